@@ -12,6 +12,55 @@ import (
 
 // Generated type declarations
 
+// HotspotClient is a device connected to a Wi-Fi hotspot or to a shared Ethernet connection.
+type HotspotClient struct {
+	MacAddress   string `json:"macAddress"`
+	IpAddress    string `json:"ipAddress"`
+	Hostname     string `json:"hostname"`
+	LeaseExpiry  int64  `json:"leaseExpiry"`
+	SignalDBm    int64  `json:"signalDBm"`
+	ConnectedSec int64  `json:"connectedSec"`
+	InactiveMSec int64  `json:"inactiveMSec"`
+}
+
+// WifiRegRule is a frequency range in which Wi-Fi may be used, subject to the rule's flags (as
+// defined for NL80211_RRF_* in linux/nl80211.h).
+type WifiRegRule struct {
+	StartFreqMHz    int64 `json:"startFreqMHz"`
+	EndFreqMHz      int64 `json:"endFreqMHz"`
+	MaxBandwidthMHz int64 `json:"maxBandwidthMHz"`
+	MaxEIRPmBm      int64 `json:"maxEIRPmBm"`
+	Flags           int64 `json:"flags"`
+}
+
+// WireGuardPeer is a peer of a WireGuard network interface, with statistics about the tunnel to it.
+type WireGuardPeer struct {
+	PublicKey     string   `json:"publicKey"`
+	Endpoint      string   `json:"endpoint"`
+	AllowedIPs    []string `json:"allowedIPs"`
+	LastHandshake int64    `json:"lastHandshake"`
+	RxBytes       int64    `json:"rxBytes"`
+	TxBytes       int64    `json:"txBytes"`
+}
+
+// HostEntry is a static entry of the machine's hosts file, which resolves each of the hostnames to
+// the address.
+type HostEntry struct {
+	Address   string   `json:"address"`
+	Hostnames []string `json:"hostnames"`
+}
+
+// Another checkpoint exists, whose changes are still waiting to be kept or rolled back.
+type CheckpointExists struct {
+	Description string `json:"description"`
+}
+
+func (e CheckpointExists) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.CheckpointExists"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
 // The uuid input provided was invalid.
 type InvalidUUID struct {
 	Description string `json:"description"`
@@ -23,6 +72,128 @@ func (e InvalidUUID) Error() string {
 	return s
 }
 
+// The packet capture options provided were invalid.
+type InvalidCaptureOptions struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidCaptureOptions) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidCaptureOptions"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The certificate or private key file provided was invalid.
+type InvalidCertificate struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidCertificate) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidCertificate"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The keyfile provided was invalid.
+type InvalidKeyfile struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidKeyfile) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidKeyfile"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The connection profile specified is not stored as a keyfile.
+type NotKeyfile struct {
+	Description string `json:"description"`
+}
+
+func (e NotKeyfile) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.NotKeyfile"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The network interface specified was unknown or unsuitable for the requested operation.
+type InvalidInterface struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidInterface) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidInterface"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The country code provided was invalid.
+type InvalidCountry struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidCountry) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidCountry"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The DNS servers, search domains, or host entries provided were invalid.
+type InvalidDNSSettings struct {
+	Description string `json:"description"`
+}
+
+func (e InvalidDNSSettings) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.InvalidDNSSettings"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The network interface specified has no IPv4 default gateway.
+type NoGateway struct {
+	Description string `json:"description"`
+}
+
+func (e NoGateway) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.NoGateway"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The connection profile specified was not for a Wi-Fi hotspot.
+type NotHotspot struct {
+	Description string `json:"description"`
+}
+
+func (e NotHotspot) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.NotHotspot"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The connection profile specified has no Wi-Fi password stored by NetworkManager.
+type NoWifiPSK struct {
+	Description string `json:"description"`
+}
+
+func (e NoWifiPSK) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.NoWifiPSK"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// Too many requests were made recently; the request may be retried after retryAfterSec seconds.
+type RateLimited struct {
+	Description   string `json:"description"`
+	RetryAfterSec int64  `json:"retryAfterSec"`
+}
+
+func (e RateLimited) Error() string {
+	s := "com.openuc2.deviceadmin.networkmanager.RateLimited"
+	s += fmt.Sprintf("(Description: %v, RetryAfterSec: %v)", e.Description, e.RetryAfterSec)
+	return s
+}
+
 // The service was unable to perform the requested operation for an unspecified reason.
 type Unknown struct {
 	Description string `json:"description"`
@@ -37,6 +208,17 @@ func (e Unknown) Error() string {
 func Dispatch_Error(err error) error {
 	if e, ok := err.(*varlink.Error); ok {
 		switch e.Name {
+		case "com.openuc2.deviceadmin.networkmanager.CheckpointExists":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param CheckpointExists
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
 		case "com.openuc2.deviceadmin.networkmanager.InvalidUUID":
 			errorRawParameters := e.Parameters.(*json.RawMessage)
 			if errorRawParameters == nil {
@@ -48,6 +230,127 @@ func Dispatch_Error(err error) error {
 				return e
 			}
 			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidCaptureOptions":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidCaptureOptions
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidCertificate":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidCertificate
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidKeyfile":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidKeyfile
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.NotKeyfile":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param NotKeyfile
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidInterface":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidInterface
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidCountry":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidCountry
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.InvalidDNSSettings":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param InvalidDNSSettings
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.NoGateway":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param NoGateway
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.NotHotspot":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param NotHotspot
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.NoWifiPSK":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param NoWifiPSK
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.networkmanager.RateLimited":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param RateLimited
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
 		case "com.openuc2.deviceadmin.networkmanager.Unknown":
 			errorRawParameters := e.Parameters.(*json.RawMessage)
 			if errorRawParameters == nil {
@@ -163,46 +466,1490 @@ func (m ReloadConnProfile_methods) Upgrade(ctx context.Context, c *varlink.Conne
 	}, nil
 }
 
-// Generated service interface with all methods
-
-type comopenuc2deviceadminnetworkmanagerInterface interface {
-	ReloadConnProfiles(ctx context.Context, c VarlinkCall) error
-	ReloadConnProfile(ctx context.Context, c VarlinkCall, uuid_ string) error
-}
-
-// Generated service object with all methods
-
-type VarlinkCall struct{ varlink.Call }
+// CapturePackets captures packets on the specified network interface, until either maxDurationSec
+// seconds have elapsed or maxBytes bytes of packet data have been captured. The filter may be empty
+// (to capture all packets), "dhcp", or "dns". The capture is streamed in pcap format as a sequence
+// of base64-encoded chunks; callers must set the "more" flag to receive more than the first chunk.
+// Streamed chunks are sent at least every second, even if they're empty, and the capture stops
+// early once the caller stops receiving chunks.
+type CapturePackets_methods struct{}
 
-// Generated reply methods for all varlink errors
+func CapturePackets() CapturePackets_methods { return CapturePackets_methods{} }
 
-// The uuid input provided was invalid.
-func (c *VarlinkCall) ReplyInvalidUUID(ctx context.Context, description_ string) error {
-	var out InvalidUUID
-	out.Description = description_
-	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidUUID", &out)
+func (m CapturePackets_methods) Call(ctx context.Context, c *varlink.Connection, iface_in_ string, maxDurationSec_in_ int64, maxBytes_in_ int64, filter_in_ string) (chunk_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, iface_in_, maxDurationSec_in_, maxBytes_in_, filter_in_)
+	if err_ != nil {
+		return
+	}
+	chunk_out_, _, err_ = receive(ctx)
+	return
 }
 
-// The service was unable to perform the requested operation for an unspecified reason.
-func (c *VarlinkCall) ReplyUnknown(ctx context.Context, description_ string) error {
-	var out Unknown
-	out.Description = description_
-	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.Unknown", &out)
+func (m CapturePackets_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, iface_in_ string, maxDurationSec_in_ int64, maxBytes_in_ int64, filter_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Iface          string `json:"iface"`
+		MaxDurationSec int64  `json:"maxDurationSec"`
+		MaxBytes       int64  `json:"maxBytes"`
+		Filter         string `json:"filter"`
+	}
+	in.Iface = iface_in_
+	in.MaxDurationSec = maxDurationSec_in_
+	in.MaxBytes = maxBytes_in_
+	in.Filter = filter_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.CapturePackets", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (chunk_out_ string, flags uint64, err error) {
+		var out struct {
+			Chunk string `json:"chunk"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		chunk_out_ = out.Chunk
+		return
+	}, nil
 }
 
-// Generated reply methods for all varlink methods
-
-func (c *VarlinkCall) ReplyReloadConnProfiles(ctx context.Context) error {
-	return c.Reply(ctx, nil)
+func (m CapturePackets_methods) Upgrade(ctx context.Context, c *varlink.Connection, iface_in_ string, maxDurationSec_in_ int64, maxBytes_in_ int64, filter_in_ string) (func(ctx context.Context) (chunk_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Iface          string `json:"iface"`
+		MaxDurationSec int64  `json:"maxDurationSec"`
+		MaxBytes       int64  `json:"maxBytes"`
+		Filter         string `json:"filter"`
+	}
+	in.Iface = iface_in_
+	in.MaxDurationSec = maxDurationSec_in_
+	in.MaxBytes = maxBytes_in_
+	in.Filter = filter_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.CapturePackets", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (chunk_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Chunk string `json:"chunk"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		chunk_out_ = out.Chunk
+		return
+	}, nil
 }
 
-func (c *VarlinkCall) ReplyReloadConnProfile(ctx context.Context) error {
-	return c.Reply(ctx, nil)
-}
+// CreateCheckpoint creates a checkpoint of all network devices and connection profiles, together
+// with a snapshot of the drop-in files from which connection profiles are assembled. Unless the
+// checkpoint is destroyed within rollbackTimeoutSec seconds, NetworkManager will automatically roll
+// back to it, and the drop-in files will be restored. It fails with CheckpointExists if another
+// checkpoint hasn't been destroyed or rolled back yet.
+type CreateCheckpoint_methods struct{}
 
-// Generated dummy implementations for all varlink methods
+func CreateCheckpoint() CreateCheckpoint_methods { return CreateCheckpoint_methods{} }
 
-// ReloadConnProfiles reloads all connection profiles from disk, including noticing any added or
+func (m CreateCheckpoint_methods) Call(ctx context.Context, c *varlink.Connection, rollbackTimeoutSec_in_ int64) (id_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, rollbackTimeoutSec_in_)
+	if err_ != nil {
+		return
+	}
+	id_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m CreateCheckpoint_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, rollbackTimeoutSec_in_ int64) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		RollbackTimeoutSec int64 `json:"rollbackTimeoutSec"`
+	}
+	in.RollbackTimeoutSec = rollbackTimeoutSec_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.CreateCheckpoint", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (id_out_ string, flags uint64, err error) {
+		var out struct {
+			Id string `json:"id"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		id_out_ = out.Id
+		return
+	}, nil
+}
+
+func (m CreateCheckpoint_methods) Upgrade(ctx context.Context, c *varlink.Connection, rollbackTimeoutSec_in_ int64) (func(ctx context.Context) (id_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		RollbackTimeoutSec int64 `json:"rollbackTimeoutSec"`
+	}
+	in.RollbackTimeoutSec = rollbackTimeoutSec_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.CreateCheckpoint", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (id_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Id string `json:"id"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		id_out_ = out.Id
+		return
+	}, nil
+}
+
+// DestroyCheckpoint destroys the specified checkpoint, so that changes since then are kept.
+type DestroyCheckpoint_methods struct{}
+
+func DestroyCheckpoint() DestroyCheckpoint_methods { return DestroyCheckpoint_methods{} }
+
+func (m DestroyCheckpoint_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m DestroyCheckpoint_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.DestroyCheckpoint", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m DestroyCheckpoint_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.DestroyCheckpoint", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// RollbackCheckpoint immediately rolls back to the specified checkpoint, and it restores the drop-in
+// files from the checkpoint's snapshot.
+type RollbackCheckpoint_methods struct{}
+
+func RollbackCheckpoint() RollbackCheckpoint_methods { return RollbackCheckpoint_methods{} }
+
+func (m RollbackCheckpoint_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m RollbackCheckpoint_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.RollbackCheckpoint", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m RollbackCheckpoint_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.RollbackCheckpoint", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// StoreCertificate stores a base64-encoded certificate or private key file (in PEM or DER format)
+// for use in the UUID-specified connection profile's 802.1X settings, replacing any file
+// previously stored for it. The kind must be "ca-cert", "client-cert", or "private-key". It returns
+// the path of the stored file, which is only readable by root.
+type StoreCertificate_methods struct{}
+
+func StoreCertificate() StoreCertificate_methods { return StoreCertificate_methods{} }
+
+func (m StoreCertificate_methods) Call(ctx context.Context, c *varlink.Connection, uuid_in_ string, kind_in_ string, data_in_ string) (path_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, uuid_in_, kind_in_, data_in_)
+	if err_ != nil {
+		return
+	}
+	path_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m StoreCertificate_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, uuid_in_ string, kind_in_ string, data_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Uuid string `json:"uuid"`
+		Kind string `json:"kind"`
+		Data string `json:"data"`
+	}
+	in.Uuid = uuid_in_
+	in.Kind = kind_in_
+	in.Data = data_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.StoreCertificate", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (path_out_ string, flags uint64, err error) {
+		var out struct {
+			Path string `json:"path"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		path_out_ = out.Path
+		return
+	}, nil
+}
+
+func (m StoreCertificate_methods) Upgrade(ctx context.Context, c *varlink.Connection, uuid_in_ string, kind_in_ string, data_in_ string) (func(ctx context.Context) (path_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Uuid string `json:"uuid"`
+		Kind string `json:"kind"`
+		Data string `json:"data"`
+	}
+	in.Uuid = uuid_in_
+	in.Kind = kind_in_
+	in.Data = data_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.StoreCertificate", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (path_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Path string `json:"path"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		path_out_ = out.Path
+		return
+	}, nil
+}
+
+// DeleteCertificates deletes all certificate and private key files stored for the UUID-specified
+// connection profile.
+type DeleteCertificates_methods struct{}
+
+func DeleteCertificates() DeleteCertificates_methods { return DeleteCertificates_methods{} }
+
+func (m DeleteCertificates_methods) Call(ctx context.Context, c *varlink.Connection, uuid_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, uuid_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m DeleteCertificates_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, uuid_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Uuid string `json:"uuid"`
+	}
+	in.Uuid = uuid_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.DeleteCertificates", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m DeleteCertificates_methods) Upgrade(ctx context.Context, c *varlink.Connection, uuid_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Uuid string `json:"uuid"`
+	}
+	in.Uuid = uuid_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.DeleteCertificates", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// GetHotspotPSK returns the Wi-Fi password of the UUID-specified connection profile, so that it can
+// be shared (e.g. as a QR code) with people joining the network. The profile must be the machine's
+// configured hotspot profile, so that the passwords of external networks are never revealed. Like
+// RevealWifiPSK, calls are rate-limited and recorded in the audit log together with the requester.
+type GetHotspotPSK_methods struct{}
+
+func GetHotspotPSK() GetHotspotPSK_methods { return GetHotspotPSK_methods{} }
+
+func (m GetHotspotPSK_methods) Call(ctx context.Context, c *varlink.Connection, uuid_in_ string, requester_in_ string) (psk_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, uuid_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	psk_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m GetHotspotPSK_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, uuid_in_ string, requester_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Uuid      string `json:"uuid"`
+		Requester string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.GetHotspotPSK", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (psk_out_ string, flags uint64, err error) {
+		var out struct {
+			Psk string `json:"psk"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		psk_out_ = out.Psk
+		return
+	}, nil
+}
+
+func (m GetHotspotPSK_methods) Upgrade(ctx context.Context, c *varlink.Connection, uuid_in_ string, requester_in_ string) (func(ctx context.Context) (psk_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Uuid      string `json:"uuid"`
+		Requester string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.GetHotspotPSK", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (psk_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Psk string `json:"psk"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		psk_out_ = out.Psk
+		return
+	}, nil
+}
+
+// RevealWifiPSK returns the stored Wi-Fi password of the UUID-specified connection profile, so that
+// it can be shown to the machine's operator. Because the profile may be for an external network,
+// reveals are rate-limited and recorded in the audit log together with the requester, which should
+// describe who asked for the password (e.g. the client's IP address).
+type RevealWifiPSK_methods struct{}
+
+func RevealWifiPSK() RevealWifiPSK_methods { return RevealWifiPSK_methods{} }
+
+func (m RevealWifiPSK_methods) Call(ctx context.Context, c *varlink.Connection, uuid_in_ string, requester_in_ string) (psk_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, uuid_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	psk_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m RevealWifiPSK_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, uuid_in_ string, requester_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Uuid      string `json:"uuid"`
+		Requester string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.RevealWifiPSK", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (psk_out_ string, flags uint64, err error) {
+		var out struct {
+			Psk string `json:"psk"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		psk_out_ = out.Psk
+		return
+	}, nil
+}
+
+func (m RevealWifiPSK_methods) Upgrade(ctx context.Context, c *varlink.Connection, uuid_in_ string, requester_in_ string) (func(ctx context.Context) (psk_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Uuid      string `json:"uuid"`
+		Requester string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.RevealWifiPSK", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (psk_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Psk string `json:"psk"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		psk_out_ = out.Psk
+		return
+	}, nil
+}
+
+// ExportConnProfile returns the keyfile of the UUID-specified connection profile, which must be
+// stored as a keyfile. Unless withSecrets is true, passwords and private keys are removed from the
+// keyfile. Exports with secrets are rate-limited together with Wi-Fi password reveals, and they are
+// recorded in the audit log together with the requester, which should describe who asked for the
+// keyfile (e.g. the client's IP address).
+type ExportConnProfile_methods struct{}
+
+func ExportConnProfile() ExportConnProfile_methods { return ExportConnProfile_methods{} }
+
+func (m ExportConnProfile_methods) Call(ctx context.Context, c *varlink.Connection, uuid_in_ string, withSecrets_in_ bool, requester_in_ string) (keyfile_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, uuid_in_, withSecrets_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	keyfile_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m ExportConnProfile_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, uuid_in_ string, withSecrets_in_ bool, requester_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Uuid        string `json:"uuid"`
+		WithSecrets bool   `json:"withSecrets"`
+		Requester   string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.WithSecrets = withSecrets_in_
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.ExportConnProfile", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (keyfile_out_ string, flags uint64, err error) {
+		var out struct {
+			Keyfile string `json:"keyfile"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		keyfile_out_ = out.Keyfile
+		return
+	}, nil
+}
+
+func (m ExportConnProfile_methods) Upgrade(ctx context.Context, c *varlink.Connection, uuid_in_ string, withSecrets_in_ bool, requester_in_ string) (func(ctx context.Context) (keyfile_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Uuid        string `json:"uuid"`
+		WithSecrets bool   `json:"withSecrets"`
+		Requester   string `json:"requester"`
+	}
+	in.Uuid = uuid_in_
+	in.WithSecrets = withSecrets_in_
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.ExportConnProfile", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (keyfile_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Keyfile string `json:"keyfile"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		keyfile_out_ = out.Keyfile
+		return
+	}, nil
+}
+
+// ImportConnProfile stores the keyfile of a connection profile (which must have a UUID), replacing
+// the keyfile of any existing connection profile with the same UUID. NetworkManager only notices the
+// keyfile once connection profiles are reloaded. The import is recorded in the audit log together
+// with the requester.
+type ImportConnProfile_methods struct{}
+
+func ImportConnProfile() ImportConnProfile_methods { return ImportConnProfile_methods{} }
+
+func (m ImportConnProfile_methods) Call(ctx context.Context, c *varlink.Connection, keyfile_in_ string, requester_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, keyfile_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m ImportConnProfile_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, keyfile_in_ string, requester_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Keyfile   string `json:"keyfile"`
+		Requester string `json:"requester"`
+	}
+	in.Keyfile = keyfile_in_
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.ImportConnProfile", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m ImportConnProfile_methods) Upgrade(ctx context.Context, c *varlink.Connection, keyfile_in_ string, requester_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Keyfile   string `json:"keyfile"`
+		Requester string `json:"requester"`
+	}
+	in.Keyfile = keyfile_in_
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.ImportConnProfile", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// ListHotspotClients lists the devices connected to the Wi-Fi hotspot or to the shared Ethernet
+// connection on the specified network interface, with information from the kernel and from
+// NetworkManager's DHCP server. For Ethernet, only devices with unexpired DHCP leases are listed.
+type ListHotspotClients_methods struct{}
+
+func ListHotspotClients() ListHotspotClients_methods { return ListHotspotClients_methods{} }
+
+func (m ListHotspotClients_methods) Call(ctx context.Context, c *varlink.Connection, iface_in_ string) (clients_out_ []HotspotClient, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, iface_in_)
+	if err_ != nil {
+		return
+	}
+	clients_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m ListHotspotClients_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, iface_in_ string) (func(ctx context.Context) ([]HotspotClient, uint64, error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+	}
+	in.Iface = iface_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.ListHotspotClients", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (clients_out_ []HotspotClient, flags uint64, err error) {
+		var out struct {
+			Clients []HotspotClient `json:"clients"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		clients_out_ = []HotspotClient(out.Clients)
+		return
+	}, nil
+}
+
+func (m ListHotspotClients_methods) Upgrade(ctx context.Context, c *varlink.Connection, iface_in_ string) (func(ctx context.Context) (clients_out_ []HotspotClient, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+	}
+	in.Iface = iface_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.ListHotspotClients", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (clients_out_ []HotspotClient, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Clients []HotspotClient `json:"clients"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		clients_out_ = []HotspotClient(out.Clients)
+		return
+	}, nil
+}
+
+// PingGateway sends count ICMP echo requests (at most 10) to the IPv4 default gateway of the
+// specified network interface, one per second, to check whether the gateway is reachable. It
+// returns the gateway's address, the numbers of requests sent and of replies received, and the
+// average round-trip time of the replies in microseconds.
+type PingGateway_methods struct{}
+
+func PingGateway() PingGateway_methods { return PingGateway_methods{} }
+
+func (m PingGateway_methods) Call(ctx context.Context, c *varlink.Connection, iface_in_ string, count_in_ int64) (gateway_out_ string, sent_out_ int64, received_out_ int64, rttUSec_out_ int64, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, iface_in_, count_in_)
+	if err_ != nil {
+		return
+	}
+	gateway_out_, sent_out_, received_out_, rttUSec_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m PingGateway_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, iface_in_ string, count_in_ int64) (func(ctx context.Context) (string, int64, int64, int64, uint64, error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+		Count int64  `json:"count"`
+	}
+	in.Iface = iface_in_
+	in.Count = count_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.PingGateway", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (gateway_out_ string, sent_out_ int64, received_out_ int64, rttUSec_out_ int64, flags uint64, err error) {
+		var out struct {
+			Gateway  string `json:"gateway"`
+			Sent     int64  `json:"sent"`
+			Received int64  `json:"received"`
+			RttUSec  int64  `json:"rttUSec"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		gateway_out_ = out.Gateway
+		sent_out_ = out.Sent
+		received_out_ = out.Received
+		rttUSec_out_ = out.RttUSec
+		return
+	}, nil
+}
+
+func (m PingGateway_methods) Upgrade(ctx context.Context, c *varlink.Connection, iface_in_ string, count_in_ int64) (func(ctx context.Context) (gateway_out_ string, sent_out_ int64, received_out_ int64, rttUSec_out_ int64, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+		Count int64  `json:"count"`
+	}
+	in.Iface = iface_in_
+	in.Count = count_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.PingGateway", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (gateway_out_ string, sent_out_ int64, received_out_ int64, rttUSec_out_ int64, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Gateway  string `json:"gateway"`
+			Sent     int64  `json:"sent"`
+			Received int64  `json:"received"`
+			RttUSec  int64  `json:"rttUSec"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		gateway_out_ = out.Gateway
+		sent_out_ = out.Sent
+		received_out_ = out.Received
+		rttUSec_out_ = out.RttUSec
+		return
+	}, nil
+}
+
+// GetWifiRegDomain returns the country code of the current Wi-Fi regulatory domain ("00" if no
+// country has been set) with its rules, and the country code which will be set on boot (empty if no
+// country will be set).
+type GetWifiRegDomain_methods struct{}
+
+func GetWifiRegDomain() GetWifiRegDomain_methods { return GetWifiRegDomain_methods{} }
+
+func (m GetWifiRegDomain_methods) Call(ctx context.Context, c *varlink.Connection) (country_out_ string, rules_out_ []WifiRegRule, persistedCountry_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0)
+	if err_ != nil {
+		return
+	}
+	country_out_, rules_out_, persistedCountry_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m GetWifiRegDomain_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64) (func(ctx context.Context) (string, []WifiRegRule, string, uint64, error), error) {
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.GetWifiRegDomain", nil, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (country_out_ string, rules_out_ []WifiRegRule, persistedCountry_out_ string, flags uint64, err error) {
+		var out struct {
+			Country          string        `json:"country"`
+			Rules            []WifiRegRule `json:"rules"`
+			PersistedCountry string        `json:"persistedCountry"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		country_out_ = out.Country
+		rules_out_ = []WifiRegRule(out.Rules)
+		persistedCountry_out_ = out.PersistedCountry
+		return
+	}, nil
+}
+
+func (m GetWifiRegDomain_methods) Upgrade(ctx context.Context, c *varlink.Connection) (func(ctx context.Context) (country_out_ string, rules_out_ []WifiRegRule, persistedCountry_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.GetWifiRegDomain", nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (country_out_ string, rules_out_ []WifiRegRule, persistedCountry_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Country          string        `json:"country"`
+			Rules            []WifiRegRule `json:"rules"`
+			PersistedCountry string        `json:"persistedCountry"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		country_out_ = out.Country
+		rules_out_ = []WifiRegRule(out.Rules)
+		persistedCountry_out_ = out.PersistedCountry
+		return
+	}, nil
+}
+
+// SetWifiCountry switches to the Wi-Fi regulatory domain of the country (specified by its ISO
+// 3166-1 alpha-2 code) and persists the country so that it's set again on every boot. If the country
+// was set but couldn't be persisted (e.g. on a machine which isn't a Raspberry Pi), persistWarning
+// explains why; otherwise it's empty. Changes are recorded in the audit log together with the
+// requester, which should describe who made the change (e.g. the client's IP address).
+type SetWifiCountry_methods struct{}
+
+func SetWifiCountry() SetWifiCountry_methods { return SetWifiCountry_methods{} }
+
+func (m SetWifiCountry_methods) Call(ctx context.Context, c *varlink.Connection, country_in_ string, requester_in_ string) (persistWarning_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, country_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	persistWarning_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m SetWifiCountry_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, country_in_ string, requester_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Country   string `json:"country"`
+		Requester string `json:"requester"`
+	}
+	in.Country = country_in_
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.SetWifiCountry", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (persistWarning_out_ string, flags uint64, err error) {
+		var out struct {
+			PersistWarning string `json:"persistWarning"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		persistWarning_out_ = out.PersistWarning
+		return
+	}, nil
+}
+
+func (m SetWifiCountry_methods) Upgrade(ctx context.Context, c *varlink.Connection, country_in_ string, requester_in_ string) (func(ctx context.Context) (persistWarning_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Country   string `json:"country"`
+		Requester string `json:"requester"`
+	}
+	in.Country = country_in_
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.SetWifiCountry", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (persistWarning_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			PersistWarning string `json:"persistWarning"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		persistWarning_out_ = out.PersistWarning
+		return
+	}, nil
+}
+
+// ListWireGuardPeers returns the peers of the WireGuard network interface, including statistics
+// which only root can read, such as the time of the latest handshake and the bytes transferred.
+type ListWireGuardPeers_methods struct{}
+
+func ListWireGuardPeers() ListWireGuardPeers_methods { return ListWireGuardPeers_methods{} }
+
+func (m ListWireGuardPeers_methods) Call(ctx context.Context, c *varlink.Connection, iface_in_ string) (peers_out_ []WireGuardPeer, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, iface_in_)
+	if err_ != nil {
+		return
+	}
+	peers_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m ListWireGuardPeers_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, iface_in_ string) (func(ctx context.Context) ([]WireGuardPeer, uint64, error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+	}
+	in.Iface = iface_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.ListWireGuardPeers", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (peers_out_ []WireGuardPeer, flags uint64, err error) {
+		var out struct {
+			Peers []WireGuardPeer `json:"peers"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		peers_out_ = []WireGuardPeer(out.Peers)
+		return
+	}, nil
+}
+
+func (m ListWireGuardPeers_methods) Upgrade(ctx context.Context, c *varlink.Connection, iface_in_ string) (func(ctx context.Context) (peers_out_ []WireGuardPeer, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Iface string `json:"iface"`
+	}
+	in.Iface = iface_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.ListWireGuardPeers", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (peers_out_ []WireGuardPeer, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Peers []WireGuardPeer `json:"peers"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		peers_out_ = []WireGuardPeer(out.Peers)
+		return
+	}, nil
+}
+
+// GetGlobalDNS returns the DNS servers and search domains which NetworkManager uses instead of those
+// of every connection profile, as set by SetGlobalDNS. Both are empty if no global DNS servers are
+// set.
+type GetGlobalDNS_methods struct{}
+
+func GetGlobalDNS() GetGlobalDNS_methods { return GetGlobalDNS_methods{} }
+
+func (m GetGlobalDNS_methods) Call(ctx context.Context, c *varlink.Connection) (servers_out_ []string, searches_out_ []string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0)
+	if err_ != nil {
+		return
+	}
+	servers_out_, searches_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m GetGlobalDNS_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64) (func(ctx context.Context) ([]string, []string, uint64, error), error) {
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.GetGlobalDNS", nil, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (servers_out_ []string, searches_out_ []string, flags uint64, err error) {
+		var out struct {
+			Servers  []string `json:"servers"`
+			Searches []string `json:"searches"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		servers_out_ = []string(out.Servers)
+		searches_out_ = []string(out.Searches)
+		return
+	}, nil
+}
+
+func (m GetGlobalDNS_methods) Upgrade(ctx context.Context, c *varlink.Connection) (func(ctx context.Context) (servers_out_ []string, searches_out_ []string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.GetGlobalDNS", nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (servers_out_ []string, searches_out_ []string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Servers  []string `json:"servers"`
+			Searches []string `json:"searches"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		servers_out_ = []string(out.Servers)
+		searches_out_ = []string(out.Searches)
+		return
+	}, nil
+}
+
+// SetGlobalDNS writes the DNS servers and search domains into a NetworkManager configuration drop-in
+// file and makes NetworkManager reload its configuration. If servers is empty, the drop-in file is
+// removed, so that the DNS servers of each connection profile are used again; search domains can
+// only be set together with servers. The change is recorded in the audit log together with the
+// requester, which should describe who made the change (e.g. the client's IP address).
+type SetGlobalDNS_methods struct{}
+
+func SetGlobalDNS() SetGlobalDNS_methods { return SetGlobalDNS_methods{} }
+
+func (m SetGlobalDNS_methods) Call(ctx context.Context, c *varlink.Connection, servers_in_ []string, searches_in_ []string, requester_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, servers_in_, searches_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m SetGlobalDNS_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, servers_in_ []string, searches_in_ []string, requester_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Servers   []string `json:"servers"`
+		Searches  []string `json:"searches"`
+		Requester string   `json:"requester"`
+	}
+	in.Servers = []string(servers_in_)
+	in.Searches = []string(searches_in_)
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.SetGlobalDNS", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m SetGlobalDNS_methods) Upgrade(ctx context.Context, c *varlink.Connection, servers_in_ []string, searches_in_ []string, requester_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Servers   []string `json:"servers"`
+		Searches  []string `json:"searches"`
+		Requester string   `json:"requester"`
+	}
+	in.Servers = []string(servers_in_)
+	in.Searches = []string(searches_in_)
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.SetGlobalDNS", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// ListHostEntries returns the static host entries which are managed by SetHostEntries; other entries
+// of the machine's hosts file are not listed.
+type ListHostEntries_methods struct{}
+
+func ListHostEntries() ListHostEntries_methods { return ListHostEntries_methods{} }
+
+func (m ListHostEntries_methods) Call(ctx context.Context, c *varlink.Connection) (entries_out_ []HostEntry, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0)
+	if err_ != nil {
+		return
+	}
+	entries_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m ListHostEntries_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64) (func(ctx context.Context) ([]HostEntry, uint64, error), error) {
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.ListHostEntries", nil, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (entries_out_ []HostEntry, flags uint64, err error) {
+		var out struct {
+			Entries []HostEntry `json:"entries"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		entries_out_ = []HostEntry(out.Entries)
+		return
+	}, nil
+}
+
+func (m ListHostEntries_methods) Upgrade(ctx context.Context, c *varlink.Connection) (func(ctx context.Context) (entries_out_ []HostEntry, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.ListHostEntries", nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (entries_out_ []HostEntry, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Entries []HostEntry `json:"entries"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		entries_out_ = []HostEntry(out.Entries)
+		return
+	}, nil
+}
+
+// SetHostEntries replaces the managed static host entries in the machine's hosts file, leaving other
+// entries of the file unchanged. The change is recorded in the audit log together with the
+// requester.
+type SetHostEntries_methods struct{}
+
+func SetHostEntries() SetHostEntries_methods { return SetHostEntries_methods{} }
+
+func (m SetHostEntries_methods) Call(ctx context.Context, c *varlink.Connection, entries_in_ []HostEntry, requester_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, entries_in_, requester_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m SetHostEntries_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, entries_in_ []HostEntry, requester_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Entries   []HostEntry `json:"entries"`
+		Requester string      `json:"requester"`
+	}
+	in.Entries = []HostEntry(entries_in_)
+	in.Requester = requester_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.networkmanager.SetHostEntries", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m SetHostEntries_methods) Upgrade(ctx context.Context, c *varlink.Connection, entries_in_ []HostEntry, requester_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Entries   []HostEntry `json:"entries"`
+		Requester string      `json:"requester"`
+	}
+	in.Entries = []HostEntry(entries_in_)
+	in.Requester = requester_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.networkmanager.SetHostEntries", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// Generated service interface with all methods
+
+type comopenuc2deviceadminnetworkmanagerInterface interface {
+	ReloadConnProfiles(ctx context.Context, c VarlinkCall) error
+	ReloadConnProfile(ctx context.Context, c VarlinkCall, uuid_ string) error
+	CapturePackets(ctx context.Context, c VarlinkCall, iface_ string, maxDurationSec_ int64, maxBytes_ int64, filter_ string) error
+	CreateCheckpoint(ctx context.Context, c VarlinkCall, rollbackTimeoutSec_ int64) error
+	DestroyCheckpoint(ctx context.Context, c VarlinkCall, id_ string) error
+	RollbackCheckpoint(ctx context.Context, c VarlinkCall, id_ string) error
+	StoreCertificate(ctx context.Context, c VarlinkCall, uuid_ string, kind_ string, data_ string) error
+	DeleteCertificates(ctx context.Context, c VarlinkCall, uuid_ string) error
+	GetHotspotPSK(ctx context.Context, c VarlinkCall, uuid_ string, requester_ string) error
+	RevealWifiPSK(ctx context.Context, c VarlinkCall, uuid_ string, requester_ string) error
+	ExportConnProfile(ctx context.Context, c VarlinkCall, uuid_ string, withSecrets_ bool, requester_ string) error
+	ImportConnProfile(ctx context.Context, c VarlinkCall, keyfile_ string, requester_ string) error
+	ListHotspotClients(ctx context.Context, c VarlinkCall, iface_ string) error
+	PingGateway(ctx context.Context, c VarlinkCall, iface_ string, count_ int64) error
+	GetWifiRegDomain(ctx context.Context, c VarlinkCall) error
+	SetWifiCountry(ctx context.Context, c VarlinkCall, country_ string, requester_ string) error
+	ListWireGuardPeers(ctx context.Context, c VarlinkCall, iface_ string) error
+	GetGlobalDNS(ctx context.Context, c VarlinkCall) error
+	SetGlobalDNS(ctx context.Context, c VarlinkCall, servers_ []string, searches_ []string, requester_ string) error
+	ListHostEntries(ctx context.Context, c VarlinkCall) error
+	SetHostEntries(ctx context.Context, c VarlinkCall, entries_ []HostEntry, requester_ string) error
+}
+
+// Generated service object with all methods
+
+type VarlinkCall struct{ varlink.Call }
+
+// Generated reply methods for all varlink errors
+
+// Another checkpoint exists, whose changes are still waiting to be kept or rolled back.
+func (c *VarlinkCall) ReplyCheckpointExists(ctx context.Context, description_ string) error {
+	var out CheckpointExists
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.CheckpointExists", &out)
+}
+
+// The uuid input provided was invalid.
+func (c *VarlinkCall) ReplyInvalidUUID(ctx context.Context, description_ string) error {
+	var out InvalidUUID
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidUUID", &out)
+}
+
+// The packet capture options provided were invalid.
+func (c *VarlinkCall) ReplyInvalidCaptureOptions(ctx context.Context, description_ string) error {
+	var out InvalidCaptureOptions
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidCaptureOptions", &out)
+}
+
+// The certificate or private key file provided was invalid.
+func (c *VarlinkCall) ReplyInvalidCertificate(ctx context.Context, description_ string) error {
+	var out InvalidCertificate
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidCertificate", &out)
+}
+
+// The keyfile provided was invalid.
+func (c *VarlinkCall) ReplyInvalidKeyfile(ctx context.Context, description_ string) error {
+	var out InvalidKeyfile
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidKeyfile", &out)
+}
+
+// The connection profile specified is not stored as a keyfile.
+func (c *VarlinkCall) ReplyNotKeyfile(ctx context.Context, description_ string) error {
+	var out NotKeyfile
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.NotKeyfile", &out)
+}
+
+// The network interface specified was unknown or unsuitable for the requested operation.
+func (c *VarlinkCall) ReplyInvalidInterface(ctx context.Context, description_ string) error {
+	var out InvalidInterface
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidInterface", &out)
+}
+
+// The country code provided was invalid.
+func (c *VarlinkCall) ReplyInvalidCountry(ctx context.Context, description_ string) error {
+	var out InvalidCountry
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidCountry", &out)
+}
+
+// The DNS servers, search domains, or host entries provided were invalid.
+func (c *VarlinkCall) ReplyInvalidDNSSettings(ctx context.Context, description_ string) error {
+	var out InvalidDNSSettings
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.InvalidDNSSettings", &out)
+}
+
+// The network interface specified has no IPv4 default gateway.
+func (c *VarlinkCall) ReplyNoGateway(ctx context.Context, description_ string) error {
+	var out NoGateway
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.NoGateway", &out)
+}
+
+// The connection profile specified was not for a Wi-Fi hotspot.
+func (c *VarlinkCall) ReplyNotHotspot(ctx context.Context, description_ string) error {
+	var out NotHotspot
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.NotHotspot", &out)
+}
+
+// The connection profile specified has no Wi-Fi password stored by NetworkManager.
+func (c *VarlinkCall) ReplyNoWifiPSK(ctx context.Context, description_ string) error {
+	var out NoWifiPSK
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.NoWifiPSK", &out)
+}
+
+// Too many requests were made recently; the request may be retried after retryAfterSec seconds.
+func (c *VarlinkCall) ReplyRateLimited(ctx context.Context, description_ string, retryAfterSec_ int64) error {
+	var out RateLimited
+	out.Description = description_
+	out.RetryAfterSec = retryAfterSec_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.RateLimited", &out)
+}
+
+// The service was unable to perform the requested operation for an unspecified reason.
+func (c *VarlinkCall) ReplyUnknown(ctx context.Context, description_ string) error {
+	var out Unknown
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.networkmanager.Unknown", &out)
+}
+
+// Generated reply methods for all varlink methods
+
+func (c *VarlinkCall) ReplyReloadConnProfiles(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyReloadConnProfile(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyCapturePackets(ctx context.Context, chunk_ string) error {
+	var out struct {
+		Chunk string `json:"chunk"`
+	}
+	out.Chunk = chunk_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyCreateCheckpoint(ctx context.Context, id_ string) error {
+	var out struct {
+		Id string `json:"id"`
+	}
+	out.Id = id_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyDestroyCheckpoint(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyRollbackCheckpoint(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyStoreCertificate(ctx context.Context, path_ string) error {
+	var out struct {
+		Path string `json:"path"`
+	}
+	out.Path = path_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyDeleteCertificates(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyGetHotspotPSK(ctx context.Context, psk_ string) error {
+	var out struct {
+		Psk string `json:"psk"`
+	}
+	out.Psk = psk_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyRevealWifiPSK(ctx context.Context, psk_ string) error {
+	var out struct {
+		Psk string `json:"psk"`
+	}
+	out.Psk = psk_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyExportConnProfile(ctx context.Context, keyfile_ string) error {
+	var out struct {
+		Keyfile string `json:"keyfile"`
+	}
+	out.Keyfile = keyfile_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyImportConnProfile(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyListHotspotClients(ctx context.Context, clients_ []HotspotClient) error {
+	var out struct {
+		Clients []HotspotClient `json:"clients"`
+	}
+	out.Clients = []HotspotClient(clients_)
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyPingGateway(ctx context.Context, gateway_ string, sent_ int64, received_ int64, rttUSec_ int64) error {
+	var out struct {
+		Gateway  string `json:"gateway"`
+		Sent     int64  `json:"sent"`
+		Received int64  `json:"received"`
+		RttUSec  int64  `json:"rttUSec"`
+	}
+	out.Gateway = gateway_
+	out.Sent = sent_
+	out.Received = received_
+	out.RttUSec = rttUSec_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyGetWifiRegDomain(ctx context.Context, country_ string, rules_ []WifiRegRule, persistedCountry_ string) error {
+	var out struct {
+		Country          string        `json:"country"`
+		Rules            []WifiRegRule `json:"rules"`
+		PersistedCountry string        `json:"persistedCountry"`
+	}
+	out.Country = country_
+	out.Rules = []WifiRegRule(rules_)
+	out.PersistedCountry = persistedCountry_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplySetWifiCountry(ctx context.Context, persistWarning_ string) error {
+	var out struct {
+		PersistWarning string `json:"persistWarning"`
+	}
+	out.PersistWarning = persistWarning_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyListWireGuardPeers(ctx context.Context, peers_ []WireGuardPeer) error {
+	var out struct {
+		Peers []WireGuardPeer `json:"peers"`
+	}
+	out.Peers = []WireGuardPeer(peers_)
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyGetGlobalDNS(ctx context.Context, servers_ []string, searches_ []string) error {
+	var out struct {
+		Servers  []string `json:"servers"`
+		Searches []string `json:"searches"`
+	}
+	out.Servers = []string(servers_)
+	out.Searches = []string(searches_)
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplySetGlobalDNS(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyListHostEntries(ctx context.Context, entries_ []HostEntry) error {
+	var out struct {
+		Entries []HostEntry `json:"entries"`
+	}
+	out.Entries = []HostEntry(entries_)
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplySetHostEntries(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+// Generated dummy implementations for all varlink methods
+
+// ReloadConnProfiles reloads all connection profiles from disk, including noticing any added or
 // deleted connection files.
 func (s *VarlinkInterface) ReloadConnProfiles(ctx context.Context, c VarlinkCall) error {
 	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ReloadConnProfiles")
@@ -213,6 +1960,149 @@ func (s *VarlinkInterface) ReloadConnProfile(ctx context.Context, c VarlinkCall,
 	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ReloadConnProfile")
 }
 
+// CapturePackets captures packets on the specified network interface, until either maxDurationSec
+// seconds have elapsed or maxBytes bytes of packet data have been captured. The filter may be empty
+// (to capture all packets), "dhcp", or "dns". The capture is streamed in pcap format as a sequence
+// of base64-encoded chunks; callers must set the "more" flag to receive more than the first chunk.
+// Streamed chunks are sent at least every second, even if they're empty, and the capture stops
+// early once the caller stops receiving chunks.
+func (s *VarlinkInterface) CapturePackets(ctx context.Context, c VarlinkCall, iface_ string, maxDurationSec_ int64, maxBytes_ int64, filter_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.CapturePackets")
+}
+
+// CreateCheckpoint creates a checkpoint of all network devices and connection profiles, together
+// with a snapshot of the drop-in files from which connection profiles are assembled. Unless the
+// checkpoint is destroyed within rollbackTimeoutSec seconds, NetworkManager will automatically roll
+// back to it, and the drop-in files will be restored. It fails with CheckpointExists if another
+// checkpoint hasn't been destroyed or rolled back yet.
+func (s *VarlinkInterface) CreateCheckpoint(ctx context.Context, c VarlinkCall, rollbackTimeoutSec_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.CreateCheckpoint")
+}
+
+// DestroyCheckpoint destroys the specified checkpoint, so that changes since then are kept.
+func (s *VarlinkInterface) DestroyCheckpoint(ctx context.Context, c VarlinkCall, id_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.DestroyCheckpoint")
+}
+
+// RollbackCheckpoint immediately rolls back to the specified checkpoint, and it restores the drop-in
+// files from the checkpoint's snapshot.
+func (s *VarlinkInterface) RollbackCheckpoint(ctx context.Context, c VarlinkCall, id_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.RollbackCheckpoint")
+}
+
+// StoreCertificate stores a base64-encoded certificate or private key file (in PEM or DER format)
+// for use in the UUID-specified connection profile's 802.1X settings, replacing any file
+// previously stored for it. The kind must be "ca-cert", "client-cert", or "private-key". It returns
+// the path of the stored file, which is only readable by root.
+func (s *VarlinkInterface) StoreCertificate(ctx context.Context, c VarlinkCall, uuid_ string, kind_ string, data_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.StoreCertificate")
+}
+
+// DeleteCertificates deletes all certificate and private key files stored for the UUID-specified
+// connection profile.
+func (s *VarlinkInterface) DeleteCertificates(ctx context.Context, c VarlinkCall, uuid_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.DeleteCertificates")
+}
+
+// GetHotspotPSK returns the Wi-Fi password of the UUID-specified connection profile, so that it can
+// be shared (e.g. as a QR code) with people joining the network. The profile must be the machine's
+// configured hotspot profile, so that the passwords of external networks are never revealed. Like
+// RevealWifiPSK, calls are rate-limited and recorded in the audit log together with the requester.
+func (s *VarlinkInterface) GetHotspotPSK(ctx context.Context, c VarlinkCall, uuid_ string, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.GetHotspotPSK")
+}
+
+// RevealWifiPSK returns the stored Wi-Fi password of the UUID-specified connection profile, so that
+// it can be shown to the machine's operator. Because the profile may be for an external network,
+// reveals are rate-limited and recorded in the audit log together with the requester, which should
+// describe who asked for the password (e.g. the client's IP address).
+func (s *VarlinkInterface) RevealWifiPSK(ctx context.Context, c VarlinkCall, uuid_ string, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.RevealWifiPSK")
+}
+
+// ExportConnProfile returns the keyfile of the UUID-specified connection profile, which must be
+// stored as a keyfile. Unless withSecrets is true, passwords and private keys are removed from the
+// keyfile. Exports with secrets are rate-limited together with Wi-Fi password reveals, and they are
+// recorded in the audit log together with the requester, which should describe who asked for the
+// keyfile (e.g. the client's IP address).
+func (s *VarlinkInterface) ExportConnProfile(ctx context.Context, c VarlinkCall, uuid_ string, withSecrets_ bool, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ExportConnProfile")
+}
+
+// ImportConnProfile stores the keyfile of a connection profile (which must have a UUID), replacing
+// the keyfile of any existing connection profile with the same UUID. NetworkManager only notices the
+// keyfile once connection profiles are reloaded. The import is recorded in the audit log together
+// with the requester.
+func (s *VarlinkInterface) ImportConnProfile(ctx context.Context, c VarlinkCall, keyfile_ string, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ImportConnProfile")
+}
+
+// ListHotspotClients lists the devices connected to the Wi-Fi hotspot or to the shared Ethernet
+// connection on the specified network interface, with information from the kernel and from
+// NetworkManager's DHCP server. For Ethernet, only devices with unexpired DHCP leases are listed.
+func (s *VarlinkInterface) ListHotspotClients(ctx context.Context, c VarlinkCall, iface_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ListHotspotClients")
+}
+
+// PingGateway sends count ICMP echo requests (at most 10) to the IPv4 default gateway of the
+// specified network interface, one per second, to check whether the gateway is reachable. It
+// returns the gateway's address, the numbers of requests sent and of replies received, and the
+// average round-trip time of the replies in microseconds.
+func (s *VarlinkInterface) PingGateway(ctx context.Context, c VarlinkCall, iface_ string, count_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.PingGateway")
+}
+
+// GetWifiRegDomain returns the country code of the current Wi-Fi regulatory domain ("00" if no
+// country has been set) with its rules, and the country code which will be set on boot (empty if no
+// country will be set).
+func (s *VarlinkInterface) GetWifiRegDomain(ctx context.Context, c VarlinkCall) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.GetWifiRegDomain")
+}
+
+// SetWifiCountry switches to the Wi-Fi regulatory domain of the country (specified by its ISO
+// 3166-1 alpha-2 code) and persists the country so that it's set again on every boot. If the country
+// was set but couldn't be persisted (e.g. on a machine which isn't a Raspberry Pi), persistWarning
+// explains why; otherwise it's empty. Changes are recorded in the audit log together with the
+// requester, which should describe who made the change (e.g. the client's IP address).
+func (s *VarlinkInterface) SetWifiCountry(ctx context.Context, c VarlinkCall, country_ string, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.SetWifiCountry")
+}
+
+// ListWireGuardPeers returns the peers of the WireGuard network interface, including statistics
+// which only root can read, such as the time of the latest handshake and the bytes transferred.
+func (s *VarlinkInterface) ListWireGuardPeers(ctx context.Context, c VarlinkCall, iface_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ListWireGuardPeers")
+}
+
+// GetGlobalDNS returns the DNS servers and search domains which NetworkManager uses instead of those
+// of every connection profile, as set by SetGlobalDNS. Both are empty if no global DNS servers are
+// set.
+func (s *VarlinkInterface) GetGlobalDNS(ctx context.Context, c VarlinkCall) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.GetGlobalDNS")
+}
+
+// SetGlobalDNS writes the DNS servers and search domains into a NetworkManager configuration drop-in
+// file and makes NetworkManager reload its configuration. If servers is empty, the drop-in file is
+// removed, so that the DNS servers of each connection profile are used again; search domains can
+// only be set together with servers. The change is recorded in the audit log together with the
+// requester, which should describe who made the change (e.g. the client's IP address).
+func (s *VarlinkInterface) SetGlobalDNS(ctx context.Context, c VarlinkCall, servers_ []string, searches_ []string, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.SetGlobalDNS")
+}
+
+// ListHostEntries returns the static host entries which are managed by SetHostEntries; other entries
+// of the machine's hosts file are not listed.
+func (s *VarlinkInterface) ListHostEntries(ctx context.Context, c VarlinkCall) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.ListHostEntries")
+}
+
+// SetHostEntries replaces the managed static host entries in the machine's hosts file, leaving other
+// entries of the file unchanged. The change is recorded in the audit log together with the
+// requester.
+func (s *VarlinkInterface) SetHostEntries(ctx context.Context, c VarlinkCall, entries_ []HostEntry, requester_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.networkmanager.SetHostEntries")
+}
+
 // Generated method call dispatcher
 
 func (s *VarlinkInterface) VarlinkDispatch(ctx context.Context, call varlink.Call, methodname string) error {
@@ -230,6 +2120,190 @@ func (s *VarlinkInterface) VarlinkDispatch(ctx context.Context, call varlink.Cal
 		}
 		return s.comopenuc2deviceadminnetworkmanagerInterface.ReloadConnProfile(ctx, VarlinkCall{call}, in.Uuid)
 
+	case "CapturePackets":
+		var in struct {
+			Iface          string `json:"iface"`
+			MaxDurationSec int64  `json:"maxDurationSec"`
+			MaxBytes       int64  `json:"maxBytes"`
+			Filter         string `json:"filter"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.CapturePackets(ctx, VarlinkCall{call}, in.Iface, in.MaxDurationSec, in.MaxBytes, in.Filter)
+
+	case "CreateCheckpoint":
+		var in struct {
+			RollbackTimeoutSec int64 `json:"rollbackTimeoutSec"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.CreateCheckpoint(ctx, VarlinkCall{call}, in.RollbackTimeoutSec)
+
+	case "DestroyCheckpoint":
+		var in struct {
+			Id string `json:"id"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.DestroyCheckpoint(ctx, VarlinkCall{call}, in.Id)
+
+	case "RollbackCheckpoint":
+		var in struct {
+			Id string `json:"id"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.RollbackCheckpoint(ctx, VarlinkCall{call}, in.Id)
+
+	case "StoreCertificate":
+		var in struct {
+			Uuid string `json:"uuid"`
+			Kind string `json:"kind"`
+			Data string `json:"data"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.StoreCertificate(ctx, VarlinkCall{call}, in.Uuid, in.Kind, in.Data)
+
+	case "DeleteCertificates":
+		var in struct {
+			Uuid string `json:"uuid"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.DeleteCertificates(ctx, VarlinkCall{call}, in.Uuid)
+
+	case "GetHotspotPSK":
+		var in struct {
+			Uuid      string `json:"uuid"`
+			Requester string `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.GetHotspotPSK(ctx, VarlinkCall{call}, in.Uuid, in.Requester)
+
+	case "RevealWifiPSK":
+		var in struct {
+			Uuid      string `json:"uuid"`
+			Requester string `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.RevealWifiPSK(ctx, VarlinkCall{call}, in.Uuid, in.Requester)
+
+	case "ExportConnProfile":
+		var in struct {
+			Uuid        string `json:"uuid"`
+			WithSecrets bool   `json:"withSecrets"`
+			Requester   string `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.ExportConnProfile(ctx, VarlinkCall{call}, in.Uuid, in.WithSecrets, in.Requester)
+
+	case "ImportConnProfile":
+		var in struct {
+			Keyfile   string `json:"keyfile"`
+			Requester string `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.ImportConnProfile(ctx, VarlinkCall{call}, in.Keyfile, in.Requester)
+
+	case "ListHotspotClients":
+		var in struct {
+			Iface string `json:"iface"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.ListHotspotClients(ctx, VarlinkCall{call}, in.Iface)
+
+	case "PingGateway":
+		var in struct {
+			Iface string `json:"iface"`
+			Count int64  `json:"count"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.PingGateway(ctx, VarlinkCall{call}, in.Iface, in.Count)
+
+	case "GetWifiRegDomain":
+		return s.comopenuc2deviceadminnetworkmanagerInterface.GetWifiRegDomain(ctx, VarlinkCall{call})
+
+	case "SetWifiCountry":
+		var in struct {
+			Country   string `json:"country"`
+			Requester string `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.SetWifiCountry(ctx, VarlinkCall{call}, in.Country, in.Requester)
+
+	case "ListWireGuardPeers":
+		var in struct {
+			Iface string `json:"iface"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.ListWireGuardPeers(ctx, VarlinkCall{call}, in.Iface)
+
+	case "GetGlobalDNS":
+		return s.comopenuc2deviceadminnetworkmanagerInterface.GetGlobalDNS(ctx, VarlinkCall{call})
+
+	case "SetGlobalDNS":
+		var in struct {
+			Servers   []string `json:"servers"`
+			Searches  []string `json:"searches"`
+			Requester string   `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.SetGlobalDNS(ctx, VarlinkCall{call}, []string(in.Servers), []string(in.Searches), in.Requester)
+
+	case "ListHostEntries":
+		return s.comopenuc2deviceadminnetworkmanagerInterface.ListHostEntries(ctx, VarlinkCall{call})
+
+	case "SetHostEntries":
+		var in struct {
+			Entries   []HostEntry `json:"entries"`
+			Requester string      `json:"requester"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminnetworkmanagerInterface.SetHostEntries(ctx, VarlinkCall{call}, []HostEntry(in.Entries), in.Requester)
+
 	default:
 		return call.ReplyMethodNotFound(ctx, methodname)
 	}
@@ -254,9 +2328,201 @@ method ReloadConnProfiles() -> ()
 # ReloadConnProfile reloads the UUID-specified connection profile from disk.
 method ReloadConnProfile(uuid: string) -> ()
 
+# CapturePackets captures packets on the specified network interface, until either maxDurationSec
+# seconds have elapsed or maxBytes bytes of packet data have been captured. The filter may be empty
+# (to capture all packets), "dhcp", or "dns". The capture is streamed in pcap format as a sequence
+# of base64-encoded chunks; callers must set the "more" flag to receive more than the first chunk.
+# Streamed chunks are sent at least every second, even if they're empty, and the capture stops
+# early once the caller stops receiving chunks.
+method CapturePackets(
+  iface: string, maxDurationSec: int, maxBytes: int, filter: string
+) -> (chunk: string)
+
+# CreateCheckpoint creates a checkpoint of all network devices and connection profiles, together
+# with a snapshot of the drop-in files from which connection profiles are assembled. Unless the
+# checkpoint is destroyed within rollbackTimeoutSec seconds, NetworkManager will automatically roll
+# back to it, and the drop-in files will be restored. It fails with CheckpointExists if another
+# checkpoint hasn't been destroyed or rolled back yet.
+method CreateCheckpoint(rollbackTimeoutSec: int) -> (id: string)
+
+# DestroyCheckpoint destroys the specified checkpoint, so that changes since then are kept.
+method DestroyCheckpoint(id: string) -> ()
+
+# RollbackCheckpoint immediately rolls back to the specified checkpoint, and it restores the drop-in
+# files from the checkpoint's snapshot.
+method RollbackCheckpoint(id: string) -> ()
+
+# StoreCertificate stores a base64-encoded certificate or private key file (in PEM or DER format)
+# for use in the UUID-specified connection profile's 802.1X settings, replacing any file
+# previously stored for it. The kind must be "ca-cert", "client-cert", or "private-key". It returns
+# the path of the stored file, which is only readable by root.
+method StoreCertificate(uuid: string, kind: string, data: string) -> (path: string)
+
+# DeleteCertificates deletes all certificate and private key files stored for the UUID-specified
+# connection profile.
+method DeleteCertificates(uuid: string) -> ()
+
+# GetHotspotPSK returns the Wi-Fi password of the UUID-specified connection profile, so that it can
+# be shared (e.g. as a QR code) with people joining the network. The profile must be the machine's
+# configured hotspot profile, so that the passwords of external networks are never revealed. Like
+# RevealWifiPSK, calls are rate-limited and recorded in the audit log together with the requester.
+method GetHotspotPSK(uuid: string, requester: string) -> (psk: string)
+
+# RevealWifiPSK returns the stored Wi-Fi password of the UUID-specified connection profile, so that
+# it can be shown to the machine's operator. Because the profile may be for an external network,
+# reveals are rate-limited and recorded in the audit log together with the requester, which should
+# describe who asked for the password (e.g. the client's IP address).
+method RevealWifiPSK(uuid: string, requester: string) -> (psk: string)
+
+# ExportConnProfile returns the keyfile of the UUID-specified connection profile, which must be
+# stored as a keyfile. Unless withSecrets is true, passwords and private keys are removed from the
+# keyfile. Exports with secrets are rate-limited together with Wi-Fi password reveals, and they are
+# recorded in the audit log together with the requester, which should describe who asked for the
+# keyfile (e.g. the client's IP address).
+method ExportConnProfile(uuid: string, withSecrets: bool, requester: string) -> (keyfile: string)
+
+# ImportConnProfile stores the keyfile of a connection profile (which must have a UUID), replacing
+# the keyfile of any existing connection profile with the same UUID. NetworkManager only notices the
+# keyfile once connection profiles are reloaded. The import is recorded in the audit log together
+# with the requester.
+method ImportConnProfile(keyfile: string, requester: string) -> ()
+
+# HotspotClient is a device connected to a Wi-Fi hotspot or to a shared Ethernet connection.
+type HotspotClient (
+  macAddress: string,
+  # ipAddress is empty if the device has no DHCP lease.
+  ipAddress: string,
+  # hostname is empty if the device didn't report a hostname when requesting a DHCP lease.
+  hostname: string,
+  # leaseExpiry is a Unix time in seconds, or 0 if the device has no DHCP lease or if the lease
+  # never expires.
+  leaseExpiry: int,
+  # signalDBm, connectedSec, and inactiveMSec are 0 for devices connected to a shared Ethernet
+  # connection.
+  signalDBm: int,
+  connectedSec: int,
+  inactiveMSec: int
+)
+
+# ListHotspotClients lists the devices connected to the Wi-Fi hotspot or to the shared Ethernet
+# connection on the specified network interface, with information from the kernel and from
+# NetworkManager's DHCP server. For Ethernet, only devices with unexpired DHCP leases are listed.
+method ListHotspotClients(iface: string) -> (clients: []HotspotClient)
+
+# PingGateway sends count ICMP echo requests (at most 10) to the IPv4 default gateway of the
+# specified network interface, one per second, to check whether the gateway is reachable. It
+# returns the gateway's address, the numbers of requests sent and of replies received, and the
+# average round-trip time of the replies in microseconds.
+method PingGateway(iface: string, count: int) -> (
+  gateway: string, sent: int, received: int, rttUSec: int
+)
+
+# WifiRegRule is a frequency range in which Wi-Fi may be used, subject to the rule's flags (as
+# defined for NL80211_RRF_* in linux/nl80211.h).
+type WifiRegRule (
+  startFreqMHz: int,
+  endFreqMHz: int,
+  maxBandwidthMHz: int,
+  maxEIRPmBm: int,
+  flags: int
+)
+
+# GetWifiRegDomain returns the country code of the current Wi-Fi regulatory domain ("00" if no
+# country has been set) with its rules, and the country code which will be set on boot (empty if no
+# country will be set).
+method GetWifiRegDomain() -> (country: string, rules: []WifiRegRule, persistedCountry: string)
+
+# SetWifiCountry switches to the Wi-Fi regulatory domain of the country (specified by its ISO
+# 3166-1 alpha-2 code) and persists the country so that it's set again on every boot. If the country
+# was set but couldn't be persisted (e.g. on a machine which isn't a Raspberry Pi), persistWarning
+# explains why; otherwise it's empty. Changes are recorded in the audit log together with the
+# requester, which should describe who made the change (e.g. the client's IP address).
+method SetWifiCountry(country: string, requester: string) -> (persistWarning: string)
+
+# WireGuardPeer is a peer of a WireGuard network interface, with statistics about the tunnel to it.
+type WireGuardPeer (
+  publicKey: string,
+  # endpoint is empty if the peer's address isn't known yet.
+  endpoint: string,
+  allowedIPs: []string,
+  # lastHandshake is the time (in seconds since the Unix epoch) of the latest handshake with the
+  # peer, or 0 if no handshake has happened yet.
+  lastHandshake: int,
+  rxBytes: int,
+  txBytes: int
+)
+
+# ListWireGuardPeers returns the peers of the WireGuard network interface, including statistics
+# which only root can read, such as the time of the latest handshake and the bytes transferred.
+method ListWireGuardPeers(iface: string) -> (peers: []WireGuardPeer)
+
+# GetGlobalDNS returns the DNS servers and search domains which NetworkManager uses instead of those
+# of every connection profile, as set by SetGlobalDNS. Both are empty if no global DNS servers are
+# set.
+method GetGlobalDNS() -> (servers: []string, searches: []string)
+
+# SetGlobalDNS writes the DNS servers and search domains into a NetworkManager configuration drop-in
+# file and makes NetworkManager reload its configuration. If servers is empty, the drop-in file is
+# removed, so that the DNS servers of each connection profile are used again; search domains can
+# only be set together with servers. The change is recorded in the audit log together with the
+# requester, which should describe who made the change (e.g. the client's IP address).
+method SetGlobalDNS(servers: []string, searches: []string, requester: string) -> ()
+
+# HostEntry is a static entry of the machine's hosts file, which resolves each of the hostnames to
+# the address.
+type HostEntry (
+  address: string,
+  hostnames: []string
+)
+
+# ListHostEntries returns the static host entries which are managed by SetHostEntries; other entries
+# of the machine's hosts file are not listed.
+method ListHostEntries() -> (entries: []HostEntry)
+
+# SetHostEntries replaces the managed static host entries in the machine's hosts file, leaving other
+# entries of the file unchanged. The change is recorded in the audit log together with the
+# requester.
+method SetHostEntries(entries: []HostEntry, requester: string) -> ()
+
+# Another checkpoint exists, whose changes are still waiting to be kept or rolled back.
+error CheckpointExists (description: string)
+
 # The uuid input provided was invalid.
 error InvalidUUID (description: string)
 
+# The packet capture options provided were invalid.
+error InvalidCaptureOptions (description: string)
+
+# The certificate or private key file provided was invalid.
+error InvalidCertificate (description: string)
+
+# The keyfile provided was invalid.
+error InvalidKeyfile (description: string)
+
+# The connection profile specified is not stored as a keyfile.
+error NotKeyfile (description: string)
+
+# The network interface specified was unknown or unsuitable for the requested operation.
+error InvalidInterface (description: string)
+
+# The country code provided was invalid.
+error InvalidCountry (description: string)
+
+# The DNS servers, search domains, or host entries provided were invalid.
+error InvalidDNSSettings (description: string)
+
+# The network interface specified has no IPv4 default gateway.
+error NoGateway (description: string)
+
+# The connection profile specified was not for a Wi-Fi hotspot.
+error NotHotspot (description: string)
+
+# The connection profile specified has no Wi-Fi password stored by NetworkManager.
+error NoWifiPSK (description: string)
+
+# Too many requests were made recently; the request may be retried after retryAfterSec seconds.
+error RateLimited (description: string, retryAfterSec: int)
+
 # The service was unable to perform the requested operation for an unspecified reason.
 error Unknown (description: string)
 `
@@ -159,11 +159,79 @@ func (m RegenerateDropInConnProfile_methods) Upgrade(ctx context.Context, c *var
 	}, nil
 }
 
+// UpdateWifiBandDropInFile sets the Wi-Fi band (e.g. "bg" or "a") and channel (or 0 for automatic
+// channel selection) in the specified connection profile (as specified via file-based name, e.g.
+// "wlan0-hotspot")'s Wi-Fi band drop-in snippet file (which is automatically determined). If the
+// band is empty, the drop-in snippet file is removed, so that the band and channel are determined
+// by the connection profile's other drop-in snippet files.
+// This operation does not try to regenerate the connection profile itself from the drop-in files.
+type UpdateWifiBandDropInFile_methods struct{}
+
+func UpdateWifiBandDropInFile() UpdateWifiBandDropInFile_methods {
+	return UpdateWifiBandDropInFile_methods{}
+}
+
+func (m UpdateWifiBandDropInFile_methods) Call(ctx context.Context, c *varlink.Connection, connProfile_in_ string, band_in_ string, channel_in_ int64) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, connProfile_in_, band_in_, channel_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m UpdateWifiBandDropInFile_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, connProfile_in_ string, band_in_ string, channel_in_ int64) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		ConnProfile string `json:"connProfile"`
+		Band        string `json:"band"`
+		Channel     int64  `json:"channel"`
+	}
+	in.ConnProfile = connProfile_in_
+	in.Band = band_in_
+	in.Channel = channel_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.openuc2.UpdateWifiBandDropInFile", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m UpdateWifiBandDropInFile_methods) Upgrade(ctx context.Context, c *varlink.Connection, connProfile_in_ string, band_in_ string, channel_in_ int64) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		ConnProfile string `json:"connProfile"`
+		Band        string `json:"band"`
+		Channel     int64  `json:"channel"`
+	}
+	in.ConnProfile = connProfile_in_
+	in.Band = band_in_
+	in.Channel = channel_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.openuc2.UpdateWifiBandDropInFile", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
 // Generated service interface with all methods
 
 type comopenuc2deviceadminopenuc2Interface interface {
 	UpdatePSKDropInFile(ctx context.Context, c VarlinkCall, connProfile_ string, newPw_ string) error
 	RegenerateDropInConnProfile(ctx context.Context, c VarlinkCall, connProfile_ string) error
+	UpdateWifiBandDropInFile(ctx context.Context, c VarlinkCall, connProfile_ string, band_ string, channel_ int64) error
 }
 
 // Generated service object with all methods
@@ -189,6 +257,10 @@ func (c *VarlinkCall) ReplyRegenerateDropInConnProfile(ctx context.Context) erro
 	return c.Reply(ctx, nil)
 }
 
+func (c *VarlinkCall) ReplyUpdateWifiBandDropInFile(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
 // Generated dummy implementations for all varlink methods
 
 // UpdatePSKDropInFile updates the specified connection profile (as specified via file-based name,
@@ -206,6 +278,16 @@ func (s *VarlinkInterface) RegenerateDropInConnProfile(ctx context.Context, c Va
 	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.openuc2.RegenerateDropInConnProfile")
 }
 
+// UpdateWifiBandDropInFile sets the Wi-Fi band (e.g. "bg" or "a") and channel (or 0 for automatic
+// channel selection) in the specified connection profile (as specified via file-based name, e.g.
+// "wlan0-hotspot")'s Wi-Fi band drop-in snippet file (which is automatically determined). If the
+// band is empty, the drop-in snippet file is removed, so that the band and channel are determined
+// by the connection profile's other drop-in snippet files.
+// This operation does not try to regenerate the connection profile itself from the drop-in files.
+func (s *VarlinkInterface) UpdateWifiBandDropInFile(ctx context.Context, c VarlinkCall, connProfile_ string, band_ string, channel_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.openuc2.UpdateWifiBandDropInFile")
+}
+
 // Generated method call dispatcher
 
 func (s *VarlinkInterface) VarlinkDispatch(ctx context.Context, call varlink.Call, methodname string) error {
@@ -231,6 +313,18 @@ func (s *VarlinkInterface) VarlinkDispatch(ctx context.Context, call varlink.Cal
 		}
 		return s.comopenuc2deviceadminopenuc2Interface.RegenerateDropInConnProfile(ctx, VarlinkCall{call}, in.ConnProfile)
 
+	case "UpdateWifiBandDropInFile":
+		var in struct {
+			ConnProfile string `json:"connProfile"`
+			Band        string `json:"band"`
+			Channel     int64  `json:"channel"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminopenuc2Interface.UpdateWifiBandDropInFile(ctx, VarlinkCall{call}, in.ConnProfile, in.Band, in.Channel)
+
 	default:
 		return call.ReplyMethodNotFound(ctx, methodname)
 	}
@@ -263,6 +357,14 @@ method UpdatePSKDropInFile(connProfile: string, newPw: string) -> ()
 # its file-based name, e.g. "wlan0-hotspot") from its constituent drop-in snippet files.
 # This operation does not try to make NetworkManager reload the updated connection profile.
 method RegenerateDropInConnProfile(connProfile: string) -> ()
+
+# UpdateWifiBandDropInFile sets the Wi-Fi band (e.g. "bg" or "a") and channel (or 0 for automatic
+# channel selection) in the specified connection profile (as specified via file-based name, e.g.
+# "wlan0-hotspot")'s Wi-Fi band drop-in snippet file (which is automatically determined). If the
+# band is empty, the drop-in snippet file is removed, so that the band and channel are determined
+# by the connection profile's other drop-in snippet files.
+# This operation does not try to regenerate the connection profile itself from the drop-in files.
+method UpdateWifiBandDropInFile(connProfile: string, band: string, channel: int) -> ()
 `
 }
 
@@ -0,0 +1,817 @@
+// Code generated by github.com/varlink/go/cmd/varlink-go-interface-generator, DO NOT EDIT.
+
+// com.openuc2.deviceadmin.terminal provides shell sessions for remote support.
+package comopenuc2deviceadminterminal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/varlink/go/varlink"
+)
+
+// Generated type declarations
+
+// Terminal sessions are currently disabled.
+type Disabled struct {
+	Description string `json:"description"`
+}
+
+func (e Disabled) Error() string {
+	s := "com.openuc2.deviceadmin.terminal.Disabled"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The specified session does not exist.
+type UnknownSession struct {
+	Description string `json:"description"`
+}
+
+func (e UnknownSession) Error() string {
+	s := "com.openuc2.deviceadmin.terminal.UnknownSession"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+// The service was unable to perform the requested operation for an unspecified reason.
+type Unknown struct {
+	Description string `json:"description"`
+}
+
+func (e Unknown) Error() string {
+	s := "com.openuc2.deviceadmin.terminal.Unknown"
+	s += fmt.Sprintf("(Description: %v)", e.Description)
+	return s
+}
+
+func Dispatch_Error(err error) error {
+	if e, ok := err.(*varlink.Error); ok {
+		switch e.Name {
+		case "com.openuc2.deviceadmin.terminal.Disabled":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param Disabled
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.terminal.UnknownSession":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param UnknownSession
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		case "com.openuc2.deviceadmin.terminal.Unknown":
+			errorRawParameters := e.Parameters.(*json.RawMessage)
+			if errorRawParameters == nil {
+				return e
+			}
+			var param Unknown
+			err := json.Unmarshal(*errorRawParameters, &param)
+			if err != nil {
+				return e
+			}
+			return &param
+		}
+	}
+	return err
+}
+
+// Generated client method calls
+
+// GetStatus returns the time (in seconds since the Unix epoch) when terminal sessions will be
+// automatically disabled, or 0 if terminal sessions are currently disabled.
+type GetStatus_methods struct{}
+
+func GetStatus() GetStatus_methods { return GetStatus_methods{} }
+
+func (m GetStatus_methods) Call(ctx context.Context, c *varlink.Connection) (enabledUntil_out_ int64, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0)
+	if err_ != nil {
+		return
+	}
+	enabledUntil_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m GetStatus_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64) (func(ctx context.Context) (int64, uint64, error), error) {
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.GetStatus", nil, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (enabledUntil_out_ int64, flags uint64, err error) {
+		var out struct {
+			EnabledUntil int64 `json:"enabledUntil"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		enabledUntil_out_ = out.EnabledUntil
+		return
+	}, nil
+}
+
+func (m GetStatus_methods) Upgrade(ctx context.Context, c *varlink.Connection) (func(ctx context.Context) (enabledUntil_out_ int64, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.GetStatus", nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (enabledUntil_out_ int64, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			EnabledUntil int64 `json:"enabledUntil"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		enabledUntil_out_ = out.EnabledUntil
+		return
+	}, nil
+}
+
+// Enable allows terminal sessions to be opened for the specified duration (which may be shortened
+// to a maximum duration configured in the sidecar), after which all sessions are closed.
+type Enable_methods struct{}
+
+func Enable() Enable_methods { return Enable_methods{} }
+
+func (m Enable_methods) Call(ctx context.Context, c *varlink.Connection, durationSec_in_ int64) (enabledUntil_out_ int64, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, durationSec_in_)
+	if err_ != nil {
+		return
+	}
+	enabledUntil_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m Enable_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, durationSec_in_ int64) (func(ctx context.Context) (int64, uint64, error), error) {
+	var in struct {
+		DurationSec int64 `json:"durationSec"`
+	}
+	in.DurationSec = durationSec_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.Enable", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (enabledUntil_out_ int64, flags uint64, err error) {
+		var out struct {
+			EnabledUntil int64 `json:"enabledUntil"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		enabledUntil_out_ = out.EnabledUntil
+		return
+	}, nil
+}
+
+func (m Enable_methods) Upgrade(ctx context.Context, c *varlink.Connection, durationSec_in_ int64) (func(ctx context.Context) (enabledUntil_out_ int64, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		DurationSec int64 `json:"durationSec"`
+	}
+	in.DurationSec = durationSec_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.Enable", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (enabledUntil_out_ int64, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			EnabledUntil int64 `json:"enabledUntil"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		enabledUntil_out_ = out.EnabledUntil
+		return
+	}, nil
+}
+
+// Disable prevents new terminal sessions from being opened, and closes all open sessions.
+type Disable_methods struct{}
+
+func Disable() Disable_methods { return Disable_methods{} }
+
+func (m Disable_methods) Call(ctx context.Context, c *varlink.Connection) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m Disable_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64) (func(ctx context.Context) (uint64, error), error) {
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.Disable", nil, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m Disable_methods) Upgrade(ctx context.Context, c *varlink.Connection) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.Disable", nil)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// OpenSession starts a new shell in a pseudo-terminal of the specified size, running as an
+// unprivileged user.
+type OpenSession_methods struct{}
+
+func OpenSession() OpenSession_methods { return OpenSession_methods{} }
+
+func (m OpenSession_methods) Call(ctx context.Context, c *varlink.Connection, cols_in_ int64, rows_in_ int64) (id_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, cols_in_, rows_in_)
+	if err_ != nil {
+		return
+	}
+	id_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m OpenSession_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, cols_in_ int64, rows_in_ int64) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Cols int64 `json:"cols"`
+		Rows int64 `json:"rows"`
+	}
+	in.Cols = cols_in_
+	in.Rows = rows_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.OpenSession", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (id_out_ string, flags uint64, err error) {
+		var out struct {
+			Id string `json:"id"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		id_out_ = out.Id
+		return
+	}, nil
+}
+
+func (m OpenSession_methods) Upgrade(ctx context.Context, c *varlink.Connection, cols_in_ int64, rows_in_ int64) (func(ctx context.Context) (id_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Cols int64 `json:"cols"`
+		Rows int64 `json:"rows"`
+	}
+	in.Cols = cols_in_
+	in.Rows = rows_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.OpenSession", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (id_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Id string `json:"id"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		id_out_ = out.Id
+		return
+	}, nil
+}
+
+// ReadSession streams output from the specified session as base64-encoded chunks, until the session
+// is closed; callers must set the "more" flag to receive more than the first chunk.
+type ReadSession_methods struct{}
+
+func ReadSession() ReadSession_methods { return ReadSession_methods{} }
+
+func (m ReadSession_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string) (chunk_out_ string, err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_)
+	if err_ != nil {
+		return
+	}
+	chunk_out_, _, err_ = receive(ctx)
+	return
+}
+
+func (m ReadSession_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string) (func(ctx context.Context) (string, uint64, error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.ReadSession", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (chunk_out_ string, flags uint64, err error) {
+		var out struct {
+			Chunk string `json:"chunk"`
+		}
+		flags, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		chunk_out_ = out.Chunk
+		return
+	}, nil
+}
+
+func (m ReadSession_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string) (func(ctx context.Context) (chunk_out_ string, flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.ReadSession", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (chunk_out_ string, flags uint64, conn varlink.ReadWriterContext, err error) {
+		var out struct {
+			Chunk string `json:"chunk"`
+		}
+		flags, conn, err = receive(ctx, &out)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		chunk_out_ = out.Chunk
+		return
+	}, nil
+}
+
+// WriteSession sends the base64-encoded data as input to the specified session.
+type WriteSession_methods struct{}
+
+func WriteSession() WriteSession_methods { return WriteSession_methods{} }
+
+func (m WriteSession_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string, data_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_, data_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m WriteSession_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string, data_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Id   string `json:"id"`
+		Data string `json:"data"`
+	}
+	in.Id = id_in_
+	in.Data = data_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.WriteSession", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m WriteSession_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string, data_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id   string `json:"id"`
+		Data string `json:"data"`
+	}
+	in.Id = id_in_
+	in.Data = data_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.WriteSession", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// ResizeSession changes the size of the specified session's pseudo-terminal.
+type ResizeSession_methods struct{}
+
+func ResizeSession() ResizeSession_methods { return ResizeSession_methods{} }
+
+func (m ResizeSession_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string, cols_in_ int64, rows_in_ int64) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_, cols_in_, rows_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m ResizeSession_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string, cols_in_ int64, rows_in_ int64) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Id   string `json:"id"`
+		Cols int64  `json:"cols"`
+		Rows int64  `json:"rows"`
+	}
+	in.Id = id_in_
+	in.Cols = cols_in_
+	in.Rows = rows_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.ResizeSession", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m ResizeSession_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string, cols_in_ int64, rows_in_ int64) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id   string `json:"id"`
+		Cols int64  `json:"cols"`
+		Rows int64  `json:"rows"`
+	}
+	in.Id = id_in_
+	in.Cols = cols_in_
+	in.Rows = rows_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.ResizeSession", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// CloseSession terminates the specified session.
+type CloseSession_methods struct{}
+
+func CloseSession() CloseSession_methods { return CloseSession_methods{} }
+
+func (m CloseSession_methods) Call(ctx context.Context, c *varlink.Connection, id_in_ string) (err_ error) {
+	receive, err_ := m.Send(ctx, c, 0, id_in_)
+	if err_ != nil {
+		return
+	}
+	_, err_ = receive(ctx)
+	return
+}
+
+func (m CloseSession_methods) Send(ctx context.Context, c *varlink.Connection, flags uint64, id_in_ string) (func(ctx context.Context) (uint64, error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Send(ctx, "com.openuc2.deviceadmin.terminal.CloseSession", in, flags)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, err error) {
+		flags, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+func (m CloseSession_methods) Upgrade(ctx context.Context, c *varlink.Connection, id_in_ string) (func(ctx context.Context) (flags uint64, conn varlink.ReadWriterContext, err_ error), error) {
+	var in struct {
+		Id string `json:"id"`
+	}
+	in.Id = id_in_
+	receive, err := c.Upgrade(ctx, "com.openuc2.deviceadmin.terminal.CloseSession", in)
+	if err != nil {
+		return nil, err
+	}
+	return func(context.Context) (flags uint64, conn varlink.ReadWriterContext, err error) {
+		flags, conn, err = receive(ctx, nil)
+		if err != nil {
+			err = Dispatch_Error(err)
+			return
+		}
+		return
+	}, nil
+}
+
+// Generated service interface with all methods
+
+type comopenuc2deviceadminterminalInterface interface {
+	GetStatus(ctx context.Context, c VarlinkCall) error
+	Enable(ctx context.Context, c VarlinkCall, durationSec_ int64) error
+	Disable(ctx context.Context, c VarlinkCall) error
+	OpenSession(ctx context.Context, c VarlinkCall, cols_ int64, rows_ int64) error
+	ReadSession(ctx context.Context, c VarlinkCall, id_ string) error
+	WriteSession(ctx context.Context, c VarlinkCall, id_ string, data_ string) error
+	ResizeSession(ctx context.Context, c VarlinkCall, id_ string, cols_ int64, rows_ int64) error
+	CloseSession(ctx context.Context, c VarlinkCall, id_ string) error
+}
+
+// Generated service object with all methods
+
+type VarlinkCall struct{ varlink.Call }
+
+// Generated reply methods for all varlink errors
+
+// Terminal sessions are currently disabled.
+func (c *VarlinkCall) ReplyDisabled(ctx context.Context, description_ string) error {
+	var out Disabled
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.terminal.Disabled", &out)
+}
+
+// The specified session does not exist.
+func (c *VarlinkCall) ReplyUnknownSession(ctx context.Context, description_ string) error {
+	var out UnknownSession
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.terminal.UnknownSession", &out)
+}
+
+// The service was unable to perform the requested operation for an unspecified reason.
+func (c *VarlinkCall) ReplyUnknown(ctx context.Context, description_ string) error {
+	var out Unknown
+	out.Description = description_
+	return c.ReplyError(ctx, "com.openuc2.deviceadmin.terminal.Unknown", &out)
+}
+
+// Generated reply methods for all varlink methods
+
+func (c *VarlinkCall) ReplyGetStatus(ctx context.Context, enabledUntil_ int64) error {
+	var out struct {
+		EnabledUntil int64 `json:"enabledUntil"`
+	}
+	out.EnabledUntil = enabledUntil_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyEnable(ctx context.Context, enabledUntil_ int64) error {
+	var out struct {
+		EnabledUntil int64 `json:"enabledUntil"`
+	}
+	out.EnabledUntil = enabledUntil_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyDisable(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyOpenSession(ctx context.Context, id_ string) error {
+	var out struct {
+		Id string `json:"id"`
+	}
+	out.Id = id_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyReadSession(ctx context.Context, chunk_ string) error {
+	var out struct {
+		Chunk string `json:"chunk"`
+	}
+	out.Chunk = chunk_
+	return c.Reply(ctx, &out)
+}
+
+func (c *VarlinkCall) ReplyWriteSession(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyResizeSession(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+func (c *VarlinkCall) ReplyCloseSession(ctx context.Context) error {
+	return c.Reply(ctx, nil)
+}
+
+// Generated dummy implementations for all varlink methods
+
+// GetStatus returns the time (in seconds since the Unix epoch) when terminal sessions will be
+// automatically disabled, or 0 if terminal sessions are currently disabled.
+func (s *VarlinkInterface) GetStatus(ctx context.Context, c VarlinkCall) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.GetStatus")
+}
+
+// Enable allows terminal sessions to be opened for the specified duration (which may be shortened
+// to a maximum duration configured in the sidecar), after which all sessions are closed.
+func (s *VarlinkInterface) Enable(ctx context.Context, c VarlinkCall, durationSec_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.Enable")
+}
+
+// Disable prevents new terminal sessions from being opened, and closes all open sessions.
+func (s *VarlinkInterface) Disable(ctx context.Context, c VarlinkCall) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.Disable")
+}
+
+// OpenSession starts a new shell in a pseudo-terminal of the specified size, running as an
+// unprivileged user.
+func (s *VarlinkInterface) OpenSession(ctx context.Context, c VarlinkCall, cols_ int64, rows_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.OpenSession")
+}
+
+// ReadSession streams output from the specified session as base64-encoded chunks, until the session
+// is closed; callers must set the "more" flag to receive more than the first chunk.
+func (s *VarlinkInterface) ReadSession(ctx context.Context, c VarlinkCall, id_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.ReadSession")
+}
+
+// WriteSession sends the base64-encoded data as input to the specified session.
+func (s *VarlinkInterface) WriteSession(ctx context.Context, c VarlinkCall, id_ string, data_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.WriteSession")
+}
+
+// ResizeSession changes the size of the specified session's pseudo-terminal.
+func (s *VarlinkInterface) ResizeSession(ctx context.Context, c VarlinkCall, id_ string, cols_ int64, rows_ int64) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.ResizeSession")
+}
+
+// CloseSession terminates the specified session.
+func (s *VarlinkInterface) CloseSession(ctx context.Context, c VarlinkCall, id_ string) error {
+	return c.ReplyMethodNotImplemented(ctx, "com.openuc2.deviceadmin.terminal.CloseSession")
+}
+
+// Generated method call dispatcher
+
+func (s *VarlinkInterface) VarlinkDispatch(ctx context.Context, call varlink.Call, methodname string) error {
+	switch methodname {
+	case "GetStatus":
+		return s.comopenuc2deviceadminterminalInterface.GetStatus(ctx, VarlinkCall{call})
+
+	case "Enable":
+		var in struct {
+			DurationSec int64 `json:"durationSec"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.Enable(ctx, VarlinkCall{call}, in.DurationSec)
+
+	case "Disable":
+		return s.comopenuc2deviceadminterminalInterface.Disable(ctx, VarlinkCall{call})
+
+	case "OpenSession":
+		var in struct {
+			Cols int64 `json:"cols"`
+			Rows int64 `json:"rows"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.OpenSession(ctx, VarlinkCall{call}, in.Cols, in.Rows)
+
+	case "ReadSession":
+		var in struct {
+			Id string `json:"id"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.ReadSession(ctx, VarlinkCall{call}, in.Id)
+
+	case "WriteSession":
+		var in struct {
+			Id   string `json:"id"`
+			Data string `json:"data"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.WriteSession(ctx, VarlinkCall{call}, in.Id, in.Data)
+
+	case "ResizeSession":
+		var in struct {
+			Id   string `json:"id"`
+			Cols int64  `json:"cols"`
+			Rows int64  `json:"rows"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.ResizeSession(ctx, VarlinkCall{call}, in.Id, in.Cols, in.Rows)
+
+	case "CloseSession":
+		var in struct {
+			Id string `json:"id"`
+		}
+		err := call.GetParameters(&in)
+		if err != nil {
+			return call.ReplyInvalidParameter(ctx, "parameters")
+		}
+		return s.comopenuc2deviceadminterminalInterface.CloseSession(ctx, VarlinkCall{call}, in.Id)
+
+	default:
+		return call.ReplyMethodNotFound(ctx, methodname)
+	}
+}
+
+// Generated varlink interface name
+
+func (s *VarlinkInterface) VarlinkGetName() string {
+	return `com.openuc2.deviceadmin.terminal`
+}
+
+// Generated varlink interface description
+
+func (s *VarlinkInterface) VarlinkGetDescription() string {
+	return `# com.openuc2.deviceadmin.terminal provides shell sessions for remote support.
+interface com.openuc2.deviceadmin.terminal
+
+# GetStatus returns the time (in seconds since the Unix epoch) when terminal sessions will be
+# automatically disabled, or 0 if terminal sessions are currently disabled.
+method GetStatus() -> (enabledUntil: int)
+
+# Enable allows terminal sessions to be opened for the specified duration (which may be shortened
+# to a maximum duration configured in the sidecar), after which all sessions are closed.
+method Enable(durationSec: int) -> (enabledUntil: int)
+
+# Disable prevents new terminal sessions from being opened, and closes all open sessions.
+method Disable() -> ()
+
+# OpenSession starts a new shell in a pseudo-terminal of the specified size, running as an
+# unprivileged user.
+method OpenSession(cols: int, rows: int) -> (id: string)
+
+# ReadSession streams output from the specified session as base64-encoded chunks, until the session
+# is closed; callers must set the "more" flag to receive more than the first chunk.
+method ReadSession(id: string) -> (chunk: string)
+
+# WriteSession sends the base64-encoded data as input to the specified session.
+method WriteSession(id: string, data: string) -> ()
+
+# ResizeSession changes the size of the specified session's pseudo-terminal.
+method ResizeSession(id: string, cols: int, rows: int) -> ()
+
+# CloseSession terminates the specified session.
+method CloseSession(id: string) -> ()
+
+# Terminal sessions are currently disabled.
+error Disabled (description: string)
+
+# The specified session does not exist.
+error UnknownSession (description: string)
+
+# The service was unable to perform the requested operation for an unspecified reason.
+error Unknown (description: string)
+`
+}
+
+// Generated service interface
+
+type VarlinkInterface struct {
+	comopenuc2deviceadminterminalInterface
+}
+
+func VarlinkNew(m comopenuc2deviceadminterminalInterface) *VarlinkInterface {
+	return &VarlinkInterface{m}
+}
@@ -0,0 +1,3 @@
+package comopenuc2deviceadminterminal
+
+//go:generate go tool varlink-go-interface-generator com.openuc2.deviceadmin.terminal.varlink
@@ -9,7 +9,9 @@ import (
 	"github.com/sargassum-world/godest/turbostreams"
 
 	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	"github.com/openUC2/machine-admin/internal/clients/diagnostics"
 	"github.com/openUC2/machine-admin/internal/clients/identity"
+	"github.com/openUC2/machine-admin/internal/clients/modemmanager"
 	"github.com/openUC2/machine-admin/internal/clients/networkmanager"
 	"github.com/openUC2/machine-admin/internal/clients/sidecar"
 	"github.com/openUC2/machine-admin/internal/clients/tailscale"
@@ -36,7 +38,9 @@ type Globals struct {
 
 	Sidecar *sidecar.Client
 
+	Diagnostics    *diagnostics.Client
 	Identity       *identity.Client
+	ModemManager   *modemmanager.Client
 	NetworkManager *networkmanager.Client
 	Tailscale      *tailscale.Client
 	UDisks2        *udisks2.Client
@@ -76,8 +80,20 @@ func NewGlobals(config conf.Config, l godest.Logger) (g *Globals, err error) {
 
 	g.Sidecar = sidecar.NewClient(config.Sidecar)
 
+	diagnosticsConfig, err := diagnostics.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't set up diagnostics config")
+	}
+	g.Diagnostics = diagnostics.NewClient(diagnosticsConfig, g.Base.Logger)
+
+	networkManagerConfig, err := networkmanager.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't set up NetworkManager config")
+	}
+
 	g.Identity = identity.NewClient(identity.Config{}, g.Base.Logger)
-	g.NetworkManager = networkmanager.NewClient(networkmanager.Config{}, g.Base.Logger)
+	g.ModemManager = modemmanager.NewClient(modemmanager.Config{}, g.Base.Logger)
+	g.NetworkManager = networkmanager.NewClient(networkManagerConfig, g.Base.Logger)
 	g.Tailscale = tailscale.NewClient(tailscale.Config{}, g.Base.Logger)
 	g.UDisks2 = udisks2.NewClient(udisks2.Config{}, g.Base.Logger)
 	g.Versioning = versioning.NewClient(versioning.Config{}, g.Base.Logger)
@@ -11,6 +11,7 @@ import (
 type Config struct {
 	Cache   ristretto.Config
 	HTTP    HTTPConfig
+	Roles   RolesConfig
 	Sidecar sidecar.Config
 }
 
@@ -20,6 +21,22 @@ type HTTPConfig struct {
 	GzipLevel int
 }
 
+// RolesConfig assigns network interfaces and connection profiles to the roles which the Internet
+// page's simplified view manages.
+type RolesConfig struct {
+	// HotspotIface is the network interface of the Wi-Fi device which provides the machine's own
+	// Wi-Fi network; if it's empty, the first Wi-Fi device which can act as a hotspot is used.
+	HotspotIface string
+	// UplinkIface is the network interface of the Wi-Fi device which connects to external Wi-Fi
+	// networks for internet access; if it's empty, the first other Wi-Fi device is used.
+	UplinkIface string
+	// HotspotConnProfileID is the ID of the connection profile for the machine's own Wi-Fi network.
+	HotspotConnProfileID string
+	// InternetConnProfileID is the ID of the built-in connection profile for connecting the uplink
+	// to an external Wi-Fi network.
+	InternetConnProfileID string
+}
+
 func GetConfig() (c Config, err error) {
 	c.Cache, err = getCacheConfig()
 	if err != nil {
@@ -0,0 +1,79 @@
+package handling
+
+import (
+	"context"
+	"time"
+)
+
+// changeDebounce is how long RepeatOnChange waits for a burst of changes to settle before it runs
+// its function, since a single change (e.g. activating a connection) is often reported as many
+// separate notifications.
+const changeDebounce = 250 * time.Millisecond
+
+// RepeatOnChange runs f immediately and then again after each notification on changes, until f
+// reports that it's done, f returns an error, changes is closed, or the context is canceled.
+func RepeatOnChange(
+	ctx context.Context, changes <-chan struct{}, f func() (done bool, err error),
+) error {
+	for {
+		if done, err := f(); done || err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+		}
+		timer := time.NewTimer(changeDebounce)
+	debounce:
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case _, ok := <-changes:
+				if !ok {
+					timer.Stop()
+					return nil
+				}
+			case <-timer.C:
+				break debounce
+			}
+		}
+	}
+}
+
+// WithPeriodicChanges returns a channel which relays the notifications on changes, and which also
+// receives a notification after every interval, until the context is canceled. This is useful for
+// data which can change without any notifications on changes.
+func WithPeriodicChanges(
+	ctx context.Context, changes <-chan struct{}, interval time.Duration,
+) <-chan struct{} {
+	merged := make(chan struct{})
+	go func() {
+		defer close(merged)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-changes:
+				if !ok {
+					return
+				}
+			case <-ticker.C:
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case merged <- struct{}{}:
+			}
+		}
+	}()
+	return merged
+}
@@ -49,19 +49,28 @@ func serveWSConn(
 
 func (h *Handlers) HandleCableGet() echo.HandlerFunc {
 	return func(c echo.Context) error {
-		// TODO: ensure that we do a CSRF check if/when users can send data over Action Cable
+		// Note: users can send data over Action Cable (e.g. terminal input), so the upgrader refuses
+		// cross-site requests (see checkCrossOrigin) to prevent cross-site WebSocket hijacking
 		wsc, err := h.wsu.Upgrade(c.Response(), c.Request(), nil)
 		if err != nil {
 			return errors.Wrap(err, "couldn't upgrade http request to websocket connection")
 		}
 
-		const wsMaxMessageSize = 512
+		// Note: terminal input is sent over Action Cable in chunks of at most 1024 UTF-16 code units
+		// (see maxInputLength in terminal.controller.js). Action Cable encodes each chunk as a JSON
+		// string inside another JSON string, so each code unit may take up to 7 bytes (e.g. a control
+		// character escaped as \\u0001); the message also includes the signed channel identifier.
+		// This limit leaves ample room for both, so that a full chunk never closes the connection.
+		const wsMaxMessageSize = 16 * 1024
 		wsc.SetReadLimit(wsMaxMessageSize)
 		sessionID := "global" // since we have no concept of user identity yet
 		serveWSConn(
 			c.Request(), wsc,
 			map[string]actioncable.ChannelFactory{
 				turbostreams.ChannelName: turbostreams.NewChannelFactory(h.tsb, sessionID, h.acs.Check),
+				TerminalChannelName: NewTerminalChannelFactory(
+					h.r.BasePath+"remote/terminal", h.scc, h.l, h.acs.Check,
+				),
 			},
 			h.wsu, h.l,
 		)
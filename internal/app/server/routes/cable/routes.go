@@ -4,10 +4,15 @@
 package cable
 
 import (
+	"net/http"
+
+	"filippo.io/csrf"
 	"github.com/gorilla/websocket"
 	"github.com/sargassum-world/godest"
 	"github.com/sargassum-world/godest/actioncable"
 	"github.com/sargassum-world/godest/turbostreams"
+
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
 )
 
 type Handlers struct {
@@ -15,6 +20,7 @@ type Handlers struct {
 
 	acs actioncable.Signer
 	tsb *turbostreams.Broker
+	scc *sc.Client
 
 	wsu websocket.Upgrader
 
@@ -22,15 +28,17 @@ type Handlers struct {
 }
 
 func New(
-	r godest.TemplateRenderer, acs actioncable.Signer, tsb *turbostreams.Broker, l godest.Logger,
+	r godest.TemplateRenderer, acs actioncable.Signer, tsb *turbostreams.Broker, scc *sc.Client,
+	l godest.Logger,
 ) *Handlers {
 	return &Handlers{
 		r:   r,
 		acs: acs,
 		tsb: tsb,
+		scc: scc,
 		wsu: websocket.Upgrader{
 			Subprotocols: actioncable.SupportedSubprotocols(),
-			// TODO: add parameters to the upgrader as needed
+			CheckOrigin:  checkCrossOrigin,
 		},
 		l: l,
 	}
@@ -39,3 +47,16 @@ func New(
 func (h *Handlers) Register(er godest.EchoRouter) {
 	er.GET(h.r.BasePath+"cable", h.HandleCableGet())
 }
+
+// crossOriginProtection makes the same cross-origin checks as the server's CSRF protection
+// middleware.
+var crossOriginProtection = csrf.New()
+
+// checkCrossOrigin refuses websocket upgrade requests from other sites, since users send data (e.g.
+// terminal input) over Action Cable. The server's CSRF protection middleware skips GET requests such
+// as websocket upgrades, so we must make the same checks here.
+func checkCrossOrigin(r *http.Request) bool {
+	upgrade := r.Clone(r.Context())
+	upgrade.Method = http.MethodPost // the checks are skipped for safe methods such as GET
+	return crossOriginProtection.Check(upgrade) == nil
+}
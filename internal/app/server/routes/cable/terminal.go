@@ -0,0 +1,243 @@
+package cable
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	"github.com/sargassum-world/godest/actioncable"
+	"github.com/sargassum-world/godest/handling"
+	"github.com/varlink/go/varlink"
+
+	termipc "github.com/openUC2/machine-admin/internal/app/ipc/terminal"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// TerminalChannelName is the name of the Action Cable channel for remote-support terminal sessions.
+const TerminalChannelName = "TerminalChannel"
+
+// TerminalChannel represents an Action Cable channel relaying a terminal session in the sidecar.
+type TerminalChannel struct {
+	identifier string
+	cols       int64
+	rows       int64
+
+	scc *sc.Client
+
+	mu        sync.Mutex
+	sessionID string
+
+	l godest.Logger
+}
+
+// parseTerminalIdentifier parses the signed name and the initial terminal size from the Action
+// Cable subscription identifier.
+func parseTerminalIdentifier(identifier string) (name string, cols, rows int64, err error) {
+	var i struct {
+		Name string `json:"name"`
+		Cols int64  `json:"cols"`
+		Rows int64  `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(identifier), &i); err != nil {
+		return "", 0, 0, errors.Wrap(
+			err, "couldn't parse terminal parameters from action cable subscription identifier",
+		)
+	}
+	const (
+		defaultCols = 80
+		defaultRows = 24
+	)
+	if i.Cols <= 0 {
+		i.Cols = defaultCols
+	}
+	if i.Rows <= 0 {
+		i.Rows = defaultRows
+	}
+	return i.Name, i.Cols, i.Rows, nil
+}
+
+// NewTerminalChannel checks the identifier with the specified checkers and returns a new
+// TerminalChannel instance. The identifier's name must match the specified name, so that names
+// signed for other purposes (e.g. Turbo Streams streams) can't be used to open terminal sessions.
+func NewTerminalChannel(
+	identifier, name string, scc *sc.Client, checkers []actioncable.IdentifierChecker,
+	l godest.Logger,
+) (*TerminalChannel, error) {
+	identifierName, cols, rows, err := parseTerminalIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if identifierName != name {
+		return nil, errors.Errorf("unexpected terminal channel name %s", identifierName)
+	}
+	for _, checker := range checkers {
+		if err := checker(identifier); err != nil {
+			return nil, errors.Wrap(err, "action cable subscription identifier failed checks")
+		}
+	}
+	return &TerminalChannel{
+		identifier: identifier,
+		cols:       cols,
+		rows:       rows,
+		scc:        scc,
+		l:          l,
+	}, nil
+}
+
+// Subscribe handles an Action Cable subscribe command from the client with the provided
+// [actioncable.Subscription], by opening a new terminal session in the sidecar and relaying its
+// output until the subscription is canceled.
+func (c *TerminalChannel) Subscribe(ctx context.Context, sub *actioncable.Subscription) error {
+	if sub.Identifier() != c.identifier {
+		return errors.Errorf(
+			"channel identifier %+v does not match subscription identifier %+v",
+			c.identifier, sub.Identifier(),
+		)
+	}
+
+	sessionID, err := c.openSession(ctx)
+	if err != nil {
+		return err
+	}
+	conn, err := c.scc.Open(ctx)
+	if err != nil {
+		c.closeSession(sessionID)
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	receive, err := termipc.ReadSession().Send(ctx, conn, varlink.More, sessionID)
+	if err != nil {
+		sc.CloseConn(conn, c.l)
+		c.closeSession(sessionID)
+		return errors.Wrap(err, "couldn't call sidecar's ReadSession method")
+	}
+
+	go func() {
+		defer sub.Close()
+		defer c.closeSession(sessionID)
+		defer sc.CloseConn(conn, c.l)
+
+		// The sidecar only notices that we've stopped reading once we close the session, so we must
+		// close the session as soon as the subscription is canceled:
+		go func() {
+			<-ctx.Done()
+			c.closeSession(sessionID)
+		}()
+
+		for {
+			chunk, flags, err := receive(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					c.l.Error(errors.Wrap(err, "couldn't receive terminal output from sidecar"))
+				}
+				return
+			}
+			if chunk != "" {
+				if err = handling.Except(sub.SendText(ctx, chunk), context.Canceled); err != nil {
+					c.l.Error(errors.Wrap(err, "couldn't send terminal output over action cable"))
+					return
+				}
+			}
+			if flags&varlink.Continues == 0 {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *TerminalChannel) openSession(ctx context.Context) (sessionID string, err error) {
+	conn, err := c.scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, c.l)
+
+	if sessionID, err = termipc.OpenSession().Call(ctx, conn, c.cols, c.rows); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's OpenSession method")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessionID = sessionID
+	return sessionID, nil
+}
+
+func (c *TerminalChannel) closeSession(sessionID string) {
+	// The subscription's context may already be canceled, so we need a separate context:
+	const timeout = 5 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := c.scc.Open(ctx)
+	if err != nil {
+		c.l.Error(errors.Wrap(err, "couldn't open connection to sidecar"))
+		return
+	}
+	defer sc.CloseConn(conn, c.l)
+
+	if err = termipc.CloseSession().Call(ctx, conn, sessionID); err != nil {
+		c.l.Error(errors.Wrap(err, "couldn't call sidecar's CloseSession method"))
+	}
+}
+
+// Perform handles an Action Cable action command from the client, for sending input to the
+// terminal session or resizing it.
+func (c *TerminalChannel) Perform(data string) error {
+	var action struct {
+		Action string `json:"action"`
+		Data   string `json:"data"`
+		Cols   int64  `json:"cols"`
+		Rows   int64  `json:"rows"`
+	}
+	if err := json.Unmarshal([]byte(data), &action); err != nil {
+		return errors.Wrap(err, "couldn't parse terminal action")
+	}
+
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+	if sessionID == "" {
+		return errors.New("terminal session has not been opened yet")
+	}
+
+	const timeout = 5 * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	conn, err := c.scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, c.l)
+
+	switch action.Action {
+	default:
+		return errors.Errorf("unknown terminal action %s", action.Action)
+	case "input":
+		if err = termipc.WriteSession().Call(
+			ctx, conn, sessionID, base64.StdEncoding.EncodeToString([]byte(action.Data)),
+		); err != nil {
+			return errors.Wrap(err, "couldn't call sidecar's WriteSession method")
+		}
+	case "resize":
+		if err = termipc.ResizeSession().Call(
+			ctx, conn, sessionID, action.Cols, action.Rows,
+		); err != nil {
+			return errors.Wrap(err, "couldn't call sidecar's ResizeSession method")
+		}
+	}
+	return nil
+}
+
+// NewTerminalChannelFactory creates an [actioncable.ChannelFactory] for terminal sessions on
+// channels with the specified name.
+func NewTerminalChannelFactory(
+	name string, scc *sc.Client, l godest.Logger, checkers ...actioncable.IdentifierChecker,
+) actioncable.ChannelFactory {
+	return func(identifier string) (actioncable.Channel, error) {
+		return NewTerminalChannel(identifier, name, scc, checkers, l)
+	}
+}
@@ -0,0 +1,178 @@
+package internet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// checkpointRollbackTimeout is how long the user has to confirm risky changes to network settings
+// before NetworkManager automatically rolls them back. It needs to be long enough for the user to
+// reconnect to the device (e.g. after changing the hotspot's password).
+const checkpointRollbackTimeout = 2 * time.Minute
+
+func (h *Handlers) HandleCheckpointGetByID() echo.HandlerFunc {
+	t := "internet/checkpoints/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		id := c.Param("id")
+		redirectTarget := c.QueryParam("redirect-target")
+
+		// Run queries
+		vd := CheckpointViewData{
+			ID:             id,
+			RedirectTarget: redirectTarget,
+		}
+		// Note: the checkpoint no longer exists once it's been rolled back, in which case we show the
+		// page anyways so that we can tell the user that their changes were reverted
+		checkpoint, err := h.nmc.GetCheckpoint(id)
+		vd.Exists = err == nil
+		if vd.Exists {
+			vd.RollbackTime = checkpoint.RollbackTime()
+		}
+
+		// Produce output
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+type CheckpointViewData struct {
+	ID             string
+	Exists         bool
+	RollbackTime   time.Time
+	RedirectTarget string
+}
+
+func (h *Handlers) HandleCheckpointPostByID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		id := c.Param("id")
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		// We use the background context for the same reasons as in HandleConnProfilePostByUUID:
+		ctx := context.Background()
+		if _, err := h.nmc.GetCheckpoint(id); err != nil {
+			// The checkpoint was probably already rolled back, so we redirect the user to a page which
+			// will tell them about that
+			return c.Redirect(http.StatusSeeOther, checkpointPath(h.r.BasePath, id, redirectTarget))
+		}
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid checkpoint state %s", state,
+			))
+		case "destroyed":
+			if err := destroyCheckpointViaSidecar(ctx, id, h.scc, h.l); err != nil {
+				return errors.Wrapf(err, "couldn't keep changes since checkpoint %s", id)
+			}
+			// Redirect user
+			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		case "rolled-back":
+			if err := rollbackCheckpointViaSidecar(ctx, id, h.scc, h.l); err != nil {
+				return errors.Wrapf(err, "couldn't roll back to checkpoint %s", id)
+			}
+			// Redirect user
+			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		}
+	}
+}
+
+// checkpointPath returns the path of the page for confirming or reverting changes since the
+// checkpoint.
+func checkpointPath(basePath, id, redirectTarget string) string {
+	return fmt.Sprintf(
+		"%sinternet/checkpoints/%s?%s", basePath, url.PathEscape(id), url.Values{
+			"redirect-target": []string{redirectTarget},
+		}.Encode(),
+	)
+}
+
+func createCheckpointViaSidecar(
+	ctx context.Context, rollbackTimeout time.Duration, scc *sc.Client, l godest.Logger,
+) (id string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if id, err = nmipc.CreateCheckpoint().Call(
+		ctx, conn, int64(rollbackTimeout.Seconds()),
+	); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's CreateCheckpoint method")
+	}
+	return id, nil
+}
+
+func destroyCheckpointViaSidecar(
+	ctx context.Context, id string, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if err := nmipc.DestroyCheckpoint().Call(ctx, conn, id); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's DestroyCheckpoint method")
+	}
+	return nil
+}
+
+func rollbackCheckpointViaSidecar(
+	ctx context.Context, id string, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if err := nmipc.RollbackCheckpoint().Call(ctx, conn, id); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's RollbackCheckpoint method")
+	}
+	return nil
+}
+
+// Checkpoint-protected changes
+
+// checkpointedChange creates a checkpoint before running the change, so that NetworkManager will
+// roll back the change unless the user confirms it. It returns the ID of the checkpoint.
+func checkpointedChange(
+	ctx context.Context, change func() error, scc *sc.Client, l godest.Logger,
+) (checkpointID string, err error) {
+	if checkpointID, err = createCheckpointViaSidecar(
+		ctx, checkpointRollbackTimeout, scc, l,
+	); err != nil {
+		var existsErr *nmipc.CheckpointExists
+		if errors.As(err, &existsErr) {
+			// Creating our checkpoint would confirm someone else's pending changes on their behalf
+			return "", echo.NewHTTPError(http.StatusConflict, fmt.Sprintf(
+				"another change to network settings must be kept or reverted first (%s)",
+				existsErr.Description,
+			))
+		}
+		return "", errors.Wrap(err, "couldn't create checkpoint before making changes")
+	}
+	if err = change(); err != nil {
+		// There's no point in asking the user to confirm a change which failed, so we revert any
+		// partial changes immediately:
+		if rerr := rollbackCheckpointViaSidecar(ctx, checkpointID, scc, l); rerr != nil {
+			l.Error(errors.Wrapf(rerr, "couldn't roll back to checkpoint %s", checkpointID))
+		}
+		return "", err
+	}
+	return checkpointID, nil
+}
@@ -0,0 +1,193 @@
+package internet
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// maxCertificateSize is the size limit for uploaded certificate and private key files; real-world
+// files are only a few kilobytes.
+const maxCertificateSize = 64 * 1024
+
+func parseConnProfileSettings8021xField(
+	key nm.ConnProfileSettingsKey, rawValues []string,
+) (parsedValue any, err error) {
+	rawValue := rawValues[len(rawValues)-1]
+	switch key.Key {
+	default:
+		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+	case "eap":
+		eap := nm.ConnProfileSettings8021xEAP(rawValue)
+		if info := eap.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return eap, nil
+	case "phase2-auth":
+		phase2Auth := nm.ConnProfileSettings8021xPhase2Auth(rawValue)
+		if info := phase2Auth.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return phase2Auth, nil
+	case "ca-cert", "client-cert", "private-key":
+		// Note: the value is the path of a file stored by storeConnProfileCertsViaSidecar, or empty
+		// to remove the file from the settings
+		return nm.ConnProfileSettings8021xCert{Path: rawValue}, nil
+	case "identity", "anonymous-identity", "domain-suffix-match", "password",
+		"private-key-password":
+		return rawValue, nil
+	}
+}
+
+// filter8021xUpdate removes 802-1x updates which shouldn't be applied: all of them if the Wi-Fi
+// key management mode doesn't use them, and secrets which were left empty in the form as a signal
+// to keep the existing secrets.
+func filter8021xUpdate(updateValues map[nm.ConnProfileSettingsKey]any, formValues url.Values) {
+	keyMgmt := nm.ConnProfileSettingsWifiSecKeyMgmt(
+		formValues.Get("802-11-wireless-security.key-mgmt"),
+	)
+	for key, value := range updateValues {
+		if key.Section != "802-1x" {
+			continue
+		}
+		if !keyMgmt.UsesEAP() {
+			delete(updateValues, key)
+			continue
+		}
+		if key.Key == "password" || key.Key == "private-key-password" {
+			if value == "" {
+				delete(updateValues, key)
+			}
+		}
+	}
+}
+
+func check8021x(uid uuid.UUID, updateValues map[nm.ConnProfileSettingsKey]any) error {
+	key := nm.NewConnProfileSettingsKey
+	for _, certKey := range []string{"ca-cert", "client-cert", "private-key"} {
+		// NetworkManager runs as root, so it could be made to read any file on the machine; thus, we
+		// only allow the files stored by storeConnProfileCertsViaSidecar for the connection profile:
+		cert, ok := updateValues[key("802-1x", certKey)].(nm.ConnProfileSettings8021xCert)
+		if ok && cert.Path != "" && !nm.IsStoredCertPath(uid, certKey, cert.Path) {
+			return errors.Errorf(
+				"%s file %s must be uploaded from the connection profile's settings form",
+				certKey, cert.Path,
+			)
+		}
+	}
+	eap, ok := updateValues[key("802-1x", "eap")].(nm.ConnProfileSettings8021xEAP)
+	if !ok {
+		return nil
+	}
+	identity, _ := updateValues[key("802-1x", "identity")].(string)
+	if identity == "" {
+		return errors.New("enterprise Wi-Fi authentication requires an identity (username)")
+	}
+	if eap != "tls" {
+		return nil
+	}
+	for _, certKey := range []string{"client-cert", "private-key"} {
+		// Note: the form omits files which can't be changed (e.g. embedded files), so we only check
+		// files which are being changed
+		cert, ok := updateValues[key("802-1x", certKey)].(nm.ConnProfileSettings8021xCert)
+		if ok && !cert.IsSet() {
+			return errors.New("TLS authentication requires a client certificate and a private key")
+		}
+	}
+	return nil
+}
+
+// storeConnProfileCertsViaSidecar stores any certificate and private key files uploaded in the
+// form, and sets the corresponding form values to the paths of the stored files.
+func storeConnProfileCertsViaSidecar(
+	ctx context.Context, c echo.Context, uid uuid.UUID, formValues url.Values,
+	scc *sc.Client, l godest.Logger,
+) error {
+	for _, kind := range []string{"ca-cert", "client-cert", "private-key"} {
+		// Note: the field name has no "." so that updateConnProfile doesn't parse it as a setting
+		file, err := c.FormFile("upload:" + kind)
+		if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+			continue // forms other than the full settings form don't upload files
+		}
+		if err != nil {
+			return errors.Wrapf(err, "couldn't load uploaded %s file", kind)
+		}
+		if file.Size == 0 {
+			continue // the browser submits an empty file for file inputs left blank
+		}
+		if file.Size > maxCertificateSize {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"uploaded %s file must not be larger than %d bytes", kind, maxCertificateSize,
+			))
+		}
+		f, err := file.Open()
+		if err != nil {
+			return errors.Wrapf(err, "couldn't open uploaded %s file", kind)
+		}
+		data, err := io.ReadAll(io.LimitReader(f, maxCertificateSize))
+		if cerr := f.Close(); cerr != nil {
+			l.Error(errors.Wrapf(cerr, "couldn't close uploaded %s file", kind))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "couldn't read uploaded %s file", kind)
+		}
+
+		filePath, err := storeCertificateViaSidecar(ctx, uid, kind, data, scc, l)
+		if err != nil {
+			// We don't wrap the error, which may be an HTTP error about an invalid file:
+			return err
+		}
+		formValues.Set("802-1x."+kind, filePath)
+	}
+	return nil
+}
+
+func storeCertificateViaSidecar(
+	ctx context.Context, uid uuid.UUID, kind string, data []byte, scc *sc.Client, l godest.Logger,
+) (filePath string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if filePath, err = nmipc.StoreCertificate().Call(
+		ctx, conn, uid.String(), kind, base64.StdEncoding.EncodeToString(data),
+	); err != nil {
+		var invalidErr *nmipc.InvalidCertificate
+		if errors.As(err, &invalidErr) {
+			return "", echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid %s file: %s", kind, invalidErr.Description,
+			))
+		}
+		return "", errors.Wrap(err, "couldn't call sidecar's StoreCertificate method")
+	}
+	return filePath, nil
+}
+
+func deleteCertificatesViaSidecar(
+	ctx context.Context, uid uuid.UUID, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if err := nmipc.DeleteCertificates().Call(ctx, conn, uid.String()); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's DeleteCertificates method")
+	}
+	return nil
+}
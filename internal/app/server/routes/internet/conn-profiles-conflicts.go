@@ -0,0 +1,147 @@
+package internet
+
+import (
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// maxConnProfileSnapshots is the number of connection profile snapshots which are remembered for
+// explaining conflicting changes.
+const maxConnProfileSnapshots = 64
+
+// connProfileSnapshots remembers the settings of the connection profiles shown in settings forms,
+// so that if a form's submission is rejected because the connection profile was changed in the
+// meantime, the user can be shown what was changed.
+type connProfileSnapshots struct {
+	mu        sync.Mutex
+	order     []string
+	snapshots map[string]nm.ConnProfileSnapshot
+}
+
+func newConnProfileSnapshots() *connProfileSnapshots {
+	return &connProfileSnapshots{
+		snapshots: make(map[string]nm.ConnProfileSnapshot),
+	}
+}
+
+func connProfileSnapshotKey(uid uuid.UUID, version string) string {
+	return uid.String() + "/" + version
+}
+
+func (s *connProfileSnapshots) add(uid uuid.UUID, snapshot nm.ConnProfileSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := connProfileSnapshotKey(uid, snapshot.Version)
+	if _, ok := s.snapshots[key]; ok {
+		return
+	}
+	if len(s.order) >= maxConnProfileSnapshots {
+		delete(s.snapshots, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.order = append(s.order, key)
+	s.snapshots[key] = snapshot
+}
+
+func (s *connProfileSnapshots) get(uid uuid.UUID, version string) (nm.ConnProfileSnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot, ok := s.snapshots[connProfileSnapshotKey(uid, version)]
+	return snapshot, ok
+}
+
+type ConnProfileConflictViewData struct {
+	ConnProfile nm.ConnProfile
+	// HasOriginal is false if the settings at the version which the user had edited are unknown (e.g.
+	// because the server was restarted), in which case Changes compares the user's submission with
+	// the current settings
+	HasOriginal bool
+	Changes     []ConnProfileSettingChange
+}
+
+// ConnProfileSettingChange describes how a setting differs between the version of a connection
+// profile which the user had edited, the current version, and the user's submission.
+type ConnProfileSettingChange struct {
+	Key          string
+	Original     string
+	Current      string
+	Submitted    string
+	HasSubmitted bool
+}
+
+func (h *Handlers) renderConnProfileConflict(
+	c echo.Context, uid uuid.UUID, version string, current nm.ConnProfileSnapshot,
+	formValues url.Values,
+) error {
+	t := "internet/conn-profiles/conflict.page.tmpl"
+	h.r.MustHave(t)
+
+	// Run queries
+	vd := ConnProfileConflictViewData{}
+	var err error
+	if vd.ConnProfile, err = h.nmc.GetConnProfileByUUID(c.Request().Context(), uid); err != nil {
+		return errors.Wrapf(err, "couldn't get connection profile %s", uid)
+	}
+	var original nm.ConnProfileSnapshot
+	original, vd.HasOriginal = h.snapshots.get(uid, version)
+	vd.Changes = listConnProfileSettingChanges(original, vd.HasOriginal, current, formValues)
+
+	// Produce output
+	return h.r.Page(c.Response(), c.Request(), http.StatusConflict, t, vd, struct{}{})
+}
+
+func listConnProfileSettingChanges(
+	original nm.ConnProfileSnapshot, hasOriginal bool, current nm.ConnProfileSnapshot,
+	formValues url.Values,
+) (changes []ConnProfileSettingChange) {
+	keys := make(map[string]bool)
+	if hasOriginal {
+		for key := range original.Settings {
+			keys[key] = true
+		}
+		for key := range current.Settings {
+			keys[key] = true
+		}
+	} else {
+		for key := range formValues {
+			if _, err := nm.ParseConnProfileSettingsKey(key); err == nil {
+				keys[key] = true
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if nm.IsSecretSetting(key) || nm.IsVolatileConnProfileSetting(key) {
+			continue
+		}
+		change := ConnProfileSettingChange{
+			Key:      key,
+			Original: original.Settings[key],
+			Current:  current.Settings[key],
+		}
+		if values, ok := formValues[key]; ok {
+			change.Submitted = strings.Join(values, ", ")
+			change.HasSubmitted = true
+		}
+		if hasOriginal && change.Original == change.Current {
+			continue
+		}
+		if !hasOriginal && change.Submitted == change.Current {
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
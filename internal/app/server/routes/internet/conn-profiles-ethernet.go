@@ -0,0 +1,109 @@
+package internet
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+func parseConnProfileSettingsEthernetField(
+	key nm.ConnProfileSettingsKey, rawValues []string,
+) (parsedValue any, err error) {
+	rawValue := rawValues[len(rawValues)-1] // selects the last value to account for single checkboxes
+	switch key.Key {
+	default:
+		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+	case "assigned-mac-address":
+		return parseAssignedMACAddress(rawValue)
+	case "auto-negotiate":
+		autoNegotiate, err := parseCheckbox(rawValue, "on", "off")
+		if err != nil {
+			return false, errors.Wrapf(err, "couldn't parse value for %s", key)
+		}
+		return autoNegotiate, nil
+	case "duplex":
+		duplex := nm.ConnProfileSettings8023EthernetDuplex(rawValue)
+		if info := duplex.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return duplex, nil
+	case "mtu":
+		return parseMTU(rawValue)
+	case "speed":
+		value, err := strconv.ParseUint(rawValue, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s as non-negative integer", rawValue)
+		}
+		return uint32(value), nil
+	case "wake-on-lan":
+		flags := make([]string, 0, len(rawValues))
+		for _, rawValue := range rawValues {
+			if rawValue == "" {
+				continue
+			}
+			flags = append(flags, rawValue)
+		}
+		return nm.NewConnProfileSettings8023EthernetWoL(flags)
+	}
+}
+
+// parseAssignedMACAddress parses the value of the assigned-mac-address setting of the
+// 802-3-ethernet and 802-11-wireless sections, which is either empty (to use the global default),
+// one of [nm.AssignedMACAddressModes], or a MAC address.
+func parseAssignedMACAddress(rawValue string) (string, error) {
+	if rawValue == "" {
+		return "", nil
+	}
+	if _, ok := nm.AssignedMACAddressModes[rawValue]; ok {
+		return rawValue, nil
+	}
+	address, err := net.ParseMAC(rawValue)
+	const macAddressLen = 6
+	if err != nil || len(address) != macAddressLen {
+		return "", errors.Errorf(
+			"MAC address %s must be written like 02:00:00:12:34:56", rawValue,
+		)
+	}
+	return strings.ToUpper(address.String()), nil
+}
+
+func parseMTU(rawValue string) (uint32, error) {
+	value, err := strconv.ParseUint(rawValue, 10, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "couldn't parse %s as non-negative integer", rawValue)
+	}
+	const (
+		minMTU = 68 // the minimum MTU for IPv4
+		maxMTU = 65535
+	)
+	if value != 0 && (value < minMTU || value > maxMTU) {
+		return 0, errors.Errorf(
+			"MTU %d must be 0 (automatic) or in range [%d, %d]", value, minMTU, maxMTU,
+		)
+	}
+	return uint32(value), nil
+}
+
+// checkConnProfileEthernet checks the consistency of the ethernet link settings which would result
+// from the update.
+func checkConnProfileEthernet(updateValues map[nm.ConnProfileSettingsKey]any) error {
+	key := nm.NewConnProfileSettingsKey
+	autoNegotiate, ok := updateValues[key("802-3-ethernet", "auto-negotiate")].(bool)
+	if !ok || autoNegotiate {
+		return nil
+	}
+	type duplexMode = nm.ConnProfileSettings8023EthernetDuplex
+	speed, _ := updateValues[key("802-3-ethernet", "speed")].(uint32)
+	duplex, _ := updateValues[key("802-3-ethernet", "duplex")].(duplexMode)
+	if (speed == 0) != (duplex == "") {
+		return errors.New(
+			"without auto-negotiation, speed and duplex must either both be set (to force them) or " +
+				"both be unset (to leave the link unchanged)",
+		)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package internet
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+var (
+	// simPINPattern matches the PINs which SIM cards accept.
+	simPINPattern = regexp.MustCompile(`^[0-9]{4,8}$`)
+	// gsmNetworkIDPattern matches the MCC and MNC of a mobile network, e.g. 26201.
+	gsmNetworkIDPattern = regexp.MustCompile(`^[0-9]{5,6}$`)
+)
+
+func parseConnProfileSettingsGSMField(
+	key nm.ConnProfileSettingsKey, rawValues []string,
+) (parsedValue any, err error) {
+	rawValue := rawValues[len(rawValues)-1] // selects the last value to account for single checkboxes
+	switch key.Key {
+	default:
+		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+	case "apn":
+		const maxAPNLen = 64
+		if len(rawValue) > maxAPNLen {
+			return nil, errors.Errorf("the APN must be at most %d characters long", maxAPNLen)
+		}
+		return rawValue, nil
+	case "auto-config", "home-only":
+		value, err := parseCheckbox(rawValue, "on", "off")
+		if err != nil {
+			return false, errors.Wrapf(err, "couldn't parse value for %s", key)
+		}
+		return value, nil
+	case "mtu":
+		value, err := strconv.ParseUint(rawValue, 10, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s as non-negative integer", rawValue)
+		}
+		return uint32(value), nil
+	case "network-id":
+		if rawValue != "" && !gsmNetworkIDPattern.MatchString(rawValue) {
+			return nil, errors.Errorf(
+				"network ID %s must be the 5- or 6-digit code of the network (e.g. 26201)", rawValue,
+			)
+		}
+		return rawValue, nil
+	case "username", "password":
+		return rawValue, nil
+	case "pin":
+		if rawValue != "" && !simPINPattern.MatchString(rawValue) {
+			return nil, errors.New("the SIM PIN must have between 4 and 8 digits")
+		}
+		return rawValue, nil
+	}
+}
+
+// filterGSMUpdate removes the secrets from the update if they were left empty, which signals that
+// the existing secrets should be kept (because the existing secrets aren't shown in the form).
+func filterGSMUpdate(updateValues map[nm.ConnProfileSettingsKey]any) {
+	for _, secret := range []string{"password", "pin"} {
+		key := nm.NewConnProfileSettingsKey("gsm", secret)
+		if value, ok := updateValues[key].(string); ok && value == "" {
+			delete(updateValues, key)
+		}
+	}
+}
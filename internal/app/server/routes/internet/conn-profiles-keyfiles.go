@@ -0,0 +1,205 @@
+package internet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// maxKeyfileSize is the maximum size (in bytes) of keyfiles which can be imported.
+const maxKeyfileSize = 64 * 1024
+
+// Exporting
+
+func (h *Handlers) HandleConnProfileKeyfileGetByUUID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return h.exportConnProfile(c, false)
+	}
+}
+
+func (h *Handlers) HandleConnProfileKeyfilePostByUUID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return h.exportConnProfile(c, true)
+	}
+}
+
+func (h *Handlers) exportConnProfile(c echo.Context, withSecrets bool) error {
+	// Parse params
+	rawUUID := c.Param("uuid")
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unparsable UUID %s", rawUUID))
+	}
+
+	// Run queries
+	ctx := c.Request().Context()
+	// The server isn't allowed to read the files of connection profiles, so we get the keyfile
+	// through the sidecar, which limits how often secrets can be exported and records who they were
+	// exported to:
+	keyfile, err := exportConnProfileViaSidecar(ctx, uid, withSecrets, c.RealIP(), h.scc, h.l)
+	if err != nil {
+		var rateErr *nmipc.RateLimited
+		if errors.As(err, &rateErr) {
+			c.Response().Header().Set(
+				echo.HeaderRetryAfter, strconv.FormatInt(rateErr.RetryAfterSec, 10),
+			)
+			return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf(
+				"too many secrets were revealed recently; try again in %d seconds",
+				rateErr.RetryAfterSec,
+			))
+		}
+		var notKeyfileErr *nmipc.NotKeyfile
+		if errors.As(err, &notKeyfileErr) {
+			return echo.NewHTTPError(http.StatusNotFound, notKeyfileErr.Description)
+		}
+		return err
+	}
+	parsed, err := nm.ParseKeyfile([]byte(keyfile))
+	if err != nil {
+		return errors.Wrapf(err, "couldn't parse keyfile of connection profile %s", uid)
+	}
+
+	// Produce output
+	// Note: the keyfile may include secrets, so it must not be cached anywhere
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(
+		"attachment; filename=\"%s.nmconnection\"", keyfileBaseName(parsed.ID(), uid),
+	))
+	return c.Blob(http.StatusOK, "application/octet-stream", []byte(keyfile))
+}
+
+// keyfileBaseName returns a name for the downloaded keyfile of a connection profile which is safe
+// to use as a filename.
+func keyfileBaseName(id string, uid uuid.UUID) string {
+	name := strings.Map(func(r rune) rune {
+		isSafe := unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r)
+		if r > unicode.MaxASCII || !isSafe {
+			return '_'
+		}
+		return r
+	}, id)
+	if strings.Trim(name, "_.") == "" {
+		return uid.String()
+	}
+	return name
+}
+
+func exportConnProfileViaSidecar(
+	ctx context.Context, uid uuid.UUID, withSecrets bool, requester string,
+	scc *sc.Client, l godest.Logger,
+) (keyfile string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if keyfile, err = nmipc.ExportConnProfile().Call(
+		ctx, conn, uid.String(), withSecrets, requester,
+	); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's ExportConnProfile method")
+	}
+	return keyfile, nil
+}
+
+// Importing
+
+func (h *Handlers) importConnProfile(ctx context.Context, c echo.Context) error {
+	// Parse params
+	file, err := c.FormFile("upload:keyfile")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "no keyfile was uploaded")
+	}
+	if file.Size > maxKeyfileSize {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"keyfile must not be larger than %d bytes", maxKeyfileSize,
+		))
+	}
+	f, err := file.Open()
+	if err != nil {
+		return errors.Wrap(err, "couldn't open uploaded keyfile")
+	}
+	data, err := io.ReadAll(io.LimitReader(f, maxKeyfileSize))
+	if cerr := f.Close(); cerr != nil {
+		h.l.Error(errors.Wrap(cerr, "couldn't close uploaded keyfile"))
+	}
+	if err != nil {
+		return errors.Wrap(err, "couldn't read uploaded keyfile")
+	}
+	keyfile, err := nm.ParseKeyfile(data)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid keyfile: %s", err))
+	}
+
+	// Run queries
+	if isFactoryConnProfile(h.roles, keyfile.ID()) {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"the name %s is reserved for a built-in connection profile", keyfile.ID(),
+		))
+	}
+	uid := keyfile.UUID()
+	if uid == (uuid.UUID{}) {
+		uid = uuid.New()
+		keyfile = keyfile.WithUUID(uid)
+	}
+	connProfiles, err := h.nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't list connection profiles")
+	}
+	for _, connProfile := range connProfiles {
+		id := connProfile.Settings.Conn.ID
+		if connProfile.Settings.Conn.UUID == uid && isFactoryConnProfile(h.roles, id) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"the keyfile would replace the built-in connection profile %s", id,
+			))
+		}
+	}
+	if err = importConnProfileViaSidecar(ctx, keyfile, c.RealIP(), h.scc, h.l); err != nil {
+		var invalidErr *nmipc.InvalidKeyfile
+		if errors.As(err, &invalidErr) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid keyfile: %s", invalidErr.Description,
+			))
+		}
+		return err
+	}
+	if err = reloadConnProfilesViaSidecar(ctx, h.scc, h.l); err != nil {
+		return errors.Wrapf(err, "couldn't reload through sidecar")
+	}
+
+	// Redirect user
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf(
+		"%sinternet/conn-profiles/%s?mode=%s", h.r.BasePath, uid, sh.ViewModeAdvanced,
+	))
+}
+
+func importConnProfileViaSidecar(
+	ctx context.Context, keyfile nm.Keyfile, requester string, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if err := nmipc.ImportConnProfile().Call(
+		ctx, conn, string(keyfile.Bytes()), requester,
+	); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's ImportConnProfile method")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package internet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// New connection profiles
+
+func (h *Handlers) HandleConnProfilesNewGet() echo.HandlerFunc {
+	t := "internet/conn-profiles/new.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		kind := c.QueryParam("kind")
+		if _, err := newConnProfileDefaults(kind); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		// Run queries
+		vd := NewConnProfileViewData{Kind: kind}
+		devices, err := h.nmc.GetDevices(c.Request().Context())
+		if err != nil {
+			return errors.Wrap(err, "couldn't list network devices")
+		}
+		deviceType := "wifi"
+		switch kind {
+		case "ethernet":
+			deviceType = "ethernet"
+		case "cellular":
+			deviceType = "modem"
+		}
+		for _, device := range devices {
+			if device.Type.Info().Short != deviceType {
+				continue
+			}
+			vd.Interfaces = append(vd.Interfaces, device.ControlInterface)
+		}
+		slices.Sort(vd.Interfaces)
+
+		// Produce output
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+type NewConnProfileViewData struct {
+	Kind       string
+	Interfaces []string
+}
+
+// newConnProfileDefaults returns sensible default settings for a new connection profile of the
+// specified kind, which may be "wifi" (for connecting to an external Wi-Fi network), "hotspot"
+// (for the machine to host its own Wi-Fi network), "ethernet", or "cellular" (for connecting to a
+// mobile network through a modem).
+func newConnProfileDefaults(kind string) (settings map[nm.ConnProfileSettingsKey]any, err error) {
+	key := nm.NewConnProfileSettingsKey
+	settings = map[nm.ConnProfileSettingsKey]any{
+		key("connection", "autoconnect"): true,
+		key("ipv4", "method"):            nm.ConnProfileSettingsIPv4Method("auto"),
+		key("ipv6", "method"):            nm.ConnProfileSettingsIPv6Method("auto"),
+	}
+	switch kind {
+	default:
+		return nil, errors.Errorf("unknown kind of connection profile %s", kind)
+	case "wifi":
+		settings[key("connection", "type")] = nm.ConnProfileSettingsConnType("802-11-wireless")
+		settings[key("802-11-wireless", "mode")] = nm.ConnProfileSettingsWifiMode("infrastructure")
+	case "hotspot":
+		settings[key("connection", "type")] = nm.ConnProfileSettingsConnType("802-11-wireless")
+		settings[key("802-11-wireless", "mode")] = nm.ConnProfileSettingsWifiMode("ap")
+		settings[key("802-11-wireless", "band")] = nm.ConnProfileSettingsWifiBand("bg")
+		const wifiSec = "802-11-wireless-security"
+		settings[key(wifiSec, "key-mgmt")] = nm.ConnProfileSettingsWifiSecKeyMgmt("wpa-psk")
+		settings[key(wifiSec, "proto")] = nm.EnumSet[nm.ConnProfileSettingsWifiSecProto]{"rsn"}
+		settings[key(wifiSec, "pairwise")] = nm.EnumSet[nm.ConnProfileSettingsWifiSecPairwise]{"ccmp"}
+		settings[key(wifiSec, "group")] = nm.EnumSet[nm.ConnProfileSettingsWifiSecGroup]{"ccmp"}
+		settings[key("ipv4", "method")] = nm.ConnProfileSettingsIPv4Method("shared")
+		settings[key("ipv6", "method")] = nm.ConnProfileSettingsIPv6Method("disabled")
+	case "ethernet":
+		settings[key("connection", "type")] = nm.ConnProfileSettingsConnType("802-3-ethernet")
+	case "cellular":
+		settings[key("connection", "type")] = nm.ConnProfileSettingsConnType("gsm")
+		// Note: if no APN is specified, the APN is looked up from the mobile broadband provider
+		// database
+		settings[key("gsm", "auto-config")] = true
+	}
+	return settings, nil
+}
+
+func addConnProfile(
+	ctx context.Context, formValues url.Values, roles conf.RolesConfig, nmc *nm.Client,
+) (uid uuid.UUID, err error) {
+	kind := formValues.Get("kind")
+	settings, err := newConnProfileDefaults(kind)
+	if err != nil {
+		return uuid.UUID{}, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	for _, rawKey := range []string{
+		"connection.id", "connection.interface-name", "connection.autoconnect",
+		"connection.autoconnect-priority", "802-11-wireless.ssid", "802-11-wireless.band",
+		"gsm.apn", "gsm.username", "gsm.password", "gsm.pin",
+	} {
+		rawValues := formValues[rawKey]
+		if len(rawValues) < 1 {
+			continue
+		}
+		key, err := nm.ParseConnProfileSettingsKey(rawKey)
+		if err != nil {
+			return uuid.UUID{}, err
+		}
+		if settings[key], err = parseConnProfileSettingsField(key, rawValues); err != nil {
+			return uuid.UUID{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"couldn't parse (key, value) pair: (%s, %+v): %s", key, rawValues, err,
+			))
+		}
+	}
+	interfaceKey := nm.NewConnProfileSettingsKey("connection", "interface-name")
+	if settings[interfaceKey] == "" {
+		delete(settings, interfaceKey) // the profile can be activated on any compatible interface
+	}
+	psk := formValues.Get("802-11-wireless-security.psk")
+	if err = checkNewConnProfile(kind, settings, psk, roles); err != nil {
+		return uuid.UUID{}, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if psk != "" {
+		settings[nm.NewConnProfileSettingsKey("802-11-wireless-security", "key-mgmt")] = nm.
+			ConnProfileSettingsWifiSecKeyMgmt("wpa-psk")
+		settings[nm.NewConnProfileSettingsKey("802-11-wireless-security", "psk")] = psk
+	}
+
+	if uid, err = nmc.AddConnProfile(ctx, "save", settings); err != nil {
+		return uuid.UUID{}, errors.Wrap(err, "couldn't add connection profile")
+	}
+	return uid, nil
+}
+
+func checkNewConnProfile(
+	kind string, settings map[nm.ConnProfileSettingsKey]any, psk string, roles conf.RolesConfig,
+) error {
+	id, _ := settings[nm.NewConnProfileSettingsKey("connection", "id")].(string)
+	if id == "" {
+		return errors.New("the connection profile must have a name")
+	}
+	if isFactoryConnProfile(roles, id) {
+		return errors.Errorf("the name %s is reserved for a built-in connection profile", id)
+	}
+	if kind == "ethernet" || kind == "cellular" {
+		return nil
+	}
+
+	ssid, _ := settings[nm.NewConnProfileSettingsKey("802-11-wireless", "ssid")].([]byte)
+	if len(ssid) == 0 {
+		return errors.New("the Wi-Fi network name must not be empty")
+	}
+	const (
+		minPSKLen = 8
+		maxPSKLen = 63
+	)
+	if psk == "" && kind != "hotspot" {
+		return nil // this is for an unsecured network
+	}
+	if len(psk) < minPSKLen || len(psk) > maxPSKLen {
+		return errors.Errorf(
+			"the Wi-Fi password must be between %d and %d characters long", minPSKLen, maxPSKLen,
+		)
+	}
+	return nil
+}
+
+// Deletion
+
+func deleteConnProfile(
+	ctx context.Context, uid uuid.UUID, roles conf.RolesConfig, nmc *nm.Client,
+) error {
+	id, err := getFactoryConnProfileID(ctx, uid, roles, nmc)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf(
+			"the built-in connection profile %s can't be deleted", id,
+		))
+	}
+	if err = nmc.DeleteConnProfile(ctx, uid); err != nil {
+		return errors.Wrapf(err, "couldn't delete connection profile %s", uid)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package internet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+type ConnProfilePSKViewData struct {
+	ConnProfile nm.ConnProfile
+	PSK         string
+}
+
+func (h *Handlers) HandleConnProfilePSKPostByUUID() echo.HandlerFunc {
+	t := "internet/conn-profiles/psk.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		rawUUID := c.Param("uuid")
+		uid, err := uuid.Parse(rawUUID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unparsable UUID %s", rawUUID))
+		}
+
+		// Run queries
+		ctx := c.Request().Context()
+		vd := ConnProfilePSKViewData{}
+		if vd.ConnProfile, err = h.nmc.GetConnProfileByUUID(ctx, uid); err != nil {
+			return errors.Wrapf(err, "couldn't get connection profile %s", uid)
+		}
+		// The server isn't allowed to read Wi-Fi passwords from NetworkManager, so we get the password
+		// through the sidecar, which limits how often passwords can be revealed and records who they
+		// were revealed to:
+		if vd.PSK, err = revealWifiPSKViaSidecar(ctx, uid, c.RealIP(), h.scc, h.l); err != nil {
+			var rateErr *nmipc.RateLimited
+			if errors.As(err, &rateErr) {
+				c.Response().Header().Set(
+					echo.HeaderRetryAfter, strconv.FormatInt(rateErr.RetryAfterSec, 10),
+				)
+				return echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf(
+					"too many Wi-Fi passwords were revealed recently; try again in %d seconds",
+					rateErr.RetryAfterSec,
+				))
+			}
+			var noPSKErr *nmipc.NoWifiPSK
+			if errors.As(err, &noPSKErr) {
+				return echo.NewHTTPError(http.StatusNotFound, noPSKErr.Description)
+			}
+			return err
+		}
+
+		// Produce output
+		// Note: the page shows the Wi-Fi password, so it must not be cached anywhere; the password is
+		// only kept for rendering this response, and never stored in the client cache
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+func revealWifiPSKViaSidecar(
+	ctx context.Context, uid uuid.UUID, requester string, scc *sc.Client, l godest.Logger,
+) (psk string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if psk, err = nmipc.RevealWifiPSK().Call(ctx, conn, uid.String(), requester); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's RevealWifiPSK method")
+	}
+	return psk, nil
+}
@@ -5,21 +5,23 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
-	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sargassum-world/godest"
-	"github.com/sargassum-world/godest/handling"
 	"github.com/sargassum-world/godest/turbostreams"
 
 	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
 	uc2ipc "github.com/openUC2/machine-admin/internal/app/ipc/openuc2"
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
 	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
 	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
 	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
@@ -44,6 +46,22 @@ func (h *Handlers) HandleConnProfilesPost() echo.HandlerFunc {
 			}
 			// Redirect user
 			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		case "added":
+			formValues, err := c.FormParams()
+			if err != nil {
+				return errors.Wrap(err, "couldn't load form parameters")
+			}
+			uid, err := addConnProfile(ctx, formValues, h.roles, h.nmc)
+			if err != nil {
+				return err // we don't wrap the error, which may be an HTTP error about invalid input
+			}
+			// Redirect user
+			return c.Redirect(http.StatusSeeOther, fmt.Sprintf(
+				"%sinternet/conn-profiles/%s?mode=%s", h.r.BasePath, uid, sh.ViewModeAdvanced,
+			))
+		case "imported":
+			// We don't wrap the error, which may be an HTTP error about an invalid keyfile:
+			return h.importConnProfile(ctx, c)
 		}
 	}
 }
@@ -76,10 +94,15 @@ func (h *Handlers) HandleConnProfileGetByUUID() echo.HandlerFunc {
 
 		// Run queries
 		ctx := c.Request().Context()
-		vd, err := getConnProfileViewData(ctx, uid, h.nmc)
+		vd, err := getConnProfileViewData(ctx, uid, h.roles, h.nmc)
 		if err != nil {
 			return err
 		}
+		// Note: the snapshot is only used to explain conflicting changes, so it's fine if we can't
+		// get it
+		if snapshot, err := h.nmc.GetConnProfileSnapshot(ctx, uid); err == nil {
+			h.snapshots.add(uid, snapshot)
+		}
 
 		// Produce output
 		// Note: we don't cache this page because it's slower to serialize the data to cache than it is
@@ -91,6 +114,9 @@ func (h *Handlers) HandleConnProfileGetByUUID() echo.HandlerFunc {
 type ConnProfileViewData struct {
 	ConnProfile nm.ConnProfile
 	Active      nm.ActiveConn
+	IsFactory   bool
+	// ActiveDevices are the devices on which the connection profile is active
+	ActiveDevices []nm.Device
 
 	IsStreamPage bool
 }
@@ -98,17 +124,26 @@ type ConnProfileViewData struct {
 func getConnProfileViewData(
 	ctx context.Context,
 	uid uuid.UUID,
+	roles conf.RolesConfig,
 	nmc *nm.Client,
 ) (vd ConnProfileViewData, err error) {
 	if vd.ConnProfile, err = nmc.GetConnProfileByUUID(ctx, uid); err != nil {
 		return vd, errors.Wrapf(err, "couldn't get connection profile %s", uid)
 	}
+	vd.IsFactory = isFactoryConnProfile(roles, vd.ConnProfile.Settings.Conn.ID)
 
 	activeConns, err := nmc.ListActiveConns()
 	if err == nil { // vd.Active is the empty value if we can't determine the active conns
 		activeConn := activeConns[vd.ConnProfile.Settings.Conn.UUID.String()]
 		vd.Active = activeConn
 	}
+	for _, iface := range vd.Active.DeviceInterfaces {
+		// The addresses obtained by the devices are just shown for comparison with the configured
+		// addresses, so it's fine if we can't provide them:
+		if device, err := nmc.GetDeviceByIface(ctx, iface); err == nil {
+			vd.ActiveDevices = append(vd.ActiveDevices, device)
+		}
+	}
 
 	return vd, nil
 }
@@ -143,11 +178,11 @@ func (h *Handlers) HandleConnProfilePubByUUID() turbostreams.HandlerFunc {
 			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unparsable UUID %s", rawUUID))
 		}
 
-		// Publish periodically
-		const pubInterval = 4 * time.Second
-		return handling.RepeatImmediate(c.Context(), pubInterval, func() (done bool, err error) {
+		// Publish on changes
+		changes := h.nmc.Subscribe(c.Context())
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
 			// Run queries
-			vd, err := getConnProfileViewData(c.Context(), uid, h.nmc)
+			vd, err := getConnProfileViewData(c.Context(), uid, h.roles, h.nmc)
 			if err != nil {
 				return false, err
 			}
@@ -179,6 +214,8 @@ func (h *Handlers) HandleConnProfilePostByUUID() echo.HandlerFunc {
 		update := c.FormValue("state:updated") == rawTrue
 		updateType := c.FormValue("update-type")
 		activate := c.FormValue("state:activated") == rawTrue
+		deactivate := c.FormValue("state:deactivated") == rawTrue
+		deleted := c.FormValue("state:deleted") == rawTrue
 		redirectTarget := c.FormValue("redirect-target")
 
 		// Run queries
@@ -187,37 +224,191 @@ func (h *Handlers) HandleConnProfilePostByUUID() echo.HandlerFunc {
 		// network interface down before bringing it back up), the operation is not interrupted by
 		// context cancellation from the loss ofthe client-server connection:
 		ctx := context.Background()
-		if dropInUpdate {
-			if err := dropInUpdateConnProfileViaSidecar(
-				ctx, uid, c.FormValue("802-11-wireless-security.psk"), h.nmc, h.scc, h.l,
-			); err != nil {
-				return errors.Wrapf(err, "couldn't regenerate connection profile %s", uid.String())
+		if update || dropInUpdate || deleted {
+			// We check for conflicting changes before making any changes, so that the user doesn't
+			// silently overwrite someone else's changes or have to roll back a partial change. Every form
+			// which edits a connection profile must send the version which it shows.
+			version := c.FormValue("version")
+			if version == "" {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+					"missing version of connection profile %s", uid,
+				))
 			}
-		}
-		if regenerate {
-			if err := regenerateConnProfileViaSidecar(ctx, uid, h.nmc, h.scc, h.l); err != nil {
-				return errors.Wrapf(err, "couldn't regenerate connection profile %s", uid.String())
+			current, err := h.nmc.GetConnProfileSnapshot(ctx, uid)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't get settings of connection profile %s", uid)
 			}
-		}
-		if reload {
-			if err := reloadConnProfileViaSidecar(ctx, uid, h.scc, h.l); err != nil {
-				return errors.Wrapf(err, "couldn't reload connection profile %s", uid.String())
+			if current.Version != version {
+				return h.renderConnProfileConflict(c, uid, version, current, formValues)
 			}
 		}
+		if deleted {
+			return h.deleteConnProfile(ctx, c, uid, redirectTarget)
+		}
+		var updateValues map[nm.ConnProfileSettingsKey]any
 		if update {
-			if err := updateConnProfile(ctx, uid, updateType, formValues, h.nmc); err != nil {
-				return errors.Wrapf(err, "couldn't update connection profile %s", uid.String())
+			// We don't wrap the error, which may be an HTTP error about a forbidden rename:
+			if err := checkFactoryConnProfileRename(ctx, uid, formValues, h.roles, h.nmc); err != nil {
+				return err
+			}
+			// Note: a rollback doesn't revert stored files either, but they're only used by the
+			// connection profile once it's updated to refer to them
+			if err := storeConnProfileCertsViaSidecar(
+				ctx, c, uid, formValues, h.scc, h.l,
+			); err != nil {
+				return err
+			}
+			// We parse and check the update before making any changes, so that invalid input is reported
+			// without creating a checkpoint. We don't wrap the error, which may be an HTTP error about
+			// invalid input:
+			if updateType, updateValues, err = parseConnProfileUpdate(
+				uid, updateType, formValues,
+			); err != nil {
+				return err
 			}
 		}
-		if activate {
-			if err := h.nmc.ActivateConnProfile(ctx, uid); err != nil {
-				return errors.Wrapf(err, "couldn't activate connection profile %s", uid.String())
+		change := func() error {
+			if dropInUpdate {
+				if err := dropInUpdateConnProfileViaSidecar(
+					ctx, uid, c.FormValue("802-11-wireless-security.psk"), h.nmc, h.scc, h.l,
+				); err != nil {
+					return errors.Wrapf(err, "couldn't regenerate connection profile %s", uid.String())
+				}
+			}
+			if regenerate {
+				if err := regenerateConnProfileViaSidecar(ctx, uid, h.nmc, h.scc, h.l); err != nil {
+					return errors.Wrapf(err, "couldn't regenerate connection profile %s", uid.String())
+				}
+			}
+			if reload {
+				if err := reloadConnProfileViaSidecar(ctx, uid, h.scc, h.l); err != nil {
+					return errors.Wrapf(err, "couldn't reload connection profile %s", uid.String())
+				}
+			}
+			if update {
+				// TODO: if the conn profile is generated from drop-in files and the updateType is safe,
+				// then also use the sidecar to modify the drop-in files appropriately
+				// We don't wrap the error, which may be an error about a conflicting change:
+				if err := h.nmc.UpdateConnProfileByUUID(
+					ctx, uid, updateType, c.FormValue("version"), updateValues,
+				); err != nil {
+					return err
+				}
 			}
+			if activate {
+				if err := h.nmc.ActivateConnProfile(ctx, uid); err != nil {
+					return errors.Wrapf(err, "couldn't activate connection profile %s", uid.String())
+				}
+			}
+			if deactivate {
+				if err := h.nmc.DeactivateConnProfile(ctx, uid); err != nil {
+					return errors.Wrapf(err, "couldn't deactivate connection profile %s", uid.String())
+				}
+			}
+			return nil
+		}
+		// Activating or deactivating the connection profile, or changing how an active connection
+		// profile connects, might disconnect the user from the device (e.g. if they're reaching it
+		// through a VPN tunnel), so we only keep such changes if the user confirms that they can still
+		// reach the device. A rollback also restores any drop-in files from which the connection
+		// profile is assembled.
+		checkpointed := activate || deactivate
+		if update && !checkpointed {
+			if checkpointed, err = updateAffectsConnectivity(ctx, uid, updateValues, h.nmc); err != nil {
+				return err
+			}
+		}
+		checkpointID := ""
+		if checkpointed {
+			checkpointID, err = checkpointedChange(ctx, change, h.scc, h.l)
+		} else {
+			err = change()
+		}
+		if errors.Is(err, nm.ErrConnProfileChanged) {
+			// Someone else changed the connection profile just after we checked for conflicts
+			current, serr := h.nmc.GetConnProfileSnapshot(ctx, uid)
+			if serr != nil {
+				return errors.Wrapf(serr, "couldn't get settings of connection profile %s", uid)
+			}
+			return h.renderConnProfileConflict(c, uid, c.FormValue("version"), current, formValues)
+		}
+		if err != nil {
+			return err
 		}
 
+		// Redirect user
+		if !checkpointed {
+			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		}
+		return c.Redirect(
+			http.StatusSeeOther, checkpointPath(h.r.BasePath, checkpointID, redirectTarget),
+		)
+	}
+}
+
+// cosmeticConnProfileSettings are the settings which can be changed without affecting the network
+// connection of an active connection profile.
+var cosmeticConnProfileSettings = map[string]bool{
+	"connection.id":                   true,
+	"connection.autoconnect":          true,
+	"connection.autoconnect-priority": true,
+}
+
+// updateAffectsConnectivity checks whether updating the connection profile with the new settings
+// might change the machine's network connections, and thus disconnect the user from the machine.
+func updateAffectsConnectivity(
+	ctx context.Context, uid uuid.UUID, updateValues map[nm.ConnProfileSettingsKey]any,
+	nmc *nm.Client,
+) (bool, error) {
+	changed, err := nmc.ListChangedConnProfileSettings(ctx, uid, updateValues)
+	if err != nil {
+		return false, errors.Wrapf(err, "couldn't compare settings of connection profile %s", uid)
+	}
+	activeConns, err := nmc.ListActiveConns()
+	if err != nil {
+		return false, errors.Wrap(err, "couldn't list active connections")
+	}
+	if _, active := activeConns[uid.String()]; !active {
+		// NetworkManager may activate an inactive connection profile as soon as autoconnect is
+		// enabled, which may replace another connection on the same device:
+		return slices.Contains(changed, "connection.autoconnect"), nil
+	}
+	return slices.ContainsFunc(changed, func(key string) bool {
+		return !cosmeticConnProfileSettings[key]
+	}), nil
+}
+
+func (h *Handlers) deleteConnProfile(
+	ctx context.Context, c echo.Context, uid uuid.UUID, redirectTarget string,
+) error {
+	activeConns, err := h.nmc.ListActiveConns()
+	if err != nil {
+		return errors.Wrap(err, "couldn't list active connections")
+	}
+	change := func() error {
+		// We don't wrap the error, which may be an HTTP error about a forbidden deletion:
+		return deleteConnProfile(ctx, uid, h.roles, h.nmc)
+	}
+	if _, active := activeConns[uid.String()]; !active {
+		if err := change(); err != nil {
+			return err
+		}
+		if err := deleteCertificatesViaSidecar(ctx, uid, h.scc, h.l); err != nil {
+			h.l.Error(errors.Wrapf(err, "couldn't delete certificates of connection profile %s", uid))
+		}
 		// Redirect user
 		return c.Redirect(http.StatusSeeOther, redirectTarget)
 	}
+	// Deleting an active connection profile deactivates it, which might disconnect the user from the
+	// device. We keep its certificate files in case the deletion is rolled back:
+	checkpointID, err := checkpointedChange(ctx, change, h.scc, h.l)
+	if err != nil {
+		return err
+	}
+	// Redirect user
+	return c.Redirect(
+		http.StatusSeeOther, checkpointPath(h.r.BasePath, checkpointID, redirectTarget),
+	)
 }
 
 func dropInUpdateConnProfileViaSidecar(
@@ -276,15 +467,18 @@ func reloadConnProfileViaSidecar(
 	return nil
 }
 
-func updateConnProfile(
-	ctx context.Context, uid uuid.UUID, updateType string, formValues url.Values,
-	nmc *nm.Client,
-) error {
-	updateValues := make(map[nm.ConnProfileSettingsKey]any)
+// parseConnProfileUpdate parses and checks the form's update to the connection profile, and returns
+// the type of update (as expected by [nm.Client.UpdateConnProfileByUUID]) with the new settings.
+func parseConnProfileUpdate(
+	uid uuid.UUID, rawUpdateType string, formValues url.Values,
+) (updateType string, updateValues map[nm.ConnProfileSettingsKey]any, err error) {
+	updateValues = make(map[nm.ConnProfileSettingsKey]any)
 
-	switch strings.ToLower(updateType) {
+	switch strings.ToLower(rawUpdateType) {
 	default:
-		return errors.Errorf("unknown update type: %s", updateType)
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"unknown update type %s", rawUpdateType,
+		))
 	case "apply temporarily":
 		updateType = "apply"
 	case "save and apply":
@@ -300,11 +494,13 @@ func updateConnProfile(
 			continue
 		}
 		if updateValues[key], err = parseConnProfileSettingsField(key, rawValues); err != nil {
-			return errors.Wrapf(err, "couldn't parse (key, value) pair: (%s, %+v)", key, rawValues)
+			return "", nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"couldn't parse (key, value) pair: (%s, %+v): %s", key, rawValues, err,
+			))
 		}
 	}
-	wifiSecKeyMgmt := formValues["802-11-wireless-security.key-mgmt"][0]
-	wifiSecPSK := formValues["802-11-wireless-security.psk"][0]
+	wifiSecKeyMgmt := formValues.Get("802-11-wireless-security.key-mgmt")
+	wifiSecPSK := formValues.Get("802-11-wireless-security.psk")
 	if wifiSecPSK == "" && wifiSecKeyMgmt != "none" && wifiSecKeyMgmt != "owe" {
 		// in key-mgmt modes requiring a PSK, don't overwrite the existing PSK with the submitted value,
 		// which may be left empty in the form submission as a signal to keep the existing PSK:
@@ -312,12 +508,23 @@ func updateConnProfile(
 			Section: "802-11-wireless-security", Key: "psk",
 		})
 	}
+	filter8021xUpdate(updateValues, formValues)
+	filterGSMUpdate(updateValues)
 	if err := checkConnProfile(formValues); err != nil {
-		return err
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
-	// TODO: if the conn profile is generated from drop-in files and the updateType is safe, then also
-	// use the sidecar to modify the drop-in files appropriately
-	return nmc.UpdateConnProfileByUUID(ctx, uid, updateType, updateValues)
+	if err := check8021x(uid, updateValues); err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err := checkConnProfileEthernet(updateValues); err != nil {
+		return "", nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	for _, section := range []string{"ipv4", "ipv6"} {
+		if err := checkConnProfileIP(updateValues, section); err != nil {
+			return "", nil, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	return updateType, updateValues, nil
 }
 
 func parseConnProfileSettingsField(
@@ -334,8 +541,16 @@ func parseConnProfileSettingsField(
 		return parseConnProfileSettingsWifiSecField(
 			key, rawValues,
 		)
+	case "802-1x":
+		return parseConnProfileSettings8021xField(key, rawValues)
+	case "802-3-ethernet":
+		return parseConnProfileSettingsEthernetField(key, rawValues)
+	case "gsm":
+		return parseConnProfileSettingsGSMField(key, rawValues)
 	case "ipv4":
 		return parseConnProfileSettingsIPv4Field(key, rawValues)
+	case "ipv6":
+		return parseConnProfileSettingsIPv6Field(key, rawValues)
 	}
 }
 
@@ -353,14 +568,22 @@ func parseConnProfileSettingsConnField(
 		}
 		return autoconnect, nil
 	case "autoconnect-priority":
-		value, err := strconv.Atoi(rawValue)
+		// Note: NetworkManager requires the priority to be an int32
+		value, err := strconv.ParseInt(rawValue, 10, 32)
 		if err != nil {
 			return nil, errors.Wrapf(err, "couldn't parse %s as integer", rawValue)
 		}
 		if value < -999 || value > 999 {
 			return nil, errors.Errorf("autoconnect priority %d out of range [-999, 999]", value)
 		}
-		return value, nil
+		return int32(value), nil
+	case "id":
+		if rawValue == "" {
+			return nil, errors.New("connection profile name must not be empty")
+		}
+		return rawValue, nil
+	case "interface-name":
+		return rawValue, nil
 	case "zone":
 		return rawValue, nil
 	}
@@ -386,6 +609,8 @@ func parseConnProfileSettingsWifiField(
 	switch key.Key {
 	default:
 		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+	case "assigned-mac-address":
+		return parseAssignedMACAddress(rawValue)
 	case "band":
 		band := nm.ConnProfileSettingsWifiBand(rawValue)
 		if info := band.Info(); info.Level == nm.EnumInfoLevelError {
@@ -393,14 +618,11 @@ func parseConnProfileSettingsWifiField(
 		}
 		return band, nil
 	case "channel":
-		value, err := strconv.Atoi(rawValue)
+		value, err := strconv.ParseUint(rawValue, 10, 32)
 		if err != nil {
-			return nil, errors.Wrapf(err, "couldn't parse %s as integer", rawValue)
-		}
-		if value < -999 || value > 999 {
-			return nil, errors.Errorf("autoconnect priority %d out of range [-999, 999]", value)
+			return nil, errors.Wrapf(err, "couldn't parse %s as a channel number", rawValue)
 		}
-		return value, nil
+		return uint32(value), nil
 	case "hidden":
 		hidden, err := parseCheckbox(rawValue, "true", "false")
 		if err != nil {
@@ -413,6 +635,8 @@ func parseConnProfileSettingsWifiField(
 			return nil, errors.New(info.Details)
 		}
 		return mode, nil
+	case "mtu":
+		return parseMTU(rawValue)
 	case "ssid":
 		for _, rawValue = range rawValues { // select the first non-blank value, not the last value
 			if rawValue != "" {
@@ -488,7 +712,7 @@ func parseConnProfileSettingsIPv4Field(
 	rawValue := rawValues[len(rawValues)-1] // selects the last value to account for single checkboxes
 	switch key.Key {
 	default:
-		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+		return parseConnProfileSettingsIPRoutingField(key, rawValue)
 	case "dhcp-timeout":
 		value, err := strconv.Atoi(rawValue)
 		if err != nil {
@@ -519,6 +743,236 @@ func parseConnProfileSettingsIPv4Field(
 	}
 }
 
+// splitList splits a list of values separated by commas, whitespace, or newlines.
+func splitList(rawValue string) []string {
+	return strings.FieldsFunc(rawValue, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+func parseConnProfileSettingsIPv6Field(
+	key nm.ConnProfileSettingsKey, rawValues []string,
+) (parsedValue any, err error) {
+	rawValue := rawValues[len(rawValues)-1] // selects the last value to account for single checkboxes
+	switch key.Key {
+	default:
+		return parseConnProfileSettingsIPRoutingField(key, rawValue)
+	case "addr-gen-mode":
+		mode := nm.NewConnProfileSettingsIPv6AddrGenMode(rawValue)
+		if info := mode.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return mode, nil
+	case "ip6-privacy":
+		privacy := nm.NewConnProfileSettingsIPv6Privacy(rawValue)
+		if info := privacy.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return privacy, nil
+	case "may-fail":
+		mayFail, err := parseCheckbox(rawValue, "optional", "required")
+		if err != nil {
+			return false, errors.Wrapf(err, "couldn't parse value for %s", key)
+		}
+		return mayFail, nil
+	case "method":
+		method := nm.ConnProfileSettingsIPv6Method(rawValue)
+		if info := method.Info(); info.Level == nm.EnumInfoLevelError {
+			return nil, errors.New(info.Details)
+		}
+		return method, nil
+	}
+}
+
+// ipSection describes how addresses are written in the ipv4 or ipv6 settings section.
+type ipSection struct {
+	name           string
+	exampleAddress string
+	exampleRoute   string
+}
+
+var ipSections = map[string]ipSection{
+	"ipv4": {
+		name:           "IPv4",
+		exampleAddress: "192.168.1.10/24",
+		exampleRoute:   "10.0.0.0/8 192.168.1.1 100",
+	},
+	"ipv6": {
+		name:           "IPv6",
+		exampleAddress: "fd00::10/64",
+		exampleRoute:   "fd01::/64 fd00::1 100",
+	},
+}
+
+// hasVersion checks whether the address is of the IP version of the settings section.
+func (s ipSection) hasVersion(addr netip.Addr) bool {
+	if s.name == "IPv6" {
+		// Note: NetworkManager rejects IPv6 addresses with zones (e.g. fe80::1%eth0), since link-local
+		// addresses in a connection profile are always scoped to the profile's own interface
+		return addr.Is6() && !addr.Is4In6() && addr.Zone() == ""
+	}
+	return addr.Is4()
+}
+
+// parseConnProfileSettingsIPRoutingField parses the fields which the ipv4 and ipv6 settings
+// sections have in common.
+func parseConnProfileSettingsIPRoutingField(
+	key nm.ConnProfileSettingsKey, rawValue string,
+) (parsedValue any, err error) {
+	section := ipSections[key.Section]
+	switch key.Key {
+	default:
+		return nil, errors.Errorf("unimplemented or unknown key %s", key)
+	case "address-data":
+		addresses := make([]nm.IPAddress, 0)
+		for _, rawAddress := range splitList(rawValue) {
+			prefix, err := netip.ParsePrefix(rawAddress)
+			if err != nil {
+				return nil, errors.Errorf(
+					"couldn't parse address %s, which must be written like %s",
+					rawAddress, section.exampleAddress,
+				)
+			}
+			if !section.hasVersion(prefix.Addr()) {
+				return nil, errors.Errorf("%s is not an %s address", rawAddress, section.name)
+			}
+			addresses = append(addresses, nm.IPAddress{Prefix: prefix})
+		}
+		return addresses, nil
+	case "gateway":
+		if rawValue == "" {
+			return netip.Addr{}, nil
+		}
+		gateway, err := netip.ParseAddr(rawValue)
+		if err != nil || !section.hasVersion(gateway) {
+			return nil, errors.Errorf("gateway %s is not an %s address", rawValue, section.name)
+		}
+		return gateway, nil
+	case "dns":
+		servers := make([]netip.Addr, 0)
+		for _, rawServer := range splitList(rawValue) {
+			server, err := netip.ParseAddr(rawServer)
+			if err != nil || !section.hasVersion(server) {
+				return nil, errors.Errorf(
+					"DNS server %s is not an %s address", rawServer, section.name,
+				)
+			}
+			servers = append(servers, server)
+		}
+		return servers, nil
+	case "dns-search":
+		return splitList(rawValue), nil
+	case "ignore-auto-dns":
+		ignoreAutoDNS, err := parseCheckbox(rawValue, "true", "false")
+		if err != nil {
+			return false, errors.Wrapf(err, "couldn't parse value for %s", key)
+		}
+		return ignoreAutoDNS, nil
+	case "route-data":
+		routes := make([]nm.IPRoute, 0)
+		for rawRoute := range strings.Lines(rawValue) {
+			if strings.TrimSpace(rawRoute) == "" {
+				continue
+			}
+			route, err := parseIPRoute(rawRoute, section)
+			if err != nil {
+				return nil, err
+			}
+			routes = append(routes, route)
+		}
+		return routes, nil
+	case "route-metric":
+		value, err := strconv.ParseInt(rawValue, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s as integer", rawValue)
+		}
+		if value < -1 || value > math.MaxUint32 {
+			return nil, errors.Errorf(
+				"route metric %d out of range [-1, %d]", value, uint32(math.MaxUint32),
+			)
+		}
+		return value, nil
+	}
+}
+
+// parseIPRoute parses a route written as the destination, optionally followed by the next hop
+// and/or the metric, e.g. "10.0.0.0/8 192.168.1.1 100" or "10.0.0.0/8 100".
+func parseIPRoute(rawRoute string, section ipSection) (route nm.IPRoute, err error) {
+	fields := strings.Fields(rawRoute)
+	const maxFields = 3
+	if len(fields) > maxFields {
+		return nm.IPRoute{}, errors.Errorf(
+			"route %s must be written like %s", strings.TrimSpace(rawRoute), section.exampleRoute,
+		)
+	}
+	route.Destination, err = netip.ParsePrefix(fields[0])
+	if err != nil || !section.hasVersion(route.Destination.Addr()) {
+		return nm.IPRoute{}, errors.Errorf(
+			"route destination %s is not an %s subnet", fields[0], section.name,
+		)
+	}
+	fields = fields[1:]
+	if len(fields) > 0 {
+		// Metrics never parse as addresses, so anything which does parse must be the next hop
+		if nextHop, err := netip.ParseAddr(fields[0]); err == nil {
+			if !section.hasVersion(nextHop) {
+				return nm.IPRoute{}, errors.Errorf(
+					"route next hop %s is not an %s address", fields[0], section.name,
+				)
+			}
+			route.NextHop = nextHop
+			fields = fields[1:]
+		}
+	}
+	switch len(fields) {
+	case 0:
+	case 1:
+		metric, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nm.IPRoute{}, errors.Errorf("route metric %s is not a non-negative integer", fields[0])
+		}
+		route.Metric = uint32(metric)
+		route.HasMetric = true
+	default:
+		return nm.IPRoute{}, errors.Errorf(
+			"route %s must be written like %s", strings.TrimSpace(rawRoute), section.exampleRoute,
+		)
+	}
+	return route, nil
+}
+
+// checkConnProfileIP checks the consistency of the IPv4 or IPv6 settings which would result from
+// the update.
+func checkConnProfileIP(updateValues map[nm.ConnProfileSettingsKey]any, sectionName string) error {
+	key := nm.NewConnProfileSettingsKey
+	section := ipSections[sectionName]
+	method := fmt.Sprint(updateValues[key(sectionName, "method")])
+	addresses, _ := updateValues[key(sectionName, "address-data")].([]nm.IPAddress)
+	gateway, _ := updateValues[key(sectionName, "gateway")].(netip.Addr)
+	if method == "manual" && len(addresses) == 0 {
+		return errors.Errorf("manual %s configuration requires at least one address", section.name)
+	}
+	if !gateway.IsValid() {
+		return nil
+	}
+	if len(addresses) == 0 {
+		return errors.Errorf(
+			"an %s gateway can only be set together with an %s address", section.name, section.name,
+		)
+	}
+	if gateway.Is6() && gateway.IsLinkLocalUnicast() {
+		return nil // IPv6 routers are commonly addressed by their link-local addresses
+	}
+	for _, address := range addresses {
+		if address.Prefix.Masked().Contains(gateway) {
+			return nil
+		}
+	}
+	return errors.Errorf(
+		"gateway %s is not in the subnet of any %s address", gateway, section.name,
+	)
+}
+
 func checkConnProfile(formValues url.Values) error {
 	if rawWifiChannel, ok := formValues["802-11-wireless.channel"]; ok {
 		wifiChannel := rawWifiChannel[0]
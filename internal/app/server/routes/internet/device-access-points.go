@@ -8,7 +8,6 @@ import (
 	"maps"
 	"net/http"
 	"slices"
-	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
@@ -108,13 +107,19 @@ func (h *Handlers) HandleDeviceAPsPubByIface() turbostreams.HandlerFunc {
 			return errors.Wrap(err, "couldn't get query param 'mode'")
 		}
 
-		// Publish periodically
-		const pubInterval = 4 * time.Second
-		return handling.RepeatImmediate(c.Context(), pubInterval, func() (done bool, err error) {
-			// Run queries
-			if err := h.nmc.RescanNetworks(c.Context(), iface); err != nil {
-				return false, err
+		// Keep the list of available Wi-Fi networks fresh
+		go func() {
+			if err := handling.Except(
+				rescanPeriodically(c.Context(), h.nmc, iface), context.Canceled,
+			); err != nil {
+				h.l.Error(errors.Wrapf(err, "couldn't rescan for Wi-Fi networks on %s", iface))
 			}
+		}()
+
+		// Publish on changes
+		changes := h.nmc.Subscribe(c.Context())
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
 			vd, err := getDeviceAPsViewData(c.Context(), iface, h.nmc)
 			if err != nil {
 				return false, err
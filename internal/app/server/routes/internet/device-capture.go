@@ -0,0 +1,126 @@
+package internet
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	"github.com/varlink/go/varlink"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// HandleDeviceCapturePostByIface starts a packet capture, which is only allowed for POST requests
+// so that other sites can't make users' browsers start privileged packet captures (e.g. through
+// links or images).
+func (h *Handlers) HandleDeviceCapturePostByIface() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+		duration, err := strconv.ParseInt(c.FormValue("duration"), 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"unparseable capture duration %s", c.FormValue("duration"),
+			))
+		}
+		const megabyte = 1024 * 1024
+		maxSize, err := strconv.ParseInt(c.FormValue("max-size"), 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"unparseable capture size %s", c.FormValue("max-size"),
+			))
+		}
+		filter := c.FormValue("filter")
+
+		// Run queries
+		// Note: the request's context is canceled when the user's browser disconnects, which stops the
+		// capture
+		ctx := c.Request().Context()
+		if _, err = h.nmc.GetDeviceByIface(ctx, iface); err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("unknown device %s", iface))
+		}
+
+		// Produce output
+		filename := fmt.Sprintf("%s-%s", iface, time.Now().UTC().Format("20060102T150405Z"))
+		if filter != "" {
+			filename += "-" + filter
+		}
+		filename += ".pcap"
+		started := false
+		if err = capturePacketsViaSidecar(
+			ctx, iface, duration, maxSize*megabyte, filter, func(chunk []byte) error {
+				if !started {
+					c.Response().Header().Set(echo.HeaderContentType, "application/vnd.tcpdump.pcap")
+					c.Response().Header().Set(
+						echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename),
+					)
+					c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+					c.Response().WriteHeader(http.StatusOK)
+					started = true
+				}
+				if len(chunk) == 0 { // the sidecar sends empty chunks while no packets are captured
+					return nil
+				}
+				if _, err := c.Response().Write(chunk); err != nil {
+					return err
+				}
+				// We flush each chunk so that the download shows the capture's progress
+				c.Response().Flush()
+				return nil
+			}, h.scc, h.l,
+		); err != nil {
+			if started {
+				// We can't report the error in the response anymore, since it's already being sent
+				h.l.Error(err)
+				return nil
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func capturePacketsViaSidecar(
+	ctx context.Context, iface string, durationSec, maxBytes int64, filter string,
+	handleChunk func(chunk []byte) error, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	receive, err := nmipc.CapturePackets().Send(
+		ctx, conn, varlink.More, iface, durationSec, maxBytes, filter,
+	)
+	if err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's CapturePackets method")
+	}
+	for {
+		rawChunk, flags, err := receive(ctx)
+		if err != nil {
+			var invalidErr *nmipc.InvalidCaptureOptions
+			if errors.As(err, &invalidErr) {
+				return echo.NewHTTPError(http.StatusBadRequest, invalidErr.Description)
+			}
+			return errors.Wrap(err, "couldn't receive captured packets from sidecar")
+		}
+		chunk, err := base64.StdEncoding.DecodeString(rawChunk)
+		if err != nil {
+			return errors.Wrap(err, "couldn't decode captured packets from sidecar")
+		}
+		if err = handleChunk(chunk); err != nil {
+			return errors.Wrap(err, "couldn't forward captured packets")
+		}
+		if flags&varlink.Continues == 0 {
+			return nil
+		}
+	}
+}
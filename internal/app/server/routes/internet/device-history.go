@@ -0,0 +1,71 @@
+package internet
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sargassum-world/godest/turbostreams"
+
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+func (h *Handlers) HandleDeviceHistoryGetByIface() echo.HandlerFunc {
+	t := "internet/devices/history/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+
+		// Run queries
+		vd := getDeviceHistoryViewData(c.Request().Context(), iface, h.nmc)
+
+		// Produce output
+		// Note: we don't cache this page because the history changes whenever the device's state
+		// changes
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+type DeviceHistoryViewData struct {
+	Interface string
+	// Device is the empty value if the device no longer exists
+	Device      nm.Device
+	Transitions []nm.StateTransition
+
+	IsStreamPage bool
+}
+
+func getDeviceHistoryViewData(
+	ctx context.Context, iface string, nmc *nm.Client,
+) (vd DeviceHistoryViewData) {
+	vd.Interface = iface
+	// Note: the history is most useful for devices which have problems, including devices which
+	// were removed (e.g. USB modems which were unplugged), so the page shouldn't fail if the device
+	// can't be found
+	if device, err := nmc.GetDeviceByIface(ctx, iface); err == nil {
+		vd.Device = device
+	}
+	vd.Transitions = nmc.GetStateHistory(iface)
+	return vd
+}
+
+func (h *Handlers) HandleDeviceHistoryPubByIface() turbostreams.HandlerFunc {
+	t := "internet/devices/history/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c *turbostreams.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+
+		// Publish on changes
+		changes := h.nmc.Subscribe(c.Context())
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
+			vd := getDeviceHistoryViewData(c.Context(), iface, h.nmc)
+			// Produce output
+			vd.IsStreamPage = true
+			return false, sh.PublishPageReload(c, h.r, t, vd)
+		})
+	}
+}
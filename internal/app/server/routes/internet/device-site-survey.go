@@ -0,0 +1,196 @@
+package internet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	"github.com/sargassum-world/godest/handling"
+	"github.com/sargassum-world/godest/turbostreams"
+
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+	"github.com/openUC2/machine-admin/internal/clients/sitesurvey"
+	"github.com/openUC2/machine-admin/internal/clients/wifireg"
+)
+
+func (h *Handlers) HandleDeviceSiteSurveyGetByIface() echo.HandlerFunc {
+	t := "internet/devices/site-survey/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+
+		// Run queries
+		vd, err := getDeviceSiteSurveyViewData(
+			c.Request().Context(), iface, h.roles, h.nmc, h.scc, h.l,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Produce output
+		return h.r.CacheablePage(c.Response(), c.Request(), t, vd, struct{}{})
+	}
+}
+
+type DeviceSiteSurveyViewData struct {
+	Interface string
+	Survey    sitesurvey.Survey
+	// RecommendationsErr explains why no channels could be recommended for the hotspot, if none could
+	RecommendationsErr error
+	// Hotspot is the empty value if the hotspot's connection profile couldn't be found
+	Hotspot nm.ConnProfileSettingsWifi
+
+	IsStreamPage bool
+}
+
+func getDeviceSiteSurveyViewData(
+	ctx context.Context, iface string, roles conf.RolesConfig,
+	nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) (vd DeviceSiteSurveyViewData, err error) {
+	vd.Interface = iface
+	// Note: the survey is still useful if no channels can be recommended for the hotspot (e.g. if
+	// the Wi-Fi country can't be determined), so the page should explain the error instead of failing
+	var candidates []wifireg.Channel
+	candidates, vd.Hotspot, vd.RecommendationsErr = getHotspotCandidateChannels(
+		ctx, roles, nmc, scc, l,
+	)
+	if vd.Survey, err = makeSiteSurvey(ctx, iface, candidates, nmc); err != nil {
+		return vd, err
+	}
+	return vd, nil
+}
+
+// getHotspotCandidateChannels returns the channels which the hotspot is allowed to use, as well as
+// the hotspot's current Wi-Fi settings.
+func getHotspotCandidateChannels(
+	ctx context.Context, roles conf.RolesConfig, nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) (candidates []wifireg.Channel, hotspot nm.ConnProfileSettingsWifi, err error) {
+	vd := InternetViewData{}
+	if err = collectDevices(ctx, roles, nmc, &vd); err != nil {
+		return nil, hotspot, err
+	}
+	if err = collectConnProfiles(ctx, nmc, &vd); err != nil {
+		return nil, hotspot, err
+	}
+	hotspot = vd.HotspotConnProfile.Settings.Wifi
+	domain, err := getWifiRegDomainViaSidecar(ctx, scc, l)
+	if err != nil {
+		return nil, hotspot, errors.Wrap(err, "couldn't get Wi-Fi regulatory domain")
+	}
+	caps := vd.HotspotDevice.Wifi.Caps
+	return domain.APChannels(caps.Supports2GHz(), caps.Supports5GHz()), hotspot, nil
+}
+
+// makeSiteSurvey analyzes the latest scan results of the Wi-Fi device, ignoring the access points
+// made by the machine's own Wi-Fi devices (e.g. the hotspot).
+func makeSiteSurvey(
+	ctx context.Context, iface string, candidates []wifireg.Channel, nmc *nm.Client,
+) (survey sitesurvey.Survey, err error) {
+	networks, err := nmc.ScanNetworks(ctx, iface)
+	if err != nil {
+		return survey, errors.Wrap(err, "couldn't scan for Wi-Fi networks")
+	}
+	devices, err := nmc.GetDevices(ctx)
+	if err != nil {
+		return survey, errors.Wrap(err, "couldn't list network devices")
+	}
+	ownBSSIDs := make(map[string]bool)
+	for _, device := range devices {
+		if device.Type.Info().Short == "wifi" && device.HardwareAddress != "" {
+			ownBSSIDs[strings.ToLower(device.HardwareAddress)] = true
+		}
+	}
+	others := make(map[string][]nm.AccessPoint, len(networks))
+	for ssid, aps := range networks {
+		for _, ap := range aps {
+			if !ownBSSIDs[strings.ToLower(ap.BSSID)] {
+				others[ssid] = append(others[ssid], ap)
+			}
+		}
+	}
+	return sitesurvey.New(iface, time.Now(), others, candidates), nil
+}
+
+func (h *Handlers) HandleDeviceSiteSurveyPubByIface() turbostreams.HandlerFunc {
+	t := "internet/devices/site-survey/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c *turbostreams.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+
+		// Keep the scan results fresh
+		go func() {
+			if err := handling.Except(
+				rescanPeriodically(c.Context(), h.nmc, iface), context.Canceled,
+			); err != nil {
+				h.l.Error(errors.Wrapf(err, "couldn't rescan for Wi-Fi networks on %s", iface))
+			}
+		}()
+
+		// Publish on changes
+		changes := h.nmc.Subscribe(c.Context())
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
+			vd, err := getDeviceSiteSurveyViewData(
+				c.Context(), iface, h.roles, h.nmc, h.scc, h.l,
+			)
+			if err != nil {
+				return false, err
+			}
+			// Produce output
+			vd.IsStreamPage = true
+			return false, sh.PublishPageReload(c, h.r, t, vd)
+		})
+	}
+}
+
+func (h *Handlers) HandleDeviceSiteSurveyExportByIface(format string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+
+		// Run queries
+		ctx := c.Request().Context()
+		// Note: the export should still be useful without recommendations for the hotspot
+		candidates, _, err := getHotspotCandidateChannels(ctx, h.roles, h.nmc, h.scc, h.l)
+		if err != nil {
+			h.l.Warn(errors.Wrap(err, "couldn't determine candidate channels for the hotspot"))
+		}
+		survey, err := makeSiteSurvey(ctx, iface, candidates, h.nmc)
+		if err != nil {
+			return err
+		}
+
+		// Produce output
+		filename := fmt.Sprintf(
+			"site-survey-%s-%s.%s", iface, survey.Time.UTC().Format("20060102T150405Z"), format,
+		)
+		c.Response().Header().Set(
+			echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename),
+		)
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		switch format {
+		default:
+			return errors.Errorf("unknown site survey format %s", format)
+		case "csv":
+			var b bytes.Buffer
+			if err := survey.WriteCSV(&b); err != nil {
+				return errors.Wrap(err, "couldn't export site survey as CSV")
+			}
+			return c.Blob(http.StatusOK, "text/csv; charset=utf-8", b.Bytes())
+		case "json":
+			const indent = "  "
+			return c.JSONPretty(http.StatusOK, survey, indent)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/clients/diagnostics"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// makeDiagnosticsChecks makes the steps of a diagnostics run for the device, in the order in which
+// they should be run. Each step re-queries NetworkManager, since the device's state may change
+// while the run is in progress.
+// Note: DNS and HTTP requests are routed like any other traffic from the machine, so they might
+// not go through the device being checked if another device provides the default route.
+func (h *Handlers) makeDiagnosticsChecks(
+	nmState nm.NetworkManager, device nm.Device,
+) []diagnostics.Check {
+	iface := cmp.Or(device.IpInterface, device.ControlInterface)
+	checks := []diagnostics.Check{
+		{
+			Name:     "Link",
+			Required: true,
+			Run: func(ctx context.Context) diagnostics.Result {
+				return h.checkLink(ctx, iface)
+			},
+		},
+		{
+			Name:     "IPv4 address",
+			Required: true,
+			Run: func(ctx context.Context) diagnostics.Result {
+				return h.checkIPv4Address(ctx, iface)
+			},
+		},
+		{
+			Name:     "Default route",
+			Required: true,
+			Run: func(ctx context.Context) diagnostics.Result {
+				return h.checkDefaultRoute(ctx, iface)
+			},
+		},
+		{
+			Name: "Gateway reachability",
+			Run: func(ctx context.Context) diagnostics.Result {
+				return checkGatewayReachability(ctx, iface, h.scc, h.l)
+			},
+		},
+	}
+
+	// DNS
+	lookupHost := "nmcheck.gnome.org"
+	if u, err := url.Parse(nmState.ConnectivityCheckURI); err == nil && u.Hostname() != "" {
+		lookupHost = u.Hostname()
+	} else if len(h.dc.Config.Endpoints) > 0 {
+		lookupHost = h.dc.Config.Endpoints[0].Hostname()
+	}
+	nameservers := slices.Concat(
+		device.IPv4Config.DNS.Nameservers, device.IPv6Config.DNS.Nameservers,
+	)
+	if len(nameservers) == 0 {
+		checks = append(checks, diagnostics.Check{
+			Name: "DNS servers",
+			Run: func(context.Context) diagnostics.Result {
+				return diagnostics.Failed([]string{
+					"Check that the router's DHCP server provides DNS servers.",
+					"Set DNS servers manually in the advanced view of the connection profile, e.g. " +
+						"1.1.1.1 and 9.9.9.9.",
+				}, "%s has no DNS servers, so the machine can't look up any websites", iface)
+			},
+		})
+	}
+	for _, nameserver := range nameservers {
+		checks = append(checks, diagnostics.Check{
+			Name: fmt.Sprintf("DNS server %s", nameserver),
+			Run: func(ctx context.Context) diagnostics.Result {
+				return checkNameserver(ctx, nameserver, lookupHost)
+			},
+		})
+	}
+
+	// HTTP
+	checks = append(checks, diagnostics.Check{
+		Name: "Connectivity check",
+		Run: func(ctx context.Context) diagnostics.Result {
+			return checkConnectivityCheckURI(ctx, nmState.ConnectivityCheckURI)
+		},
+	})
+	for _, endpoint := range h.dc.Config.Endpoints {
+		checks = append(checks, diagnostics.Check{
+			Name: fmt.Sprintf("Endpoint %s", endpoint.Host),
+			Run: func(ctx context.Context) diagnostics.Result {
+				return checkEndpoint(ctx, endpoint)
+			},
+		})
+	}
+	return checks
+}
+
+func (h *Handlers) checkLink(ctx context.Context, iface string) diagnostics.Result {
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return diagnostics.Failed([]string{
+			"If this is a USB network module, check that it's plugged into the machine.",
+		}, "couldn't find %s: %s", iface, err)
+	}
+	isWifi := device.Type.Info().Short == "wifi"
+
+	if !device.Managed {
+		return diagnostics.Failed([]string{
+			"Check whether NetworkManager was configured to ignore this device.",
+		}, "%s isn't managed by NetworkManager", iface)
+	}
+	if !device.InterfaceFlags.IsUp() {
+		return diagnostics.Failed([]string{
+			"Connect the device to a network from the Internet page.",
+		}, "%s is down", iface)
+	}
+	if !device.InterfaceFlags.HasCarrier() {
+		if isWifi {
+			return diagnostics.Failed([]string{
+				"Connect the machine to an external Wi-Fi network from the Internet page.",
+				"Check that the Wi-Fi network is in range.",
+			}, "%s isn't connected to any Wi-Fi network", iface)
+		}
+		return diagnostics.Failed([]string{
+			"Check that the cable is plugged into both the machine and the router or switch.",
+			"Try another cable or another port on the router or switch.",
+		}, "%s has no link, so the cable is probably unplugged or broken", iface)
+	}
+	if state := device.State.Info(); state.Short != "activated" {
+		fixes := []string{
+			"Wait a minute and run the diagnostics again, in case the connection is still starting.",
+		}
+		if isWifi {
+			fixes = append(fixes, "Check that the Wi-Fi password saved on the machine is correct.")
+		}
+		return diagnostics.Failed(
+			fixes, "%s is %s (%s)", iface, state.Short, device.StateReason.Info().Short,
+		)
+	}
+	return diagnostics.Passed("%s is connected with %s", iface, device.ActiveConn.ID)
+}
+
+func (h *Handlers) checkIPv4Address(ctx context.Context, iface string) diagnostics.Result {
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return diagnostics.Failed(nil, "couldn't find %s: %s", iface, err)
+	}
+
+	if options := device.DHCPv4Config.Options; options["ip_address"] != "" {
+		summary := fmt.Sprintf("%s has the address %s", iface, options["ip_address"])
+		if server := options["dhcp_server_identifier"]; server != "" {
+			summary += fmt.Sprintf(" from the DHCP server %s", server)
+		}
+		if rawLeaseTime := options["dhcp_lease_time"]; rawLeaseTime != "" {
+			summary += fmt.Sprintf(" for %s seconds", rawLeaseTime)
+		}
+		return diagnostics.Passed("%s", summary)
+	}
+	if addresses := device.IPv4Config.Addresses; len(addresses) > 0 {
+		return diagnostics.Passed(
+			"%s has the address %s, which wasn't assigned by DHCP", iface, addresses[0].Prefix,
+		)
+	}
+	return diagnostics.Failed([]string{
+		"Check that the router's DHCP server is enabled and has addresses left to give out.",
+		"Restart the router.",
+		"If the network has no DHCP server, set a static address in the advanced view of the " +
+			"connection profile.",
+	}, "%s has no IPv4 address", iface)
+}
+
+func (h *Handlers) checkDefaultRoute(ctx context.Context, iface string) diagnostics.Result {
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return diagnostics.Failed(nil, "couldn't find %s: %s", iface, err)
+	}
+	gateway := device.IPv4Config.Gateway
+	if gateway == "" {
+		return diagnostics.Failed([]string{
+			"Check that the router's DHCP server provides a default gateway (router option).",
+			"If you set a static address, also set a gateway in the advanced view of the connection " +
+				"profile.",
+		}, "%s has no default gateway, so it can only reach its local network", iface)
+	}
+
+	nmState, err := h.nmc.Get()
+	if err != nil {
+		return diagnostics.Failed(nil, "couldn't get overall information about NetworkManager: %s", err)
+	}
+	primary := nmState.PrimaryConnection
+	if primary.HasData() && primary.UUID != device.ActiveConn.UUID {
+		return diagnostics.Warning([]string{
+			fmt.Sprintf(
+				"If the internet should be reached through %s, disconnect %s or give %s a lower route "+
+					"metric.", iface, primary.ID, device.ActiveConn.ID,
+			),
+		},
+			"%s has the gateway %s, but internet traffic goes through %s instead",
+			iface, gateway, strings.Join(primary.DeviceInterfaces, ", "),
+		)
+	}
+	return diagnostics.Passed("internet traffic goes through the gateway %s", gateway)
+}
+
+func checkGatewayReachability(
+	ctx context.Context, iface string, scc *sc.Client, l godest.Logger,
+) diagnostics.Result {
+	const count = 3
+	stats, err := pingGatewayViaSidecar(ctx, iface, count, scc, l)
+	if err != nil {
+		return diagnostics.Failed(nil, "couldn't ping the gateway: %s", err)
+	}
+	fixes := []string{
+		"Check that the router is powered on and working.",
+		"Some routers ignore pings; if the following steps pass, this warning can be ignored.",
+	}
+	switch {
+	case stats.Received == 0:
+		return diagnostics.Warning(
+			fixes, "the gateway %s didn't reply to any of %d pings", stats.Gateway, stats.Sent,
+		)
+	case stats.Received < stats.Sent:
+		return diagnostics.Warning([]string{
+			"If the machine uses Wi-Fi, move it closer to the Wi-Fi router.",
+			"Check that the network isn't overloaded.",
+		},
+			"the gateway %s only replied to %d of %d pings, so the connection may be unreliable",
+			stats.Gateway, stats.Received, stats.Sent,
+		)
+	default:
+		return diagnostics.Passed(
+			"the gateway %s replied to all %d pings, in %s on average",
+			stats.Gateway, stats.Sent, stats.RTT.Round(100*time.Microsecond),
+		)
+	}
+}
+
+type gatewayPingStats struct {
+	Gateway string
+	diagnostics.PingStats
+}
+
+func pingGatewayViaSidecar(
+	ctx context.Context, iface string, count int, scc *sc.Client, l godest.Logger,
+) (s gatewayPingStats, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return s, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	gateway, sent, received, rttUSec, err := nmipc.PingGateway().Call(
+		ctx, conn, iface, int64(count),
+	)
+	if err != nil {
+		return s, errors.Wrap(err, "couldn't call sidecar's PingGateway method")
+	}
+	s.Gateway = gateway
+	s.Sent = int(sent)
+	s.Received = int(received)
+	s.RTT = time.Duration(rttUSec) * time.Microsecond
+	return s, nil
+}
+
+func checkNameserver(ctx context.Context, nameserver netip.Addr, host string) diagnostics.Result {
+	addrs, err := diagnostics.LookupHost(ctx, nameserver, host)
+	if err != nil {
+		return diagnostics.Failed([]string{
+			"The DNS server may be down or blocked by the network; set other DNS servers (e.g. " +
+				"1.1.1.1 and 9.9.9.9) in the advanced view of the connection profile.",
+		}, "%s couldn't look up %s: %s", nameserver, host, errors.Cause(err))
+	}
+	return diagnostics.Passed("%s looked up %s as %s", nameserver, host, strings.Join(addrs, ", "))
+}
+
+// nmCheckOnlineBody is the response body from NetworkManager's default connectivity check URI.
+const nmCheckOnlineBody = "NetworkManager is online"
+
+func checkConnectivityCheckURI(ctx context.Context, rawURI string) diagnostics.Result {
+	if rawURI == "" {
+		return diagnostics.Skipped("connectivity checking is disabled in NetworkManager")
+	}
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return diagnostics.Failed(nil, "couldn't parse the connectivity check URI %s", rawURI)
+	}
+
+	res, err := diagnostics.ProbeHTTP(ctx, uri)
+	if err != nil {
+		return diagnostics.Failed([]string{
+			"The network's firewall may block web traffic; ask the network's administrator.",
+		}, "couldn't reach %s: %s", uri.Host, errors.Cause(err))
+	}
+	if res.IsRedirect() {
+		return diagnostics.Warning([]string{
+			"Log in through the captive portal from the Internet page of this admin panel.",
+			"Ask the network's administrator to allow this machine's MAC address without sign-in.",
+		}, "%s redirected to %s, so the network probably has a captive portal", uri.Host, res.Location)
+	}
+	if res.Header.Get("X-NetworkManager-Status") == "online" ||
+		strings.HasPrefix(res.Body, nmCheckOnlineBody) {
+		return diagnostics.Passed("%s responded as expected", uri.Host)
+	}
+	return diagnostics.Warning([]string{
+		"The network may require sign-in through a captive portal; open any website in a browser on " +
+			"a device connected to the same network.",
+		"The network may be filtering web traffic; ask the network's administrator.",
+	}, "%s gave an unexpected response (%s)", uri.Host, res.Status())
+}
+
+func checkEndpoint(ctx context.Context, endpoint *url.URL) diagnostics.Result {
+	res, err := diagnostics.ProbeHTTP(ctx, endpoint)
+	if err != nil {
+		return diagnostics.Failed([]string{
+			fmt.Sprintf(
+				"The network's firewall may block %s; ask the network's administrator to allow %s "+
+					"traffic to it.", endpoint.Host, strings.ToUpper(endpoint.Scheme),
+			),
+		}, "couldn't reach %s: %s", endpoint.Host, errors.Cause(err))
+	}
+	return diagnostics.Passed("%s responded (%s)", endpoint.Host, res.Status())
+}
@@ -0,0 +1,172 @@
+package internet
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest/turbostreams"
+
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	"github.com/openUC2/machine-admin/internal/clients/diagnostics"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+func (h *Handlers) HandleDiagnosticsGet() echo.HandlerFunc {
+	t := "internet/diagnostics/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Run queries
+		ctx := c.Request().Context()
+		vd := DiagnosticsViewData{
+			Endpoints: h.dc.Config.Endpoints,
+			Runs:      h.dc.ListRuns(),
+		}
+		nmState, err := h.nmc.Get()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get overall information about NetworkManager")
+		}
+		vd.ConnectivityCheckURI = nmState.ConnectivityCheckURI
+		devices, err := h.nmc.GetDevices(ctx)
+		if err != nil {
+			return errors.Wrap(err, "couldn't list network devices")
+		}
+		vd.DefaultIface = resolveRoles(h.roles, devices).UplinkIface
+		if ifaces := nmState.PrimaryConnection.DeviceInterfaces; len(ifaces) > 0 {
+			vd.DefaultIface = ifaces[0]
+		}
+		for _, device := range devices {
+			if device.Type.Info().Short == "loopback" {
+				continue
+			}
+			vd.Devices = append(vd.Devices, device)
+		}
+
+		// Produce output
+		return h.r.CacheablePage(c.Response(), c.Request(), t, vd, struct{}{})
+	}
+}
+
+type DiagnosticsViewData struct {
+	Devices              []nm.Device
+	DefaultIface         string
+	ConnectivityCheckURI string
+	Endpoints            []*url.URL
+	Runs                 []diagnostics.Run
+}
+
+func (h *Handlers) HandleDiagnosticsPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.FormValue("iface")
+
+		// Run queries
+		ctx := c.Request().Context()
+		device, err := h.nmc.GetDeviceByIface(ctx, iface)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("unknown device %s", iface))
+		}
+		nmState, err := h.nmc.Get()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get overall information about NetworkManager")
+		}
+		id := h.dc.StartRun(iface, h.makeDiagnosticsChecks(nmState, device))
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, fmt.Sprintf(
+			"%sinternet/diagnostics/runs/%s", h.r.BasePath, id,
+		))
+	}
+}
+
+func (h *Handlers) HandleDiagnosticsRunGetByID() echo.HandlerFunc {
+	t := "internet/diagnostics/runs/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Parse params
+		id, err := parseDiagnosticsRunID(c.Param("id"))
+		if err != nil {
+			return err // we don't wrap the error, which is an HTTP error about an invalid ID
+		}
+
+		// Run queries
+		run, err := h.dc.GetRun(id)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf(
+				"diagnostics run %s doesn't exist or is too old", id,
+			))
+		}
+
+		// Produce output
+		// Note: the run's progress changes quickly, so the page must not be cached
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return h.r.Page(
+			c.Response(), c.Request(), http.StatusOK, t, DiagnosticsRunViewData{Run: run}, struct{}{},
+		)
+	}
+}
+
+type DiagnosticsRunViewData struct {
+	Run          diagnostics.Run
+	IsStreamPage bool
+}
+
+func parseDiagnosticsRunID(rawID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.UUID{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"invalid diagnostics run id %s", rawID,
+		))
+	}
+	return id, nil
+}
+
+func (h *Handlers) HandleDiagnosticsRunSubByID() turbostreams.HandlerFunc {
+	return func(c *turbostreams.Context) error {
+		// Parse params
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return errors.Wrapf(err, "couldn't parse diagnostics run id %s", c.Param("id"))
+		}
+
+		// Run queries
+		if _, err := h.dc.GetRun(id); err != nil {
+			return err
+		}
+
+		// Allow subscription
+		return nil
+	}
+}
+
+func (h *Handlers) HandleDiagnosticsRunPubByID() turbostreams.HandlerFunc {
+	t := "internet/diagnostics/runs/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c *turbostreams.Context) error {
+		// Parse params
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return errors.Wrapf(err, "couldn't parse diagnostics run id %s", c.Param("id"))
+		}
+
+		// Publish on changes
+		changes := h.dc.Subscribe(c.Context(), id)
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
+			run, err := h.dc.GetRun(id)
+			if err != nil {
+				return false, err
+			}
+			// Produce output
+			vd := DiagnosticsRunViewData{
+				Run:          run,
+				IsStreamPage: true,
+			}
+			// Note: once the run has finished, its results won't change anymore
+			return !run.Finished.IsZero(), sh.PublishPageReload(c, h.r, t, vd)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package internet
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/clients/hostsfile"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// DNSOverrides are the machine-wide name resolution settings which override the settings obtained
+// from connection profiles and from networks.
+type DNSOverrides struct {
+	// GlobalDNS is used instead of the DNS servers of every connection profile, if it has data
+	GlobalDNS   nm.GlobalDNS
+	HostEntries []hostsfile.Entry
+	Err         error
+}
+
+// collectDNSOverrides adds the global DNS servers and the static host entries.
+func collectDNSOverrides(
+	ctx context.Context, vd *InternetViewData, scc *sc.Client, l godest.Logger,
+) {
+	// Note: the rest of the page is still useful if the overrides can't be determined, so the page
+	// should explain the error instead of failing
+	if vd.DNSOverrides.GlobalDNS, vd.DNSOverrides.Err = getGlobalDNSViaSidecar(
+		ctx, scc, l,
+	); vd.DNSOverrides.Err != nil {
+		return
+	}
+	vd.DNSOverrides.HostEntries, vd.DNSOverrides.Err = listHostEntriesViaSidecar(ctx, scc, l)
+}
+
+// Global DNS
+
+func (h *Handlers) HandleGlobalDNSPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		state := c.FormValue("state")
+		rawServers := splitList(c.FormValue("servers"))
+		searches := splitList(c.FormValue("searches"))
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid global DNS state %s", state,
+			))
+		case "updated":
+			var dns nm.GlobalDNS
+			for _, rawServer := range rawServers {
+				server, err := netip.ParseAddr(rawServer)
+				if err != nil {
+					return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+						"DNS server %s is not an IP address", rawServer,
+					))
+				}
+				dns.Servers = append(dns.Servers, server)
+			}
+			dns.Searches = searches
+			if err := h.setGlobalDNS(c, dns); err != nil {
+				return err
+			}
+		case "removed":
+			if err := h.setGlobalDNS(c, nm.GlobalDNS{}); err != nil {
+				return err
+			}
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+func (h *Handlers) setGlobalDNS(c echo.Context, dns nm.GlobalDNS) error {
+	if err := setGlobalDNSViaSidecar(
+		c.Request().Context(), dns, c.RealIP(), h.scc, h.l,
+	); err != nil {
+		var invalidErr *nmipc.InvalidDNSSettings
+		if errors.As(err, &invalidErr) {
+			return echo.NewHTTPError(http.StatusBadRequest, invalidErr.Description)
+		}
+		return errors.Wrap(err, "couldn't set global DNS servers")
+	}
+	return nil
+}
+
+func getGlobalDNSViaSidecar(
+	ctx context.Context, scc *sc.Client, l godest.Logger,
+) (dns nm.GlobalDNS, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return dns, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawServers, searches, err := nmipc.GetGlobalDNS().Call(ctx, conn)
+	if err != nil {
+		return dns, errors.Wrap(err, "couldn't call sidecar's GetGlobalDNS method")
+	}
+	for _, rawServer := range rawServers {
+		server, err := netip.ParseAddr(rawServer)
+		if err != nil {
+			return dns, errors.Wrapf(err, "couldn't parse DNS server %s", rawServer)
+		}
+		dns.Servers = append(dns.Servers, server)
+	}
+	dns.Searches = searches
+	return dns, nil
+}
+
+func setGlobalDNSViaSidecar(
+	ctx context.Context, dns nm.GlobalDNS, requester string, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	servers := make([]string, 0, len(dns.Servers))
+	for _, server := range dns.Servers {
+		servers = append(servers, server.String())
+	}
+	searches := append(make([]string, 0, len(dns.Searches)), dns.Searches...)
+	if err := nmipc.SetGlobalDNS().Call(ctx, conn, servers, searches, requester); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's SetGlobalDNS method")
+	}
+	return nil
+}
+
+// Host entries
+
+func (h *Handlers) HandleHostEntriesPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		rawEntries := c.FormValue("entries")
+		redirectTarget := c.FormValue("redirect-target")
+
+		entries := make([]hostsfile.Entry, 0)
+		for line := range strings.Lines(rawEntries) {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			entry, err := hostsfile.ParseEntry(line)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+					"invalid host entry: %s", err,
+				))
+			}
+			entries = append(entries, entry)
+		}
+		if err := hostsfile.Check(entries); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		// Run queries
+		if err := setHostEntriesViaSidecar(
+			c.Request().Context(), entries, c.RealIP(), h.scc, h.l,
+		); err != nil {
+			var invalidErr *nmipc.InvalidDNSSettings
+			if errors.As(err, &invalidErr) {
+				return echo.NewHTTPError(http.StatusBadRequest, invalidErr.Description)
+			}
+			return errors.Wrap(err, "couldn't set static host entries")
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+func listHostEntriesViaSidecar(
+	ctx context.Context, scc *sc.Client, l godest.Logger,
+) ([]hostsfile.Entry, error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawEntries, err := nmipc.ListHostEntries().Call(ctx, conn)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't call sidecar's ListHostEntries method")
+	}
+	entries := make([]hostsfile.Entry, 0, len(rawEntries))
+	for _, rawEntry := range rawEntries {
+		address, err := netip.ParseAddr(rawEntry.Address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse host entry address %s", rawEntry.Address)
+		}
+		entries = append(entries, hostsfile.Entry{Address: address, Hostnames: rawEntry.Hostnames})
+	}
+	return entries, nil
+}
+
+func setHostEntriesViaSidecar(
+	ctx context.Context, entries []hostsfile.Entry, requester string,
+	scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawEntries := make([]nmipc.HostEntry, 0, len(entries))
+	for _, entry := range entries {
+		rawEntries = append(rawEntries, nmipc.HostEntry{
+			Address: entry.Address.String(), Hostnames: entry.Hostnames,
+		})
+	}
+	if err := nmipc.SetHostEntries().Call(ctx, conn, rawEntries, requester); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's SetHostEntries method")
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// sharingAutoconnectPriority is the autoconnect priority of connection profiles for sharing the
+// machine's internet connection over Ethernet, so that sharing stays on across restarts instead of
+// being replaced by an Ethernet connection profile for joining an external network.
+const sharingAutoconnectPriority = 100
+
+// EthernetSharing describes whether an Ethernet device shares the machine's internet connection
+// with the devices plugged into it (e.g. a lab PC), and with which devices.
+type EthernetSharing struct {
+	// ConnProfile is the empty value if sharing was never turned on for the device
+	ConnProfile nm.ConnProfile
+	Active      bool
+	// Address is the device's own address on the shared network, if sharing is active
+	Address netip.Prefix
+	// DHCPFirst and DHCPLast are the range of addresses which the machine hands out to the devices
+	// plugged into the Ethernet device, if sharing is active
+	DHCPFirst netip.Addr
+	DHCPLast  netip.Addr
+	Clients   HotspotClients
+}
+
+// isSharingConnProfile checks whether the connection profile shares the machine's internet
+// connection over the Ethernet device with the network interface.
+func isSharingConnProfile(connProfile nm.ConnProfile, iface string) bool {
+	settings := connProfile.Settings
+	return settings.Conn.Type.Info().Short == "ethernet" &&
+		settings.IPv4.Method.Info().Short == "shared" &&
+		settings.Conn.InterfaceName == iface
+}
+
+// findSharingConnProfile returns the connection profile which shares the machine's internet
+// connection over the Ethernet device, preferring the active one if there are several; it returns
+// the empty value if there is none.
+func findSharingConnProfile(connProfiles []nm.ConnProfile, device nm.Device) nm.ConnProfile {
+	iface := cmp.Or(device.IpInterface, device.ControlInterface)
+	var found nm.ConnProfile
+	for _, connProfile := range connProfiles {
+		if !isSharingConnProfile(connProfile, iface) {
+			continue
+		}
+		if connProfile.Settings.Conn.UUID == device.ActiveConn.UUID {
+			return connProfile
+		}
+		if !found.HasData() {
+			found = connProfile
+		}
+	}
+	return found
+}
+
+// collectEthernetSharing adds information about connection sharing for each Ethernet device. It
+// must be called after the devices have been collected.
+func collectEthernetSharing(
+	ctx context.Context, vd *InternetViewData, nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) error {
+	connProfiles, err := nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't list connection profiles")
+	}
+	vd.EthernetSharing = make(map[string]EthernetSharing)
+	for _, device := range vd.EthernetDevices {
+		iface := cmp.Or(device.IpInterface, device.ControlInterface)
+		sharing := EthernetSharing{ConnProfile: findSharingConnProfile(connProfiles, device)}
+		sharing.Active = sharing.ConnProfile.HasData() &&
+			device.ActiveConn.UUID == sharing.ConnProfile.Settings.Conn.UUID
+		if sharing.Active {
+			for _, address := range device.IPv4Config.Addresses {
+				first, last, ok := nm.SharedDHCPRange(address.Prefix)
+				if !ok {
+					continue
+				}
+				sharing.Address = address.Prefix
+				sharing.DHCPFirst, sharing.DHCPLast = first, last
+				break
+			}
+			// The list of devices is just for information, so we show any error on the page instead of
+			// failing to render the page:
+			clients, err := listHotspotClientsViaSidecar(ctx, iface, scc, l)
+			sharing.Clients = HotspotClients{Clients: clients, Err: err}
+		}
+		vd.EthernetSharing[iface] = sharing
+	}
+	return nil
+}
+
+// by interface
+
+func (h *Handlers) HandleDeviceSharingPostByIface() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		iface := c.Param("iface")
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		// We use the background context so that if the user's connection to the server is interrupted
+		// by the change (e.g. if the user is connected through the Ethernet device), the operation is
+		// not interrupted by context cancellation:
+		ctx := context.Background()
+		device, err := h.nmc.GetDeviceByIface(ctx, iface)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("unknown device %s", iface))
+		}
+		if device.Type.Info().Short != "ethernet" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"%s is not an Ethernet device", iface,
+			))
+		}
+		var change func() error
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid Ethernet sharing state %s", state,
+			))
+		case "shared":
+			change = func() error {
+				return startEthernetSharing(ctx, device, h.nmc)
+			}
+		case "unshared":
+			change = func() error {
+				return stopEthernetSharing(ctx, device, h.nmc)
+			}
+		}
+		// Changing the Ethernet device's mode might disconnect the user from the machine, so we only
+		// keep the changes if the user confirms that they can still reach the machine:
+		checkpointID, err := checkpointedChange(ctx, change, h.scc, h.l)
+		if err != nil {
+			return err
+		}
+
+		// Redirect user
+		return c.Redirect(
+			http.StatusSeeOther, checkpointPath(h.r.BasePath, checkpointID, redirectTarget),
+		)
+	}
+}
+
+// startEthernetSharing activates a connection profile which shares the machine's internet
+// connection over the Ethernet device, adding the connection profile if it doesn't exist yet.
+func startEthernetSharing(ctx context.Context, device nm.Device, nmc *nm.Client) error {
+	iface := cmp.Or(device.IpInterface, device.ControlInterface)
+	connProfiles, err := nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't list connection profiles")
+	}
+	key := nm.NewConnProfileSettingsKey
+	connProfile := findSharingConnProfile(connProfiles, device)
+	uid := connProfile.Settings.Conn.UUID
+	switch {
+	case !connProfile.HasData():
+		settings, err := newConnProfileDefaults("ethernet")
+		if err != nil {
+			return err
+		}
+		settings[key("connection", "id")] = iface + "-shared"
+		settings[key("connection", "interface-name")] = iface
+		settings[key("connection", "autoconnect-priority")] = int32(sharingAutoconnectPriority)
+		settings[key("ipv4", "method")] = nm.ConnProfileSettingsIPv4Method("shared")
+		settings[key("ipv6", "method")] = nm.ConnProfileSettingsIPv6Method("disabled")
+		if uid, err = nmc.AddConnProfile(ctx, "save", settings); err != nil {
+			return errors.Wrapf(err, "couldn't add connection profile for sharing over %s", iface)
+		}
+	case !connProfile.Settings.Conn.Autoconnect:
+		if err = nmc.UpdateConnProfileByUUID(ctx, uid, "save", "", map[nm.ConnProfileSettingsKey]any{
+			key("connection", "autoconnect"): true,
+		}); err != nil {
+			return errors.Wrapf(err, "couldn't enable autoconnect for connection profile %s", uid)
+		}
+	}
+	if err = nmc.ActivateConnProfile(ctx, uid); err != nil {
+		return errors.Wrapf(err, "couldn't activate connection profile %s", uid)
+	}
+	return nil
+}
+
+// stopEthernetSharing reverts the Ethernet device to its normal mode of joining an external
+// network. The connection profile for sharing is kept (but no longer connected automatically), so
+// that sharing can be turned on again with the same settings.
+func stopEthernetSharing(ctx context.Context, device nm.Device, nmc *nm.Client) error {
+	connProfiles, err := nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't list connection profiles")
+	}
+	connProfile := findSharingConnProfile(connProfiles, device)
+	if !connProfile.HasData() {
+		return nil // sharing was never turned on
+	}
+	uid := connProfile.Settings.Conn.UUID
+	if err = nmc.UpdateConnProfileByUUID(ctx, uid, "save", "", map[nm.ConnProfileSettingsKey]any{
+		nm.NewConnProfileSettingsKey("connection", "autoconnect"): false,
+	}); err != nil {
+		return errors.Wrapf(err, "couldn't disable autoconnect for connection profile %s", uid)
+	}
+	// Once the connection profile is deactivated, NetworkManager automatically activates the
+	// device's preferred connection profile for joining an external network, if it has one:
+	if err = nmc.DeactivateConnProfile(ctx, uid); err != nil {
+		return errors.Wrapf(err, "couldn't deactivate connection profile %s", uid)
+	}
+	return nil
+}
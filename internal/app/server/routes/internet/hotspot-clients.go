@@ -0,0 +1,78 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/clients/hotspot"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+// hotspotClientsRefreshInterval is how often pages showing the devices connected to hotspots
+// should be refreshed, since NetworkManager doesn't notify us when devices join or leave.
+const hotspotClientsRefreshInterval = 10 * time.Second
+
+// HotspotClients lists the devices connected to a Wi-Fi hotspot.
+type HotspotClients struct {
+	Clients []hotspot.Client
+	// Err is why the devices couldn't be listed, if they couldn't be listed.
+	Err error
+}
+
+// collectHotspotClients lists the devices connected to each Wi-Fi device acting as a hotspot.
+func collectHotspotClients(
+	ctx context.Context, vd *InternetViewData, scc *sc.Client, l godest.Logger,
+) {
+	vd.HotspotClients = make(map[string]HotspotClients)
+	for _, device := range vd.WifiDevices {
+		if device.Wifi.Mode.Info().Short != "hotspot" {
+			continue
+		}
+		iface := cmp.Or(device.IpInterface, device.ControlInterface)
+		// The list of devices is just for information, so we show any error on the page instead of
+		// failing to render the page:
+		clients, err := listHotspotClientsViaSidecar(ctx, iface, scc, l)
+		vd.HotspotClients[iface] = HotspotClients{Clients: clients, Err: err}
+	}
+}
+
+func listHotspotClientsViaSidecar(
+	ctx context.Context, iface string, scc *sc.Client, l godest.Logger,
+) ([]hotspot.Client, error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawClients, err := nmipc.ListHotspotClients().Call(ctx, conn, iface)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't call sidecar's ListHotspotClients method")
+	}
+	clients := make([]hotspot.Client, 0, len(rawClients))
+	for _, rawClient := range rawClients {
+		client := hotspot.Client{
+			MACAddress: rawClient.MacAddress,
+			Hostname:   rawClient.Hostname,
+			Signal:     int(rawClient.SignalDBm),
+			Connected:  time.Duration(rawClient.ConnectedSec) * time.Second,
+			Inactive:   time.Duration(rawClient.InactiveMSec) * time.Millisecond,
+		}
+		if rawClient.IpAddress != "" {
+			if client.IPAddress, err = netip.ParseAddr(rawClient.IpAddress); err != nil {
+				return nil, errors.Wrapf(err, "couldn't parse IP address of %s", rawClient.MacAddress)
+			}
+		}
+		if rawClient.LeaseExpiry > 0 {
+			client.LeaseExpiry = time.Unix(rawClient.LeaseExpiry, 0)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
@@ -0,0 +1,206 @@
+package internet
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	qrcode "github.com/skip2/go-qrcode"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+func (h *Handlers) HandleHotspotQRCodeGet(format string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Run queries
+		joinInfo, err := getHotspotJoinInfo(c, h.roles, h.nmc, h.scc, h.l)
+		if err != nil {
+			return err // we don't wrap the error, which may be an HTTP error about a missing hotspot
+		}
+		qrCode, err := qrcode.New(joinInfo.QRCodeContent(), qrcode.Medium)
+		if err != nil {
+			return errors.Wrap(err, "couldn't generate QR code")
+		}
+
+		// Produce output
+		// Note: the QR code contains the Wi-Fi password, so it must not be cached
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		switch format {
+		default:
+			return errors.Errorf("unknown QR code format %s", format)
+		case "svg":
+			return c.Blob(http.StatusOK, "image/svg+xml", renderQRCodeSVG(qrCode))
+		case "png":
+			const size = 512 // px
+			png, err := qrCode.PNG(size)
+			if err != nil {
+				return errors.Wrap(err, "couldn't render QR code as PNG")
+			}
+			return c.Blob(http.StatusOK, "image/png", png)
+		}
+	}
+}
+
+// renderQRCodeSVG renders the QR code as an SVG image with one unit per module, so that it can be
+// scaled to any size.
+func renderQRCodeSVG(qrCode *qrcode.QRCode) []byte {
+	bitmap := qrCode.Bitmap() // this includes the quiet zone around the QR code
+	var b bytes.Buffer
+	fmt.Fprintf(
+		&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		len(bitmap), len(bitmap),
+	)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range bitmap {
+		for x, isSet := range row {
+			if isSet {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.Bytes()
+}
+
+func (h *Handlers) HandleHotspotLabelGet() echo.HandlerFunc {
+	t := "internet/hotspot/label.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Run queries
+		vd := HotspotLabelViewData{}
+		var err error
+		if vd.WifiJoinInfo, err = getHotspotJoinInfo(c, h.roles, h.nmc, h.scc, h.l); err != nil {
+			return err // we don't wrap the error, which may be an HTTP error about a missing hotspot
+		}
+		// Every reveal of the password counts against the sidecar's rate limit, so we embed the QR
+		// code in the page rather than making the browser request it separately:
+		qrCode, err := qrcode.New(vd.QRCodeContent(), qrcode.Medium)
+		if err != nil {
+			return errors.Wrap(err, "couldn't generate QR code")
+		}
+		vd.QRCodeURL = template.URL( //nolint:gosec // the URL only has our own base64-encoded SVG
+			"data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(renderQRCodeSVG(qrCode)),
+		)
+
+		// Produce output
+		// Note: the label shows the Wi-Fi password, so it must not be cached
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+type HotspotLabelViewData struct {
+	WifiJoinInfo
+	QRCodeURL template.URL
+}
+
+// WifiJoinInfo is the information which people need to join a Wi-Fi network.
+type WifiJoinInfo struct {
+	SSID    string
+	KeyMgmt nm.ConnProfileSettingsWifiSecKeyMgmt
+	PSK     string
+	Hidden  bool
+}
+
+// QRCodeContent returns the text which phones expect in a QR code for joining the Wi-Fi network,
+// in the form "WIFI:T:<security>;S:<ssid>;P:<password>;;".
+func (i WifiJoinInfo) QRCodeContent() string {
+	escaper := strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `"`, `\"`, `:`, `\:`)
+	security := "WPA"
+	switch i.KeyMgmt {
+	case "", "owe":
+		security = "nopass"
+	case "none":
+		security = "WEP"
+	}
+	content := fmt.Sprintf("WIFI:T:%s;S:%s;", security, escaper.Replace(i.SSID))
+	if security != "nopass" {
+		content += fmt.Sprintf("P:%s;", escaper.Replace(i.PSK))
+	}
+	if i.Hidden {
+		content += "H:true;"
+	}
+	return content + ";"
+}
+
+// getHotspotJoinInfo looks up the information for joining the hotspot, revealing the hotspot's
+// password to the client which made the request.
+func getHotspotJoinInfo(
+	c echo.Context, roles conf.RolesConfig, nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) (i WifiJoinInfo, err error) {
+	ctx := c.Request().Context()
+	connProfiles, err := nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return i, errors.Wrap(err, "couldn't list connection profiles")
+	}
+	var connProfile nm.ConnProfile
+	for _, c := range connProfiles {
+		if c.Settings.Conn.ID == roles.HotspotConnProfileID {
+			connProfile = c
+			break
+		}
+	}
+	if !connProfile.HasData() {
+		return i, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf(
+			"couldn't find the connection profile %s", roles.HotspotConnProfileID,
+		))
+	}
+
+	i.SSID = string(connProfile.Settings.Wifi.SSID)
+	i.KeyMgmt = connProfile.Settings.WifiSec.KeyMgmt
+	i.Hidden = connProfile.Settings.Wifi.Hidden
+	if i.KeyMgmt.UsesEAP() {
+		return i, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+			"Wi-Fi network %s uses enterprise authentication, which QR codes can't represent", i.SSID,
+		))
+	}
+	if i.KeyMgmt == "" || i.KeyMgmt == "owe" {
+		return i, nil
+	}
+	// The server isn't allowed to read Wi-Fi passwords from NetworkManager, so we only get the
+	// password through the sidecar, which limits how often it can be revealed and records who it was
+	// revealed to:
+	if i.PSK, err = getHotspotPSKViaSidecar(
+		ctx, connProfile.Settings.Conn.UUID, c.RealIP(), scc, l,
+	); err != nil {
+		var rateErr *nmipc.RateLimited
+		if errors.As(err, &rateErr) {
+			c.Response().Header().Set(
+				echo.HeaderRetryAfter, strconv.FormatInt(rateErr.RetryAfterSec, 10),
+			)
+			return i, echo.NewHTTPError(http.StatusTooManyRequests, fmt.Sprintf(
+				"too many Wi-Fi passwords were revealed recently; try again in %d seconds",
+				rateErr.RetryAfterSec,
+			))
+		}
+		return i, errors.Wrapf(err, "couldn't get password of Wi-Fi network %s", i.SSID)
+	}
+	return i, nil
+}
+
+func getHotspotPSKViaSidecar(
+	ctx context.Context, uid uuid.UUID, requester string, scc *sc.Client, l godest.Logger,
+) (psk string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if psk, err = nmipc.GetHotspotPSK().Call(ctx, conn, uid.String(), requester); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's GetHotspotPSK method")
+	}
+	return psk, nil
+}
@@ -0,0 +1,21 @@
+package internet
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	mm "github.com/openUC2/machine-admin/internal/clients/modemmanager"
+)
+
+// collectModems adds information about the cellular modems managed by ModemManager.
+func collectModems(ctx context.Context, vd *InternetViewData, mmc *mm.Client) {
+	// Note: the rest of the page is still useful if ModemManager isn't running (e.g. because the
+	// machine has never had a modem), so the page should explain the error instead of failing
+	modems, err := mmc.GetModems(ctx)
+	if err != nil {
+		vd.ModemsErr = errors.Wrap(err, "couldn't list modems")
+		return
+	}
+	vd.Modems = modems
+}
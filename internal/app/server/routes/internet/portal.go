@@ -0,0 +1,420 @@
+package internet
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	"github.com/sargassum-world/godest/turbostreams"
+
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	"github.com/openUC2/machine-admin/internal/clients/captiveportal"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// portalProxyTimeout is the longest amount of time which a request through the captive portal
+// proxy may take.
+const portalProxyTimeout = 30 * time.Second
+
+// portalHostMaxAge is how long the host of a captive portal is remembered for its uplink, before
+// the captive portal proxy checks again which host the connectivity check is redirected to.
+const portalHostMaxAge = 5 * time.Minute
+
+// defaultConnectivityCheckURI is used to find the captive portal when connectivity checking is
+// disabled in NetworkManager.
+const defaultConnectivityCheckURI = "http://nmcheck.gnome.org/check_network_status.txt"
+
+func (h *Handlers) portalRewriter() captiveportal.Rewriter {
+	return captiveportal.Rewriter{Prefix: h.r.BasePath + "internet/portal/proxy/"}
+}
+
+func (h *Handlers) TrailingSlashSkipper(c echo.Context) bool {
+	// Captive portals' pages may rely on trailing slashes for resolving relative URLs:
+	return strings.HasPrefix(c.Request().URL.Path, h.portalRewriter().Prefix)
+}
+
+func (h *Handlers) CSRFSkipper(c echo.Context) bool {
+	// Proxied pages are sandboxed (see portalContentSecurityPolicy), so their forms are submitted
+	// from an opaque origin, which the CSRF protection would reject. The proxy only passes requests on
+	// to captive portals (without the admin panel's cookies), so it doesn't need that protection.
+	return strings.HasPrefix(c.Request().URL.Path, h.portalRewriter().Prefix)
+}
+
+func (h *Handlers) HandlePortalGet() echo.HandlerFunc {
+	t := "internet/portal/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Run queries
+		ctx := c.Request().Context()
+		vd, err := getPortalViewData(ctx, h.roles, h.nmc)
+		if err != nil {
+			return err
+		}
+		if vd.UplinkErr == nil {
+			// Note: finding the portal requires a request through the uplink, so we only do it when the
+			// page is loaded (rather than whenever the page is published)
+			vd.PortalURL, vd.PortalErr = findPortal(ctx, vd.NM.ConnectivityCheckURI, vd.Uplink)
+			if vd.PortalURL != nil {
+				vd.PortalProxyPath = h.portalRewriter().ProxyPath(vd.PortalURL)
+				h.portalHosts.set(vd.Uplink, vd.PortalURL.Hostname())
+			}
+		}
+
+		// Produce output
+		// Note: the portal may change at any time, so the page must not be cached
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return h.r.Page(c.Response(), c.Request(), http.StatusOK, t, vd, struct{}{})
+	}
+}
+
+type PortalViewData struct {
+	NM        nm.NetworkManager
+	Uplink    nm.Device
+	UplinkErr error
+
+	PortalURL       *url.URL
+	PortalProxyPath string
+	PortalErr       error
+
+	IsStreamPage bool
+}
+
+func getPortalViewData(
+	ctx context.Context, roles conf.RolesConfig, nmc *nm.Client,
+) (vd PortalViewData, err error) {
+	if vd.NM, err = nmc.Get(); err != nil {
+		return vd, errors.Wrap(err, "couldn't get overall information about NetworkManager")
+	}
+	// Note: the uplink's device might be missing (e.g. if the USB Wi-Fi module was unplugged), which
+	// the page should explain instead of failing
+	vd.Uplink, vd.UplinkErr = getPortalUplink(ctx, vd.NM, roles, nmc)
+	return vd, nil
+}
+
+// getPortalUplink returns the device which provides the machine's connection to the network
+// behind the captive portal.
+func getPortalUplink(
+	ctx context.Context, nmState nm.NetworkManager, roles conf.RolesConfig, nmc *nm.Client,
+) (nm.Device, error) {
+	var iface string
+	if ifaces := nmState.PrimaryConnection.DeviceInterfaces; len(ifaces) > 0 {
+		iface = ifaces[0]
+	} else {
+		resolved, err := getRoles(ctx, roles, nmc)
+		if err != nil {
+			return nm.Device{}, errors.Wrap(err, "couldn't determine roles of network interfaces")
+		}
+		if iface = resolved.UplinkIface; iface == "" {
+			return nm.Device{}, errors.New("the machine has no connection to an external network")
+		}
+	}
+	device, err := nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return nm.Device{}, errors.Wrapf(err, "couldn't get device %s", iface)
+	}
+	return device, nil
+}
+
+// newPortalHTTPClient makes an HTTP client which sends requests through the uplink device. It may
+// only reach the portal host at non-public addresses.
+func newPortalHTTPClient(uplink nm.Device, portalHost string) (*http.Client, error) {
+	if uplink.IpInterface == "" {
+		return nil, errors.Errorf("%s has no network interface", uplink.ControlInterface)
+	}
+	addresses := uplink.IPv4Config.Addresses
+	if len(addresses) == 0 {
+		return nil, errors.Errorf("%s has no IPv4 address", uplink.IpInterface)
+	}
+	nameservers := slices.Concat(
+		uplink.IPv4Config.DNS.Nameservers, uplink.IPv6Config.DNS.Nameservers,
+	)
+	return captiveportal.NewHTTPClient(
+		uplink.IpInterface, addresses[0].Prefix.Addr(), nameservers, portalHost, portalProxyTimeout,
+	), nil
+}
+
+// findPortal returns the URL of the captive portal's page, or nil if no captive portal was found.
+func findPortal(
+	ctx context.Context, rawConnectivityCheckURI string, uplink nm.Device,
+) (*url.URL, error) {
+	checkURI, err := url.Parse(cmp.Or(rawConnectivityCheckURI, defaultConnectivityCheckURI))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse connectivity check URI %s", checkURI)
+	}
+	// Note: captive portals often answer DNS queries for the connectivity check's host with their
+	// own private addresses
+	client, err := newPortalHTTPClient(uplink, checkURI.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checkURI.String(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't make request for %s", checkURI)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get %s", checkURI)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if location := res.Header.Get("Location"); location != "" {
+		portalURL, err := checkURI.Parse(location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse captive portal URL %s", location)
+		}
+		return portalURL, nil
+	}
+	const maxBodySize = 1024 // bytes
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read response from %s", checkURI)
+	}
+	if res.Header.Get("X-NetworkManager-Status") == "online" ||
+		bytes.HasPrefix(body, []byte(nmCheckOnlineBody)) {
+		return nil, nil
+	}
+	// Some captive portals serve their page in place of the connectivity check's response
+	return checkURI, nil
+}
+
+func (h *Handlers) HandlePortalPub() turbostreams.HandlerFunc {
+	t := "internet/portal/index.page.tmpl"
+	h.r.MustHave(t)
+	return func(c *turbostreams.Context) error {
+		// Publish on changes
+		changes := h.nmc.Subscribe(c.Context())
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
+			vd, err := getPortalViewData(c.Context(), h.roles, h.nmc)
+			if err != nil {
+				return false, err
+			}
+			// Produce output
+			vd.IsStreamPage = true
+			return false, sh.PublishPageReload(c, h.r, t, vd)
+		})
+	}
+}
+
+func (h *Handlers) HandlePortalPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid captive portal state %s", state,
+			))
+		case "checked":
+			if _, err := h.nmc.CheckConnectivity(c.Request().Context()); err != nil {
+				return err
+			}
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+// portalHostCache remembers which host the connectivity check was last redirected to on an uplink,
+// so that the captive portal proxy doesn't need to repeat the connectivity check for every request.
+type portalHostCache struct {
+	mu      sync.Mutex
+	iface   string
+	host    string
+	checked time.Time
+}
+
+func (c *portalHostCache) set(uplink nm.Device, host string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.iface = uplink.IpInterface
+	c.host = host
+	c.checked = time.Now()
+}
+
+// get returns the host of the captive portal on the uplink, checking it again if it's unknown or
+// outdated. It returns an empty host if no captive portal was found.
+func (c *portalHostCache) get(
+	ctx context.Context, rawConnectivityCheckURI string, uplink nm.Device,
+) (string, error) {
+	c.mu.Lock()
+	if c.iface == uplink.IpInterface && time.Since(c.checked) < portalHostMaxAge {
+		host := c.host
+		c.mu.Unlock()
+		return host, nil
+	}
+	c.mu.Unlock()
+
+	portalURL, err := findPortal(ctx, rawConnectivityCheckURI, uplink)
+	if err != nil {
+		return "", err
+	}
+	host := ""
+	if portalURL != nil {
+		host = portalURL.Hostname()
+	}
+	c.set(uplink, host)
+	return host, nil
+}
+
+// portalProxyHeaders are the request headers which the captive portal proxy passes on to captive
+// portals. Other headers (e.g. the admin panel's cookies) must not be passed on.
+var portalProxyHeaders = []string{
+	echo.HeaderAccept, "Accept-Language", echo.HeaderContentType, "User-Agent",
+}
+
+func (h *Handlers) HandlePortalProxy() echo.HandlerFunc {
+	rewriter := h.portalRewriter()
+	return func(c echo.Context) error {
+		// Parse params
+		target, err := rewriter.ParseProxyPath(c.Param("*"), c.QueryString())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid captive portal URL: %s", err,
+			))
+		}
+
+		// Run queries
+		ctx := c.Request().Context()
+		nmState, err := h.nmc.Get()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get overall information about NetworkManager")
+		}
+		// Note: we only allow the proxy to be used when it's needed, so that people on the hotspot
+		// can't use it to reach the internet through the machine
+		if connectivity := nmState.Connectivity.Info().Short; connectivity != "portal" &&
+			connectivity != "limited" {
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf(
+				"the captive portal proxy is unavailable because internet connectivity is %s",
+				connectivity,
+			))
+		}
+		uplink, err := getPortalUplink(ctx, nmState, h.roles, h.nmc)
+		if err != nil {
+			return err
+		}
+		portalHost, err := h.portalHosts.get(ctx, nmState.ConnectivityCheckURI, uplink)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadGateway, fmt.Sprintf(
+				"couldn't find captive portal: %s", errors.Cause(err),
+			))
+		}
+		client, err := newPortalHTTPClient(uplink, portalHost)
+		if err != nil {
+			return err
+		}
+		res, err := proxyPortalRequest(ctx, c.Request(), target, client)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadGateway, fmt.Sprintf(
+				"couldn't reach captive portal: %s", errors.Cause(err),
+			))
+		}
+		defer func() {
+			_ = res.Body.Close()
+		}()
+
+		// Produce output
+		return writePortalResponse(c, res, target, rewriter, h.l)
+	}
+}
+
+func proxyPortalRequest(
+	ctx context.Context, r *http.Request, target *url.URL, client *http.Client,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, r.Method, target.String(), r.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't make request for %s", target)
+	}
+	for _, header := range portalProxyHeaders {
+		if value := r.Header.Get(header); value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	for _, cookie := range captiveportal.TargetCookies(r) {
+		req.AddCookie(cookie)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't send request to %s", target)
+	}
+	return res, nil
+}
+
+// portalResponseHeaders are the response headers which the captive portal proxy passes on from
+// captive portals, in addition to cookies and redirects. Other headers (e.g. security policies)
+// must not be passed on, since they would override the admin panel's headers.
+var portalResponseHeaders = []string{
+	echo.HeaderCacheControl, echo.HeaderContentType, "Expires", echo.HeaderLastModified,
+}
+
+// portalContentSecurityPolicy is added to every response from the captive portal proxy. Proxied
+// pages are served from the admin panel's origin, so the sandbox gives them a unique opaque origin
+// instead; without allow-same-origin, their scripts can't read the admin panel's pages or cookies.
+const portalContentSecurityPolicy = "sandbox allow-forms allow-scripts"
+
+func writePortalResponse(
+	c echo.Context, res *http.Response, target *url.URL, rewriter captiveportal.Rewriter,
+	l godest.Logger,
+) error {
+	header := c.Response().Header()
+	// Note: we add the policy rather than replacing the admin panel's own policy, so that both apply
+	header.Add(echo.HeaderContentSecurityPolicy, portalContentSecurityPolicy)
+	for _, name := range portalResponseHeaders {
+		if value := res.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+	if location := res.Header.Get("Location"); location != "" {
+		if absolute, err := target.Parse(location); err == nil {
+			header.Set("Location", rewriter.RewriteURL(absolute.String(), target))
+		}
+	}
+	for _, rawCookie := range res.Header.Values("Set-Cookie") {
+		cookie, err := rewriter.RewriteSetCookie(rawCookie, target)
+		if err != nil {
+			l.Warn(err)
+			continue
+		}
+		header.Add("Set-Cookie", cookie)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	default:
+		c.Response().WriteHeader(res.StatusCode)
+		_, err := io.Copy(c.Response(), res.Body)
+		return err
+	case echo.MIMETextHTML, "text/css":
+		const maxBodySize = 16 * 1024 * 1024 // bytes
+		body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+		if err != nil {
+			return errors.Wrapf(err, "couldn't read response from %s", target)
+		}
+		if mediaType == "text/css" {
+			body = rewriter.RewriteCSS(body, target)
+		} else {
+			body = rewriter.RewriteHTML(body, target)
+		}
+		return c.Blob(res.StatusCode, res.Header.Get(echo.HeaderContentType), body)
+	}
+}
@@ -0,0 +1,128 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+
+	"github.com/pkg/errors"
+
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// resolveRoles fills in the network interfaces which the configuration leaves unassigned, based
+// on the Wi-Fi devices which are present: the hotspot is the first Wi-Fi device which can act as
+// an access point, and the uplink is the first other Wi-Fi device. Neither role is given to the
+// interface which the configuration assigns to the other role. Either interface remains empty
+// if no suitable device is present (e.g. the uplink on a machine with a single Wi-Fi device).
+func resolveRoles(config conf.RolesConfig, devices []nm.Device) conf.RolesConfig {
+	if config.HotspotIface != "" && config.UplinkIface != "" {
+		return config
+	}
+	wifiDevices := make([]nm.Device, 0, len(devices))
+	for _, device := range devices {
+		if device.Type.Info().Short == "wifi" {
+			wifiDevices = append(wifiDevices, device)
+		}
+	}
+	slices.SortFunc(wifiDevices, func(a, b nm.Device) int {
+		return cmp.Compare(
+			cmp.Or(a.IpInterface, a.ControlInterface), cmp.Or(b.IpInterface, b.ControlInterface),
+		)
+	})
+
+	if config.HotspotIface == "" {
+		for _, device := range wifiDevices {
+			if iface := cmp.Or(device.IpInterface, device.ControlInterface); iface != "" &&
+				iface != config.UplinkIface && device.Wifi.Caps.SupportsAP() {
+				config.HotspotIface = iface
+				break
+			}
+		}
+	}
+	if config.UplinkIface == "" {
+		for _, device := range wifiDevices {
+			if iface := cmp.Or(device.IpInterface, device.ControlInterface); iface != "" &&
+				iface != config.HotspotIface {
+				config.UplinkIface = iface
+				break
+			}
+		}
+	}
+	return config
+}
+
+// getRoles resolves the roles from the configuration and the devices which are currently present.
+func getRoles(
+	ctx context.Context, config conf.RolesConfig, nmc *nm.Client,
+) (conf.RolesConfig, error) {
+	if config.HotspotIface != "" && config.UplinkIface != "" {
+		return config, nil
+	}
+	devices, err := nmc.GetDevices(ctx)
+	if err != nil {
+		return config, errors.Wrap(err, "couldn't list network devices")
+	}
+	return resolveRoles(config, devices), nil
+}
+
+// isFactoryConnProfile checks whether the connection profile is generated from the machine's
+// drop-in configuration files, in which case it can't be deleted or replaced through the UI.
+func isFactoryConnProfile(roles conf.RolesConfig, id string) bool {
+	return id == roles.HotspotConnProfileID || id == roles.InternetConnProfileID
+}
+
+// getFactoryConnProfileID returns the name of the factory connection profile which the stored
+// connection profile is, or an empty string if it isn't a factory connection profile. Besides the
+// profile's current name, this checks the name of the file which stores the profile, since
+// NetworkManager keeps a profile's file when the profile is renamed.
+func getFactoryConnProfileID(
+	ctx context.Context, uid uuid.UUID, roles conf.RolesConfig, nmc *nm.Client,
+) (string, error) {
+	connProfile, err := nmc.GetConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't get connection profile %s", uid)
+	}
+	if id := connProfile.Settings.Conn.ID; isFactoryConnProfile(roles, id) {
+		return id, nil
+	}
+	if connProfile.Filename == "" { // the connection profile only exists in memory
+		return "", nil
+	}
+	id := strings.TrimSuffix(path.Base(connProfile.Filename), ".nmconnection")
+	if isFactoryConnProfile(roles, id) {
+		return id, nil
+	}
+	return "", nil
+}
+
+// checkFactoryConnProfileRename refuses updates which would rename a factory connection profile,
+// since factory connection profiles are recognized by their names.
+func checkFactoryConnProfileRename(
+	ctx context.Context, uid uuid.UUID, formValues url.Values, roles conf.RolesConfig,
+	nmc *nm.Client,
+) error {
+	rawIDs := formValues["connection.id"]
+	if len(rawIDs) == 0 {
+		return nil
+	}
+	factoryID, err := getFactoryConnProfileID(ctx, uid, roles, nmc)
+	if err != nil {
+		return err
+	}
+	if id := rawIDs[len(rawIDs)-1]; factoryID != "" && id != factoryID {
+		return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf(
+			"the built-in connection profile %s can't be renamed", factoryID,
+		))
+	}
+	return nil
+}
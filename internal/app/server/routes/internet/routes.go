@@ -13,31 +13,49 @@ import (
 	"github.com/sargassum-world/godest/handling"
 	"github.com/sargassum-world/godest/turbostreams"
 
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
 	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	"github.com/openUC2/machine-admin/internal/clients/diagnostics"
+	mm "github.com/openUC2/machine-admin/internal/clients/modemmanager"
 	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
 	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+	"github.com/openUC2/machine-admin/internal/clients/wifireg"
 )
 
 type Handlers struct {
-	r godest.TemplateRenderer
+	r     godest.TemplateRenderer
+	roles conf.RolesConfig
 
 	tsh *turbostreams.Hub
 
 	nmc *nm.Client
+	mmc *mm.Client
+	dc  *diagnostics.Client
 	scc *sc.Client
 
+	snapshots   *connProfileSnapshots
+	portalHosts *portalHostCache
+
 	l godest.Logger
 }
 
 func New(
-	r godest.TemplateRenderer, tsh *turbostreams.Hub, nmc *nm.Client, scc *sc.Client, l godest.Logger,
+	r godest.TemplateRenderer, roles conf.RolesConfig, tsh *turbostreams.Hub,
+	nmc *nm.Client, mmc *mm.Client, dc *diagnostics.Client, scc *sc.Client, l godest.Logger,
 ) *Handlers {
 	return &Handlers{
-		r:   r,
-		tsh: tsh,
-		nmc: nmc,
-		scc: scc,
-		l:   l,
+		r:     r,
+		roles: roles,
+		tsh:   tsh,
+		nmc:   nmc,
+		mmc:   mmc,
+		dc:    dc,
+		scc:   scc,
+
+		snapshots:   newConnProfileSnapshots(),
+		portalHosts: &portalHostCache{},
+
+		l: l,
 	}
 }
 
@@ -50,12 +68,72 @@ func (h *Handlers) Register(er godest.EchoRouter, tr turbostreams.Router) {
 	tr.SUB(h.r.BasePath+"internet/devices/:iface/access-points", h.HandleDeviceAPsSubByIface())
 	tr.PUB(h.r.BasePath+"internet/devices/:iface/access-points", h.HandleDeviceAPsPubByIface())
 	er.POST(h.r.BasePath+"internet/devices/:iface/access-points", h.HandleDeviceAPsPostByIface())
+	// device-site-survey
+	er.GET(h.r.BasePath+"internet/devices/:iface/site-survey", h.HandleDeviceSiteSurveyGetByIface())
+	tr.SUB(h.r.BasePath+"internet/devices/:iface/site-survey", h.HandleDeviceAPsSubByIface())
+	tr.PUB(h.r.BasePath+"internet/devices/:iface/site-survey", h.HandleDeviceSiteSurveyPubByIface())
+	er.GET(
+		h.r.BasePath+"internet/devices/:iface/site-survey.csv",
+		h.HandleDeviceSiteSurveyExportByIface("csv"),
+	)
+	er.GET(
+		h.r.BasePath+"internet/devices/:iface/site-survey.json",
+		h.HandleDeviceSiteSurveyExportByIface("json"),
+	)
+	// device-history
+	er.GET(h.r.BasePath+"internet/devices/:iface/history", h.HandleDeviceHistoryGetByIface())
+	tr.SUB(h.r.BasePath+"internet/devices/:iface/history", sh.AllowTSSub())
+	tr.PUB(h.r.BasePath+"internet/devices/:iface/history", h.HandleDeviceHistoryPubByIface())
+	// ethernet-sharing
+	er.POST(h.r.BasePath+"internet/devices/:iface/sharing", h.HandleDeviceSharingPostByIface())
+	// checkpoints
+	er.GET(h.r.BasePath+"internet/checkpoints/:id", h.HandleCheckpointGetByID())
+	er.POST(h.r.BasePath+"internet/checkpoints/:id", h.HandleCheckpointPostByID())
+	// device-capture
+	er.POST(h.r.BasePath+"internet/devices/:iface/capture", h.HandleDeviceCapturePostByIface())
 	// conn-profiles
 	er.POST(h.r.BasePath+"internet/conn-profiles", h.HandleConnProfilesPost())
+	er.GET(h.r.BasePath+"internet/conn-profiles/new", h.HandleConnProfilesNewGet())
 	er.GET(h.r.BasePath+"internet/conn-profiles/:uuid", h.HandleConnProfileGetByUUID())
 	tr.SUB(h.r.BasePath+"internet/conn-profiles/:uuid", h.HandleConnProfileSubByUUID())
 	tr.PUB(h.r.BasePath+"internet/conn-profiles/:uuid", h.HandleConnProfilePubByUUID())
 	er.POST(h.r.BasePath+"internet/conn-profiles/:uuid", h.HandleConnProfilePostByUUID())
+	er.POST(h.r.BasePath+"internet/conn-profiles/:uuid/psk", h.HandleConnProfilePSKPostByUUID())
+	er.GET(
+		h.r.BasePath+"internet/conn-profiles/:uuid/keyfile", h.HandleConnProfileKeyfileGetByUUID(),
+	)
+	er.POST(
+		h.r.BasePath+"internet/conn-profiles/:uuid/keyfile", h.HandleConnProfileKeyfilePostByUUID(),
+	)
+	// diagnostics
+	er.GET(h.r.BasePath+"internet/diagnostics", h.HandleDiagnosticsGet())
+	er.POST(h.r.BasePath+"internet/diagnostics", h.HandleDiagnosticsPost())
+	er.GET(h.r.BasePath+"internet/diagnostics/runs/:id", h.HandleDiagnosticsRunGetByID())
+	tr.SUB(h.r.BasePath+"internet/diagnostics/runs/:id", h.HandleDiagnosticsRunSubByID())
+	tr.PUB(h.r.BasePath+"internet/diagnostics/runs/:id", h.HandleDiagnosticsRunPubByID())
+	// dns
+	er.POST(h.r.BasePath+"internet/dns", h.HandleGlobalDNSPost())
+	er.POST(h.r.BasePath+"internet/dns/hosts", h.HandleHostEntriesPost())
+	// hotspot
+	er.GET(h.r.BasePath+"internet/hotspot/qr-code.svg", h.HandleHotspotQRCodeGet("svg"))
+	er.GET(h.r.BasePath+"internet/hotspot/qr-code.png", h.HandleHotspotQRCodeGet("png"))
+	er.GET(h.r.BasePath+"internet/hotspot/label", h.HandleHotspotLabelGet())
+	er.POST(h.r.BasePath+"internet/hotspot/radio", h.HandleHotspotRadioPost())
+	// portal
+	er.GET(h.r.BasePath+"internet/portal", h.HandlePortalGet())
+	tr.SUB(h.r.BasePath+"internet/portal", sh.AllowTSSub())
+	tr.PUB(h.r.BasePath+"internet/portal", h.HandlePortalPub())
+	er.POST(h.r.BasePath+"internet/portal", h.HandlePortalPost())
+	er.GET(h.r.BasePath+"internet/portal/proxy/*", h.HandlePortalProxy())
+	er.HEAD(h.r.BasePath+"internet/portal/proxy/*", h.HandlePortalProxy())
+	er.POST(h.r.BasePath+"internet/portal/proxy/*", h.HandlePortalProxy())
+	// wireguard
+	er.POST(h.r.BasePath+"internet/wireguard", h.HandleWireGuardPost())
+	// uplinks
+	er.POST(h.r.BasePath+"internet/uplinks", h.HandleUplinksPost())
+	er.POST(h.r.BasePath+"internet/uplinks/:uuid", h.HandleUplinkPostByUUID())
+	// wifi-country
+	er.POST(h.r.BasePath+"internet/wifi-country", h.HandleWifiCountryPost())
 }
 
 func (h *Handlers) HandleInternetGet() echo.HandlerFunc {
@@ -68,7 +146,9 @@ func (h *Handlers) HandleInternetGet() echo.HandlerFunc {
 		mode := c.QueryParam("mode")
 
 		// Run queries
-		vd, err := getInternetViewData(c.Request().Context(), h.nmc)
+		vd, err := getInternetViewData(
+			c.Request().Context(), h.roles, h.nmc, h.mmc, h.scc, h.l,
+		)
 		if err != nil {
 			return err
 		}
@@ -84,78 +164,133 @@ func (h *Handlers) HandleInternetGet() echo.HandlerFunc {
 
 type InternetViewData struct {
 	NM nm.NetworkManager
+	// Roles are the network interfaces and connection profiles managed by the simplified view, with
+	// an empty interface if no device can serve the role
+	Roles conf.RolesConfig
 
-	Wlan0HotspotConnProfile      nm.ConnProfile
-	Wlan0Device                  nm.Device
-	Wlan0HotspotPasswordInsecure bool
+	HotspotConnProfile      nm.ConnProfile
+	HotspotDevice           nm.Device
+	HotspotPasswordInsecure bool
 
-	Wlan1InternetConnProfile nm.ConnProfile
-	Wlan1Device              nm.Device
-	AvailableSSIDs           []string
+	InternetConnProfile nm.ConnProfile
+	UplinkDevice        nm.Device
+	AvailableSSIDs      []string
+	// UplinkConnProfiles are the saved external Wi-Fi networks, in order of preference
+	UplinkConnProfiles []UplinkConnProfile
+
+	WifiRegDomain    WifiRegDomain
+	WifiRegDomainErr error
+	// HotspotChannels are the channels which the hotspot is allowed to use, given the regulatory
+	// domain and the capabilities of the hotspot's Wi-Fi device
+	HotspotChannels []wifireg.Channel
+	// HotspotRadioRestriction explains why the hotspot's current band and channel aren't allowed, if
+	// they aren't
+	HotspotRadioRestriction string
 
 	WifiDevices     []nm.Device
 	EthernetDevices []nm.Device
+	ModemDevices    []nm.Device
 	OtherDevices    []nm.Device
+	// Modems are the cellular modems reported by ModemManager, which also provides the devices in
+	// ModemDevices to NetworkManager
+	Modems    []mm.Modem
+	ModemsErr error
+	// HotspotClients are the devices connected to each Wi-Fi device acting as a hotspot, keyed by
+	// network interface
+	HotspotClients map[string]HotspotClients
+	// EthernetSharing describes connection sharing for each Ethernet device, keyed by network
+	// interface
+	EthernetSharing map[string]EthernetSharing
 
 	WifiConnProfiles     []nm.ConnProfileSettingsConn
 	EthernetConnProfiles []nm.ConnProfileSettingsConn
+	CellularConnProfiles []nm.ConnProfileSettingsConn
 	OtherConnProfiles    []nm.ConnProfileSettingsConn
+	WireGuardConns       []WireGuardConn
+
+	DNSOverrides DNSOverrides
 
 	IsStreamPage bool
 }
 
-func getInternetViewData(ctx context.Context, nmc *nm.Client) (vd InternetViewData, err error) {
+func getInternetViewData(
+	ctx context.Context, roles conf.RolesConfig,
+	nmc *nm.Client, mmc *mm.Client, scc *sc.Client, l godest.Logger,
+) (vd InternetViewData, err error) {
 	if vd.NM, err = nmc.Get(); err != nil {
 		return vd, errors.Wrap(err, "couldn't get overall information about NetworkManager")
 	}
 
-	const internetIface = "wlan1"
+	if err := collectDevices(ctx, roles, nmc, &vd); err != nil {
+		return vd, err
+	}
 	// Note(ethanjli): the list of APs is just for autocompletion in the simplified wifi management
-	// view, and it can be missing just after activating wlan0-hotspot; so it's fine if we don't
+	// view, and it can be missing just after activating the hotspot; so it's fine if we don't
 	// provide any data about available APs on this page:
-	availableAPs, _ := nmc.ScanNetworks(ctx, internetIface)
-	for ssid, aps := range availableAPs {
-		if len(aps) == 0 {
-			continue
+	if vd.Roles.UplinkIface != "" {
+		availableAPs, _ := nmc.ScanNetworks(ctx, vd.Roles.UplinkIface)
+		for ssid, aps := range availableAPs {
+			if len(aps) == 0 {
+				continue
+			}
+			vd.AvailableSSIDs = append(vd.AvailableSSIDs, ssid)
 		}
-		vd.AvailableSSIDs = append(vd.AvailableSSIDs, ssid)
+		slices.Sort(vd.AvailableSSIDs)
 	}
-	slices.Sort(vd.AvailableSSIDs)
 
-	if err := collectDevices(ctx, nmc, &vd); err != nil {
+	collectHotspotClients(ctx, &vd, scc, l)
+	if err := collectEthernetSharing(ctx, &vd, nmc, scc, l); err != nil {
 		return vd, err
 	}
 	if err := collectConnProfiles(ctx, nmc, &vd); err != nil {
 		return vd, err
 	}
+	if vd.UplinkConnProfiles, err = listUplinkConnProfiles(ctx, vd.Roles, nmc); err != nil {
+		return vd, err
+	}
+	if err := collectWireGuardTunnels(ctx, &vd, nmc, scc, l); err != nil {
+		return vd, err
+	}
+	collectWifiRegDomain(ctx, &vd, scc, l)
+	collectModems(ctx, &vd, mmc)
+	collectDNSOverrides(ctx, &vd, scc, l)
 
 	return vd, nil
 }
 
-func collectDevices(ctx context.Context, nmc *nm.Client, vd *InternetViewData) error {
+// collectDevices adds the network devices, and it resolves the roles of the Wi-Fi devices.
+func collectDevices(
+	ctx context.Context, roles conf.RolesConfig, nmc *nm.Client, vd *InternetViewData,
+) error {
 	allDevices, err := nmc.GetDevices(ctx)
 	if err != nil {
 		return errors.Wrap(err, "couldn't list network devices")
 	}
+	vd.Roles = resolveRoles(roles, allDevices)
 	for _, device := range allDevices {
 		switch device.Type.Info().Short {
 		default:
 			vd.OtherDevices = append(vd.OtherDevices, device)
 		case "wifi":
 			switch cmp.Or(device.IpInterface, device.ControlInterface) {
-			case "wlan0":
-				vd.Wlan0Device = device
-			case "wlan1":
-				vd.Wlan1Device = device
+			case "":
+			case vd.Roles.HotspotIface:
+				vd.HotspotDevice = device
+			case vd.Roles.UplinkIface:
+				vd.UplinkDevice = device
 			}
 			vd.WifiDevices = append(vd.WifiDevices, device)
 		case "ethernet":
 			vd.EthernetDevices = append(vd.EthernetDevices, device)
+		case "modem":
+			vd.ModemDevices = append(vd.ModemDevices, device)
 		}
 	}
 	return nil
 }
 
+// collectConnProfiles adds the connection profiles. It must be called after the devices have been
+// collected, so that the roles are resolved.
 func collectConnProfiles(ctx context.Context, nmc *nm.Client, vd *InternetViewData) error {
 	connProfiles, err := nmc.ListConnProfiles(ctx)
 	if err != nil {
@@ -166,13 +301,17 @@ func collectConnProfiles(ctx context.Context, nmc *nm.Client, vd *InternetViewDa
 		case "wifi":
 			vd.WifiConnProfiles = append(vd.WifiConnProfiles, connProfile.Settings.Conn)
 			switch conn := connProfile.Settings.Conn; conn.ID {
-			case "wlan0-hotspot":
-				vd.Wlan0HotspotConnProfile = connProfile
-			case "wlan1-internet":
-				vd.Wlan1InternetConnProfile = connProfile
+			case vd.Roles.HotspotConnProfileID:
+				vd.HotspotConnProfile = connProfile
+			case vd.Roles.InternetConnProfileID:
+				vd.InternetConnProfile = connProfile
 			}
 		case "ethernet":
 			vd.EthernetConnProfiles = append(vd.EthernetConnProfiles, connProfile.Settings.Conn)
+		case "gsm":
+			vd.CellularConnProfiles = append(vd.CellularConnProfiles, connProfile.Settings.Conn)
+		case "wireguard":
+			vd.WireGuardConns = append(vd.WireGuardConns, WireGuardConn{ConnProfile: connProfile})
 		default:
 			vd.OtherConnProfiles = append(vd.OtherConnProfiles, connProfile.Settings.Conn)
 		}
@@ -180,6 +319,16 @@ func collectConnProfiles(ctx context.Context, nmc *nm.Client, vd *InternetViewDa
 	return nil
 }
 
+// rescanPeriodically requests NetworkManager to rescan for Wi-Fi networks on the specified
+// interface at regular intervals, until the context is canceled. NetworkManager only reports newly
+// discovered networks after a scan, so pages listing available networks must trigger scans.
+func rescanPeriodically(ctx context.Context, nmc *nm.Client, iface string) error {
+	const rescanInterval = 10 * time.Second
+	return handling.RepeatImmediate(ctx, rescanInterval, func() (done bool, err error) {
+		return false, nmc.RescanNetworks(ctx, iface)
+	})
+}
+
 func (h *Handlers) HandleInternetPub() turbostreams.HandlerFunc {
 	t := "internet/index.page.tmpl"
 	h.r.MustHave(t)
@@ -192,14 +341,28 @@ func (h *Handlers) HandleInternetPub() turbostreams.HandlerFunc {
 			return errors.Wrap(err, "couldn't get query param 'mode'")
 		}
 
-		// Publish periodically
-		const pubInterval = 4 * time.Second
-		return handling.RepeatImmediate(c.Context(), pubInterval, func() (done bool, err error) {
-			// Run queries
-			if mode != sh.ViewModeAdvanced {
-				_ = h.nmc.RescanNetworks(c.Context(), "wlan0")
+		// Keep the list of available Wi-Fi networks fresh
+		if mode != sh.ViewModeAdvanced {
+			roles, err := getRoles(c.Context(), h.roles, h.nmc)
+			if err != nil {
+				return errors.Wrap(err, "couldn't determine roles of network interfaces")
+			}
+			if roles.UplinkIface != "" {
+				go func() {
+					_ = rescanPeriodically(c.Context(), h.nmc, roles.UplinkIface)
+				}()
 			}
-			vd, err := getInternetViewData(c.Context(), h.nmc)
+		}
+
+		// Publish on changes
+		// Note: we also publish periodically, since NetworkManager doesn't report changes to the
+		// devices connected to hotspots
+		changes := sh.WithPeriodicChanges(
+			c.Context(), h.nmc.Subscribe(c.Context()), hotspotClientsRefreshInterval,
+		)
+		return sh.RepeatOnChange(c.Context(), changes, func() (done bool, err error) {
+			// Run queries
+			vd, err := getInternetViewData(c.Context(), h.roles, h.nmc, h.mmc, h.scc, h.l)
 			if err != nil {
 				return false, err
 			}
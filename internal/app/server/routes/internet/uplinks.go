@@ -0,0 +1,223 @@
+package internet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// UplinkConnProfile is a connection profile for connecting the uplink interface to a known
+// external Wi-Fi network.
+type UplinkConnProfile struct {
+	ConnProfile nm.ConnProfile
+	// Active is the empty value if the connection profile isn't active
+	Active    nm.ActiveConn
+	IsFactory bool
+}
+
+// isUplinkConnProfile checks whether the connection profile connects the uplink interface to an
+// external Wi-Fi network.
+func isUplinkConnProfile(roles conf.RolesConfig, connProfile nm.ConnProfile) bool {
+	settings := connProfile.Settings
+	return roles.UplinkIface != "" &&
+		settings.Conn.Type.Info().Short == "wifi" &&
+		settings.Wifi.Mode.Info().Short == "infrastructure" &&
+		settings.Conn.InterfaceName == roles.UplinkIface
+}
+
+// listUplinkConnProfiles returns the uplink connection profiles in the order in which
+// NetworkManager prefers them for automatic connection: by descending autoconnect priority, and
+// then by most recent use. The roles must already be resolved.
+func listUplinkConnProfiles(
+	ctx context.Context, roles conf.RolesConfig, nmc *nm.Client,
+) ([]UplinkConnProfile, error) {
+	connProfiles, err := nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't list connection profiles")
+	}
+	// The active state is only shown for information, so it's fine if we can't determine it:
+	activeConns, _ := nmc.ListActiveConns()
+
+	uplinks := make([]UplinkConnProfile, 0, len(connProfiles))
+	for _, connProfile := range connProfiles {
+		if !isUplinkConnProfile(roles, connProfile) {
+			continue
+		}
+		conn := connProfile.Settings.Conn
+		uplinks = append(uplinks, UplinkConnProfile{
+			ConnProfile: connProfile,
+			Active:      activeConns[conn.UUID.String()],
+			IsFactory:   isFactoryConnProfile(roles, conn.ID),
+		})
+	}
+	slices.SortStableFunc(uplinks, func(a, b UplinkConnProfile) int {
+		aConn := a.ConnProfile.Settings.Conn
+		bConn := b.ConnProfile.Settings.Conn
+		if aConn.AutoconnectPriority != bConn.AutoconnectPriority {
+			return int(bConn.AutoconnectPriority - aConn.AutoconnectPriority)
+		}
+		return bConn.Timestamp.Compare(aConn.Timestamp)
+	})
+	return uplinks, nil
+}
+
+func (h *Handlers) HandleUplinksPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		ctx := c.Request().Context()
+		roles, err := getRoles(ctx, h.roles, h.nmc)
+		if err != nil {
+			return errors.Wrap(err, "couldn't determine roles of network interfaces")
+		}
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid uplinks state %s", state,
+			))
+		case "added":
+			if err := addUplinkConnProfile(
+				ctx, c.FormValue("802-11-wireless.ssid"),
+				c.FormValue("802-11-wireless-security.psk"), roles, h.nmc,
+			); err != nil {
+				return err // we don't wrap the error, which may be an HTTP error about invalid input
+			}
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+// addUplinkConnProfile saves a new external Wi-Fi network for the uplink interface. The new
+// network is tried after all previously-known networks. The roles must already be resolved.
+func addUplinkConnProfile(
+	ctx context.Context, ssid, psk string, roles conf.RolesConfig, nmc *nm.Client,
+) error {
+	if roles.UplinkIface == "" {
+		return echo.NewHTTPError(
+			http.StatusNotFound, "the machine has no Wi-Fi device for external Wi-Fi networks",
+		)
+	}
+	uplinks, err := listUplinkConnProfiles(ctx, roles, nmc)
+	if err != nil {
+		return err
+	}
+	const minPriority = -999
+	priority := 0
+	for _, uplink := range uplinks {
+		if bytes.Equal(uplink.ConnProfile.Settings.Wifi.SSID, []byte(ssid)) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"the Wi-Fi network %s is already saved", ssid,
+			))
+		}
+		priority = min(priority, int(uplink.ConnProfile.Settings.Conn.AutoconnectPriority)-1)
+	}
+
+	if _, err = addConnProfile(ctx, url.Values{
+		"kind":                            {"wifi"},
+		"connection.id":                   {ssid},
+		"connection.interface-name":       {roles.UplinkIface},
+		"connection.autoconnect":          {"on"},
+		"connection.autoconnect-priority": {strconv.Itoa(max(priority, minPriority))},
+		"802-11-wireless.ssid":            {ssid},
+		"802-11-wireless-security.psk":    {psk},
+	}, roles, nmc); err != nil {
+		return err // we don't wrap the error, which may be an HTTP error about invalid input
+	}
+	return nil
+}
+
+// by UUID
+
+func (h *Handlers) HandleUplinkPostByUUID() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		rawUUID := c.Param("uuid")
+		uid, err := uuid.Parse(rawUUID)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unparsable UUID %s", rawUUID))
+		}
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		ctx := c.Request().Context()
+		roles, err := getRoles(ctx, h.roles, h.nmc)
+		if err != nil {
+			return errors.Wrap(err, "couldn't determine roles of network interfaces")
+		}
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid uplink state %s", state,
+			))
+		case "raised":
+			if err := moveUplinkConnProfile(ctx, uid, -1, roles, h.nmc); err != nil {
+				return err // we don't wrap the error, which may be an HTTP error about invalid input
+			}
+		case "lowered":
+			if err := moveUplinkConnProfile(ctx, uid, 1, roles, h.nmc); err != nil {
+				return err // we don't wrap the error, which may be an HTTP error about invalid input
+			}
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+// moveUplinkConnProfile moves the uplink connection profile by the specified offset in the order
+// of preference, and then renumbers the autoconnect priorities of all uplink connection profiles
+// so that they're all distinct. Changing priorities doesn't disconnect the device, so this doesn't
+// need a checkpoint. The roles must already be resolved.
+func moveUplinkConnProfile(
+	ctx context.Context, uid uuid.UUID, offset int, roles conf.RolesConfig, nmc *nm.Client,
+) error {
+	uplinks, err := listUplinkConnProfiles(ctx, roles, nmc)
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(uplinks, func(uplink UplinkConnProfile) bool {
+		return uplink.ConnProfile.Settings.Conn.UUID == uid
+	})
+	if i < 0 {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf(
+			"connection profile %s is not a saved external Wi-Fi network", uid,
+		))
+	}
+	j := i + offset
+	if j < 0 || j >= len(uplinks) {
+		return nil // the connection profile is already at the start or end of the order
+	}
+	uplinks[i], uplinks[j] = uplinks[j], uplinks[i]
+
+	key := nm.NewConnProfileSettingsKey("connection", "autoconnect-priority")
+	for k, uplink := range uplinks {
+		conn := uplink.ConnProfile.Settings.Conn
+		priority := int32(len(uplinks) - k) //nolint:gosec // there can't be billions of profiles
+		if conn.AutoconnectPriority == priority {
+			continue
+		}
+		if err := nmc.UpdateConnProfileByUUID(
+			ctx, conn.UUID, "save", "", map[nm.ConnProfileSettingsKey]any{key: priority},
+		); err != nil {
+			return errors.Wrapf(err, "couldn't update priority of connection profile %s", conn.UUID)
+		}
+	}
+	return nil
+}
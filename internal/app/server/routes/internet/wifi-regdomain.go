@@ -0,0 +1,289 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	uc2ipc "github.com/openUC2/machine-admin/internal/app/ipc/openuc2"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+	"github.com/openUC2/machine-admin/internal/clients/wifireg"
+)
+
+// WifiRegDomain is the Wi-Fi regulatory domain which the machine currently uses.
+type WifiRegDomain struct {
+	wifireg.Domain
+	// PersistedCountry is the country which will be set when the machine boots, or an empty string if
+	// no country will be set
+	PersistedCountry string
+}
+
+// IsUnset reports whether no country has been chosen, so that the kernel only allows the channels
+// which may be used everywhere.
+func (d WifiRegDomain) IsUnset() bool {
+	return d.Country == "" || d.Country == wifireg.WorldCountry
+}
+
+func getWifiRegDomainViaSidecar(
+	ctx context.Context, scc *sc.Client, l godest.Logger,
+) (d WifiRegDomain, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return d, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	country, rules, persistedCountry, err := nmipc.GetWifiRegDomain().Call(ctx, conn)
+	if err != nil {
+		return d, errors.Wrap(err, "couldn't call sidecar's GetWifiRegDomain method")
+	}
+	d.Country = country
+	d.PersistedCountry = persistedCountry
+	d.Rules = make([]wifireg.Rule, 0, len(rules))
+	for _, rule := range rules {
+		d.Rules = append(d.Rules, wifireg.Rule{
+			StartFreq:    uint32(rule.StartFreqMHz),     //nolint:gosec // the kernel reports a u32
+			EndFreq:      uint32(rule.EndFreqMHz),       //nolint:gosec // the kernel reports a u32
+			MaxBandwidth: uint32(rule.MaxBandwidthMHz),  //nolint:gosec // the kernel reports a u32
+			MaxEIRP:      uint32(rule.MaxEIRPmBm),       //nolint:gosec // the kernel reports a u32
+			Flags:        wifireg.RuleFlags(rule.Flags), //nolint:gosec // the kernel reports a u32
+		})
+	}
+	return d, nil
+}
+
+// collectWifiRegDomain adds information about the Wi-Fi regulatory domain and the channels which it
+// allows for the hotspot. It must be called after the hotspot's device and connection profile have
+// been collected.
+func collectWifiRegDomain(
+	ctx context.Context, vd *InternetViewData, scc *sc.Client, l godest.Logger,
+) {
+	// Note: the rest of the page is still useful if the regulatory domain can't be determined (e.g.
+	// if the machine has no Wi-Fi devices), so the page should explain the error instead of failing
+	if vd.WifiRegDomain, vd.WifiRegDomainErr = getWifiRegDomainViaSidecar(
+		ctx, scc, l,
+	); vd.WifiRegDomainErr != nil {
+		return
+	}
+	caps := vd.HotspotDevice.Wifi.Caps
+	vd.HotspotChannels = vd.WifiRegDomain.APChannels(caps.Supports2GHz(), caps.Supports5GHz())
+	if wifi := vd.HotspotConnProfile.Settings.Wifi; vd.HotspotConnProfile.HasData() {
+		vd.HotspotRadioRestriction = hotspotRadioRestriction(
+			vd.WifiRegDomain.Domain, caps, string(wifi.Band), wifi.Channel,
+		)
+	}
+}
+
+// hotspotRadioRestriction explains why the hotspot may not use the band and channel (where channel
+// 0 means automatic channel selection within the band), or returns an empty string if it may.
+func hotspotRadioRestriction(
+	domain wifireg.Domain, caps nm.DeviceWifiCaps, band string, channel uint32,
+) string {
+	if band == "" {
+		if channel != 0 {
+			return "a channel can only be chosen together with a band"
+		}
+		return ""
+	}
+	bandName := nm.ConnProfileSettingsWifiBand(band).Info().Details
+	if (band != "bg" || !caps.Supports2GHz()) && (band != "a" || !caps.Supports5GHz()) {
+		return fmt.Sprintf("the hotspot's Wi-Fi module doesn't support the %s band", bandName)
+	}
+	if channel == 0 {
+		allowed := domain.APChannels(caps.Supports2GHz(), caps.Supports5GHz())
+		if !slices.ContainsFunc(allowed, func(c wifireg.Channel) bool {
+			return c.Band == band
+		}) {
+			return fmt.Sprintf(
+				"the country's regulations don't allow hotspots in the %s band", bandName,
+			)
+		}
+		return ""
+	}
+	i := slices.IndexFunc(wifireg.Channels, func(c wifireg.Channel) bool {
+		return c.Band == band && c.Number == channel
+	})
+	if i < 0 {
+		return fmt.Sprintf("channel %d isn't in the %s band", channel, bandName)
+	}
+	return domain.APRestriction(wifireg.Channels[i])
+}
+
+func (h *Handlers) HandleWifiCountryPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		state := c.FormValue("state")
+		country := strings.ToUpper(strings.TrimSpace(c.FormValue("country")))
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid Wi-Fi country state %s", state,
+			))
+		case "updated":
+			if !wifireg.ValidCountry(country) {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+					"invalid country code %s (it should be a two-letter code such as DE or US)", country,
+				))
+			}
+			persistWarning, err := setWifiCountryViaSidecar(
+				c.Request().Context(), country, c.RealIP(), h.scc, h.l,
+			)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't set Wi-Fi country to %s", country)
+			}
+			// Note: the page already explains when the current country won't be set after a reboot, so
+			// we don't need to show the warning to the user
+			if persistWarning != "" {
+				h.l.Warnf("set Wi-Fi country to %s, but couldn't persist it: %s", country, persistWarning)
+			}
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, redirectTarget)
+	}
+}
+
+// setWifiCountryViaSidecar sets the Wi-Fi country, and returns a warning if the country was set but
+// couldn't be persisted so that it will be forgotten after a reboot.
+func setWifiCountryViaSidecar(
+	ctx context.Context, country, requester string, scc *sc.Client, l godest.Logger,
+) (persistWarning string, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if persistWarning, err = nmipc.SetWifiCountry().Call(ctx, conn, country, requester); err != nil {
+		return "", errors.Wrap(err, "couldn't call sidecar's SetWifiCountry method")
+	}
+	return persistWarning, nil
+}
+
+func (h *Handlers) HandleHotspotRadioPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		band, channel, err := parseHotspotRadio(c.FormValue("radio"))
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		// We use the background context so that if the user's connection to the server is interrupted
+		// by the hotspot's restart, the operation is not interrupted by context cancellation:
+		ctx := context.Background()
+		vd := InternetViewData{}
+		if err = collectDevices(ctx, h.roles, h.nmc, &vd); err != nil {
+			return err
+		}
+		if err = collectConnProfiles(ctx, h.nmc, &vd); err != nil {
+			return err
+		}
+		if !vd.HotspotConnProfile.HasData() {
+			return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf(
+				"couldn't find the connection profile %s", vd.Roles.HotspotConnProfileID,
+			))
+		}
+		domain, err := getWifiRegDomainViaSidecar(ctx, h.scc, h.l)
+		if err != nil {
+			return errors.Wrap(err, "couldn't get Wi-Fi regulatory domain")
+		}
+		if restriction := hotspotRadioRestriction(
+			domain.Domain, vd.HotspotDevice.Wifi.Caps, band, channel,
+		); restriction != "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"the hotspot can't use the chosen band and channel: %s", restriction,
+			))
+		}
+
+		uid := vd.HotspotConnProfile.Settings.Conn.UUID
+		change := func() error {
+			if err := updateWifiBandDropInViaSidecar(
+				ctx, uid, band, channel, h.nmc, h.scc, h.l,
+			); err != nil {
+				return errors.Wrapf(err, "couldn't update band of connection profile %s", uid)
+			}
+			if err := regenerateConnProfileViaSidecar(ctx, uid, h.nmc, h.scc, h.l); err != nil {
+				return errors.Wrapf(err, "couldn't regenerate connection profile %s", uid)
+			}
+			if err := reloadConnProfileViaSidecar(ctx, uid, h.scc, h.l); err != nil {
+				return errors.Wrapf(err, "couldn't reload connection profile %s", uid)
+			}
+			// Note: the hotspot can only be restarted if its Wi-Fi module is working
+			if cmp.Or(vd.HotspotDevice.IpInterface, vd.HotspotDevice.ControlInterface) != "" {
+				if err := h.nmc.ActivateConnProfile(ctx, uid); err != nil {
+					return errors.Wrapf(err, "couldn't activate connection profile %s", uid)
+				}
+			}
+			return nil
+		}
+		// Restarting the hotspot might disconnect the user from the device (e.g. if the user's device
+		// doesn't support the new band), so we only keep the changes if the user confirms that they can
+		// still reach the device. A rollback also restores the hotspot's drop-in files.
+		checkpointID, err := checkpointedChange(ctx, change, h.scc, h.l)
+		if err != nil {
+			return err
+		}
+
+		// Redirect user
+		return c.Redirect(
+			http.StatusSeeOther, checkpointPath(h.r.BasePath, checkpointID, redirectTarget),
+		)
+	}
+}
+
+// parseHotspotRadio parses a band and channel formatted as "band:channel", where an empty band
+// means any available band and channel 0 means automatic channel selection.
+func parseHotspotRadio(raw string) (band string, channel uint32, err error) {
+	band, rawChannel, ok := strings.Cut(raw, ":")
+	if !ok {
+		return "", 0, errors.Errorf("unparsable band and channel %s", raw)
+	}
+	if band != "" && band != "a" && band != "bg" {
+		return "", 0, errors.Errorf("unknown band %s", band)
+	}
+	parsed, err := strconv.ParseUint(rawChannel, 10, 32)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "unparsable channel %s", rawChannel)
+	}
+	return band, uint32(parsed), nil
+}
+
+func updateWifiBandDropInViaSidecar(
+	ctx context.Context, uid uuid.UUID, band string, channel uint32,
+	nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	filename, err := nmc.GetConnProfileFilename(ctx, uid)
+	if err != nil {
+		return err
+	}
+	filename = strings.TrimSuffix(path.Base(filename), ".nmconnection")
+	if err := uc2ipc.UpdateWifiBandDropInFile().Call(
+		ctx, conn, filename, band, int64(channel),
+	); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's UpdateWifiBandDropInFile method")
+	}
+	return nil
+}
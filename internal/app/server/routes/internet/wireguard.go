@@ -0,0 +1,256 @@
+package internet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	nmipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/server/conf"
+	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+	"github.com/openUC2/machine-admin/internal/clients/wireguard"
+)
+
+// maxWireGuardConfigSize is the maximum size (in bytes) of WireGuard configuration files which can
+// be imported.
+const maxWireGuardConfigSize = 64 * 1024
+
+// WireGuardConn is a WireGuard connection profile, with the status of its tunnels if it's active.
+type WireGuardConn struct {
+	ConnProfile nm.ConnProfile
+	// Active is the empty value if the connection profile isn't active
+	Active nm.ActiveConn
+	// Peers are only listed if the connection profile is active
+	Peers    []WireGuardPeerStatus
+	PeersErr error
+}
+
+// WireGuardPeerStatus describes the tunnel to a peer of an active WireGuard connection.
+type WireGuardPeerStatus struct {
+	PublicKey  string
+	Endpoint   string
+	AllowedIPs []string
+	// LastHandshake is the zero value if no handshake has happened yet
+	LastHandshake time.Time
+	RxBytes       int64
+	TxBytes       int64
+}
+
+// collectWireGuardTunnels adds the status of the tunnels of the active WireGuard connections.
+func collectWireGuardTunnels(
+	ctx context.Context, vd *InternetViewData, nmc *nm.Client, scc *sc.Client, l godest.Logger,
+) error {
+	if len(vd.WireGuardConns) == 0 {
+		return nil
+	}
+	activeConns, err := nmc.ListActiveConns()
+	if err != nil {
+		return errors.Wrap(err, "couldn't list active connections")
+	}
+	for i, conn := range vd.WireGuardConns {
+		conn.Active = activeConns[conn.ConnProfile.Settings.Conn.UUID.String()]
+		if conn.Active.HasData() {
+			iface := conn.ConnProfile.Settings.Conn.InterfaceName
+			if len(conn.Active.DeviceInterfaces) > 0 {
+				iface = conn.Active.DeviceInterfaces[0]
+			}
+			// Note: the rest of the page is still useful if the tunnel statistics can't be determined,
+			// so the page should explain the error instead of failing
+			conn.Peers, conn.PeersErr = listWireGuardPeersViaSidecar(ctx, iface, scc, l)
+		}
+		vd.WireGuardConns[i] = conn
+	}
+	return nil
+}
+
+func listWireGuardPeersViaSidecar(
+	ctx context.Context, iface string, scc *sc.Client, l godest.Logger,
+) (peers []WireGuardPeerStatus, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawPeers, err := nmipc.ListWireGuardPeers().Call(ctx, conn, iface)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't call sidecar's ListWireGuardPeers method")
+	}
+	peers = make([]WireGuardPeerStatus, 0, len(rawPeers))
+	for _, rawPeer := range rawPeers {
+		peer := WireGuardPeerStatus{
+			PublicKey:  rawPeer.PublicKey,
+			Endpoint:   rawPeer.Endpoint,
+			AllowedIPs: rawPeer.AllowedIPs,
+			RxBytes:    rawPeer.RxBytes,
+			TxBytes:    rawPeer.TxBytes,
+		}
+		if rawPeer.LastHandshake > 0 {
+			peer.LastHandshake = time.Unix(rawPeer.LastHandshake, 0)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+// Importing
+
+func (h *Handlers) HandleWireGuardPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		file, err := c.FormFile("upload:config")
+		if err != nil {
+			return echo.NewHTTPError(
+				http.StatusBadRequest, "no WireGuard configuration file was uploaded",
+			)
+		}
+		if file.Size > maxWireGuardConfigSize {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"WireGuard configuration file must not be larger than %d bytes",
+				maxWireGuardConfigSize,
+			))
+		}
+		stem := strings.TrimSuffix(path.Base(file.Filename), path.Ext(file.Filename))
+		id := cmp.Or(strings.TrimSpace(c.FormValue("connection.id")), stem)
+		iface := cmp.Or(strings.TrimSpace(c.FormValue("connection.interface-name")), stem)
+
+		// Run queries
+		f, err := file.Open()
+		if err != nil {
+			return errors.Wrap(err, "couldn't open uploaded WireGuard configuration file")
+		}
+		config, err := wireguard.ParseConfig(f)
+		if cerr := f.Close(); cerr != nil {
+			h.l.Error(errors.Wrap(cerr, "couldn't close uploaded WireGuard configuration file"))
+		}
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid WireGuard configuration file: %s", err,
+			))
+		}
+		if len(config.Ignored) > 0 {
+			h.l.Warnf(
+				"ignored settings only supported by wg-quick in WireGuard configuration %s: %s",
+				file.Filename, strings.Join(config.Ignored, ", "),
+			)
+		}
+		settings, err := wireGuardConnProfileSettings(id, iface, config, h.roles)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		uid, err := h.nmc.AddConnProfile(c.Request().Context(), "save", settings)
+		if err != nil {
+			return errors.Wrap(err, "couldn't add WireGuard connection profile")
+		}
+
+		// Redirect user
+		return c.Redirect(http.StatusSeeOther, fmt.Sprintf(
+			"%sinternet/conn-profiles/%s?mode=%s", h.r.BasePath, uid, sh.ViewModeAdvanced,
+		))
+	}
+}
+
+// interfaceNamePattern matches the network interface names which Linux accepts.
+var interfaceNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,15}$`)
+
+// wireGuardConnProfileSettings returns the settings for a new connection profile with the
+// WireGuard configuration. The tunnel is not activated automatically, so that it's only used when
+// the user turns it on.
+func wireGuardConnProfileSettings(
+	id, iface string, config wireguard.Config, roles conf.RolesConfig,
+) (settings map[nm.ConnProfileSettingsKey]any, err error) {
+	if id == "" {
+		return nil, errors.New("the connection profile must have a name")
+	}
+	if isFactoryConnProfile(roles, id) {
+		return nil, errors.Errorf("the name %s is reserved for a built-in connection profile", id)
+	}
+	if !interfaceNamePattern.MatchString(iface) {
+		return nil, errors.Errorf(
+			"invalid network interface name %s (it must have at most 15 letters, digits, or _.-)",
+			iface,
+		)
+	}
+
+	key := nm.NewConnProfileSettingsKey
+	settings = map[nm.ConnProfileSettingsKey]any{
+		key("connection", "id"):             id,
+		key("connection", "type"):           nm.ConnProfileSettingsConnType("wireguard"),
+		key("connection", "interface-name"): iface,
+		key("connection", "autoconnect"):    false,
+		key("wireguard", "private-key"):     config.PrivateKey,
+		key("wireguard", "peer-routes"):     !config.NoPeerRoutes,
+	}
+	if config.ListenPort > 0 {
+		settings[key("wireguard", "listen-port")] = config.ListenPort
+	}
+	if config.FwMark > 0 {
+		settings[key("wireguard", "fwmark")] = config.FwMark
+	}
+	if config.MTU > 0 {
+		settings[key("wireguard", "mtu")] = config.MTU
+	}
+	peers := make([]nm.WireGuardPeer, 0, len(config.Peers))
+	for _, peer := range config.Peers {
+		peers = append(peers, nm.WireGuardPeer{
+			PublicKey:           peer.PublicKey,
+			Endpoint:            peer.Endpoint,
+			AllowedIPs:          peer.AllowedIPs,
+			PersistentKeepalive: peer.PersistentKeepalive,
+			PresharedKey:        peer.PresharedKey,
+		})
+	}
+	settings[key("wireguard", "peers")] = peers
+
+	var addresses4, addresses6 []nm.IPAddress
+	for _, address := range config.Addresses {
+		if address.Addr().Is4() {
+			addresses4 = append(addresses4, nm.IPAddress{Prefix: address})
+			continue
+		}
+		addresses6 = append(addresses6, nm.IPAddress{Prefix: address})
+	}
+	var dns4, dns6 []netip.Addr
+	for _, server := range config.DNS {
+		if server.Is4() {
+			dns4 = append(dns4, server)
+			continue
+		}
+		dns6 = append(dns6, server)
+	}
+	settings[key("ipv4", "method")] = nm.ConnProfileSettingsIPv4Method("disabled")
+	if len(addresses4) > 0 {
+		settings[key("ipv4", "method")] = nm.ConnProfileSettingsIPv4Method("manual")
+		settings[key("ipv4", "address-data")] = addresses4
+		if len(dns4) > 0 {
+			settings[key("ipv4", "dns")] = dns4
+		}
+		if len(config.DNSSearch) > 0 {
+			settings[key("ipv4", "dns-search")] = config.DNSSearch
+		}
+	}
+	settings[key("ipv6", "method")] = nm.ConnProfileSettingsIPv6Method("disabled")
+	if len(addresses6) > 0 {
+		settings[key("ipv6", "method")] = nm.ConnProfileSettingsIPv6Method("manual")
+		settings[key("ipv6", "address-data")] = addresses6
+		if len(dns6) > 0 {
+			settings[key("ipv6", "dns")] = dns6
+		}
+		if len(config.DNSSearch) > 0 && len(addresses4) == 0 {
+			settings[key("ipv6", "dns-search")] = config.DNSSearch
+		}
+	}
+	return settings, nil
+}
@@ -16,18 +16,24 @@ import (
 	"github.com/sargassum-world/godest/turbostreams"
 
 	sh "github.com/openUC2/machine-admin/internal/app/server/handling"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
 	ts "github.com/openUC2/machine-admin/internal/clients/tailscale"
 )
 
 type Handlers struct {
 	r   godest.TemplateRenderer
 	tsc *ts.Client
+	scc *sc.Client
+
+	l godest.Logger
 }
 
-func New(r godest.TemplateRenderer, tsc *ts.Client) *Handlers {
+func New(r godest.TemplateRenderer, tsc *ts.Client, scc *sc.Client, l godest.Logger) *Handlers {
 	return &Handlers{
 		r:   r,
 		tsc: tsc,
+		scc: scc,
+		l:   l,
 	}
 }
 
@@ -37,6 +43,9 @@ func (h *Handlers) Register(er godest.EchoRouter, tr turbostreams.Router) error
 	tr.PUB(h.r.BasePath+"remote", h.HandleRemotePub())
 	// assistance
 	er.POST(h.r.BasePath+"remote/assistance", h.HandleAssistancePost())
+	// terminal
+	er.GET(h.r.BasePath+"remote/terminal", h.HandleTerminalGet())
+	er.POST(h.r.BasePath+"remote/terminal", h.HandleTerminalPost())
 	// tailscale
 	tsws, err := h.tsc.InitWebServer(h.r.BasePath + "remote/tailscale")
 	if err != nil {
@@ -66,7 +75,7 @@ func (h *Handlers) HandleRemoteGet() echo.HandlerFunc {
 	h.r.MustHave(t)
 	return func(c echo.Context) error {
 		// Run queries
-		remoteViewData, err := getRemoteViewData(c.Request().Context(), h.tsc)
+		remoteViewData, err := getRemoteViewData(c.Request().Context(), h.tsc, h.scc, h.l)
 		if err != nil {
 			return err
 		}
@@ -85,10 +94,17 @@ type RemoteViewData struct {
 	// KeyExpiration time.Time
 	NetworkName string
 
+	TerminalEnabledUntil time.Time
+	// TerminalErr explains why the support terminal's status couldn't be determined (e.g. because
+	// the sidecar isn't running)
+	TerminalErr error
+
 	IsStreamPage bool
 }
 
-func getRemoteViewData(ctx context.Context, tc *ts.Client) (vd RemoteViewData, err error) {
+func getRemoteViewData(
+	ctx context.Context, tc *ts.Client, scc *sc.Client, l godest.Logger,
+) (vd RemoteViewData, err error) {
 	status, err := tc.GetStatus(ctx)
 	if err != nil {
 		return vd, errors.Wrap(err, "couldn't get tailscale daemon status")
@@ -114,6 +130,12 @@ func getRemoteViewData(ctx context.Context, tc *ts.Client) (vd RemoteViewData, e
 		vd.NetworkName = tailnet.Name
 	}
 
+	// Note: the rest of the page is still useful if the sidecar is unavailable, so the page should
+	// explain that the terminal is unavailable instead of failing
+	if vd.TerminalEnabledUntil, err = getTerminalStatusViaSidecar(ctx, scc, l); err != nil {
+		vd.TerminalErr = errors.Wrap(err, "couldn't get terminal status through sidecar")
+	}
+
 	return vd, nil
 }
 
@@ -125,7 +147,7 @@ func (h *Handlers) HandleRemotePub() turbostreams.HandlerFunc {
 		const pubInterval = 4 * time.Second
 		return handling.RepeatImmediate(c.Context(), pubInterval, func() (done bool, err error) {
 			// Run queries
-			vd, err := getRemoteViewData(c.Context(), h.tsc)
+			vd, err := getRemoteViewData(c.Context(), h.tsc, h.scc, h.l)
 			if err != nil {
 				return false, err
 			}
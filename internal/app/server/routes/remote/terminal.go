@@ -0,0 +1,121 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/terminal"
+	sc "github.com/openUC2/machine-admin/internal/clients/sidecar"
+)
+
+func (h *Handlers) HandleTerminalGet() echo.HandlerFunc {
+	t := "remote/terminal.page.tmpl"
+	h.r.MustHave(t)
+	return func(c echo.Context) error {
+		// Run queries
+		enabledUntil, err := getTerminalStatusViaSidecar(c.Request().Context(), h.scc, h.l)
+		if err != nil {
+			return errors.Wrap(err, "couldn't get terminal status through sidecar")
+		}
+		// Produce output
+		return h.r.CacheablePage(c.Response(), c.Request(), t, TerminalViewData{
+			EnabledUntil: enabledUntil,
+		}, struct{}{})
+	}
+}
+
+type TerminalViewData struct {
+	EnabledUntil time.Time
+}
+
+func getTerminalStatusViaSidecar(
+	ctx context.Context, scc *sc.Client, l godest.Logger,
+) (enabledUntil time.Time, err error) {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	rawEnabledUntil, err := ipc.GetStatus().Call(ctx, conn)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "couldn't call sidecar's GetStatus method")
+	}
+	if rawEnabledUntil == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(rawEnabledUntil, 0), nil
+}
+
+func (h *Handlers) HandleTerminalPost() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		// Parse params
+		state := c.FormValue("state")
+		redirectTarget := c.FormValue("redirect-target")
+
+		// Run queries
+		ctx := c.Request().Context()
+		switch state {
+		default:
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+				"invalid terminal state %s", state,
+			))
+		case "enabled":
+			rawDuration := c.FormValue("duration")
+			durationMin, err := strconv.ParseInt(rawDuration, 10, 64)
+			if err != nil || durationMin <= 0 {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(
+					"invalid duration %s", rawDuration,
+				))
+			}
+			if err := enableTerminalViaSidecar(
+				ctx, time.Duration(durationMin)*time.Minute, h.scc, h.l,
+			); err != nil {
+				return errors.Wrap(err, "couldn't enable terminal through sidecar")
+			}
+			// Redirect user
+			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		case "disabled":
+			if err := disableTerminalViaSidecar(ctx, h.scc, h.l); err != nil {
+				return errors.Wrap(err, "couldn't disable terminal through sidecar")
+			}
+			// Redirect user
+			return c.Redirect(http.StatusSeeOther, redirectTarget)
+		}
+	}
+}
+
+func enableTerminalViaSidecar(
+	ctx context.Context, duration time.Duration, scc *sc.Client, l godest.Logger,
+) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if _, err := ipc.Enable().Call(ctx, conn, int64(duration.Seconds())); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's Enable method")
+	}
+	return nil
+}
+
+func disableTerminalViaSidecar(ctx context.Context, scc *sc.Client, l godest.Logger) error {
+	conn, err := scc.Open(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't open connection to sidecar")
+	}
+	defer sc.CloseConn(conn, l)
+
+	if err := ipc.Disable().Call(ctx, conn); err != nil {
+		return errors.Wrap(err, "couldn't call sidecar's Disable method")
+	}
+	return nil
+}
@@ -27,7 +27,8 @@ type Handlers struct {
 	r       godest.TemplateRenderer
 	globals *client.Globals
 
-	remote *remote.Handlers
+	internet *internet.Handlers
+	remote   *remote.Handlers
 }
 
 func New(r godest.TemplateRenderer, globals *client.Globals) *Handlers {
@@ -45,12 +46,16 @@ func (h *Handlers) Register(er godest.EchoRouter, tsr turbostreams.Router, em go
 	assets.NewTemplated(h.r).Register(er)
 	boot.New(h.r, h.globals.Sidecar, l).Register(er)
 	cable.New(
-		h.r, h.globals.Base.ACSigner, h.globals.Base.TSBroker, l,
+		h.r, h.globals.Base.ACSigner, h.globals.Base.TSBroker, h.globals.Sidecar, l,
 	).Register(er)
 	home.New(h.r, h.globals.Identity, h.globals.Versioning, h.globals.Tailscale, l).Register(er, tsr)
 	identity.New(h.r).Register(er)
-	internet.New(h.r, tsh, h.globals.NetworkManager, h.globals.Sidecar, l).Register(er, tsr)
-	h.remote = remote.New(h.r, h.globals.Tailscale)
+	h.internet = internet.New(
+		h.r, h.globals.Config.Roles, tsh, h.globals.NetworkManager, h.globals.ModemManager,
+		h.globals.Diagnostics, h.globals.Sidecar, l,
+	)
+	h.internet.Register(er, tsr)
+	h.remote = remote.New(h.r, h.globals.Tailscale, h.globals.Sidecar, l)
 	if err := h.remote.Register(er, tsr); err != nil {
 		return errors.Wrap(err, "couldn't register handlers for remote routes")
 	}
@@ -93,7 +98,11 @@ func (h *Handlers) HandleRefreshPub() turbostreams.HandlerFunc {
 }
 
 func (h *Handlers) TrailingSlashSkipper(c echo.Context) bool {
-	return h.remote.TrailingSlashSkipper(c)
+	return h.remote.TrailingSlashSkipper(c) || h.internet.TrailingSlashSkipper(c)
+}
+
+func (h *Handlers) CSRFSkipper(c echo.Context) bool {
+	return h.internet.CSRFSkipper(c)
 }
 
 func (h *Handlers) GzipSkipper(c echo.Context) bool {
@@ -177,10 +177,21 @@ func (s *Server) Register(e *echo.Echo) error {
 	e.Pre(middleware.RemoveTrailingSlashWithConfig(middleware.TrailingSlashConfig{
 		Skipper: s.Handlers.TrailingSlashSkipper,
 	}))
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if s.Handlers.CSRFSkipper(c) {
+				c.SetRequest(csrf.UnsafeSkipCheck(c.Request()))
+			}
+			return next(c)
+		}
+	})
 	e.Use(echo.WrapMiddleware(
 		csrf.Protect(nil, csrf.ErrorHandler(NewCSRFErrorHandler(s.Renderer, e.Logger)))))
-	// application/JSON is needed by the Tailscale web GUI:
-	e.Use(gmw.RequireContentTypes(echo.MIMEApplicationForm, echo.MIMEApplicationJSON))
+	// application/JSON is needed by the Tailscale web GUI, and multipart/form-data is needed for
+	// file uploads:
+	e.Use(gmw.RequireContentTypes(
+		echo.MIMEApplicationForm, echo.MIMEApplicationJSON, echo.MIMEMultipartForm,
+	))
 	// TODO: enable Prometheus and rate-limiting
 
 	// Handlers
@@ -238,6 +249,14 @@ func (s *Server) runWorkersInContext(ctx context.Context) error {
 		}
 		return nil
 	})
+	eg.Go(func() error {
+		if err := s.Globals.ModemManager.Open(ctx); err != nil {
+			s.Globals.Base.Logger.Error("couldn't open ModemManager client")
+			// Even if ModemManager is unavailable, other parts of machine-admin are still useful, so we
+			// don't propagate the error from here
+		}
+		return nil
+	})
 	eg.Go(func() error {
 		if err := s.Globals.UDisks2.Open(ctx); err != nil {
 			s.Globals.Base.Logger.Error("couldn't open UDisks2 client")
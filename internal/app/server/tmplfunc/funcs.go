@@ -14,6 +14,7 @@ func FuncMap(h HashedNamers, tss TurboStreamSigner) template.FuncMap {
 		"appHashed":       h.AppHashed,
 		"staticHashed":    h.StaticHashed,
 		"isIPAddr":        IsIPAddr,
+		"formatBytes":     FormatBytes,
 		"signTurboStream": tss,
 	}
 }
@@ -5,13 +5,17 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sargassum-world/godest"
 
+	"github.com/openUC2/machine-admin/internal/clients/audit"
 	"github.com/openUC2/machine-admin/internal/clients/networkmanager"
 	"github.com/openUC2/machine-admin/internal/clients/systemd"
+	"github.com/openUC2/machine-admin/internal/clients/terminal"
 )
 
 // Server
 
 type BaseGlobals struct {
+	Audit *audit.Client
+
 	Logger godest.Logger
 }
 
@@ -20,11 +24,18 @@ type Globals struct {
 
 	Systemd        *systemd.Client
 	NetworkManager *networkmanager.Client
+	Terminal       *terminal.Client
 }
 
 func NewBaseGlobals(l godest.Logger) (g *BaseGlobals, err error) {
 	g = &BaseGlobals{}
 
+	auditConfig, err := audit.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't set up audit config")
+	}
+	g.Audit = audit.NewClient(auditConfig, l)
+
 	g.Logger = l
 	return g, nil
 }
@@ -37,6 +48,11 @@ func NewGlobals(l godest.Logger) (g *Globals, err error) {
 
 	g.Systemd = systemd.NewClient(systemd.Config{}, g.Base.Logger)
 	g.NetworkManager = networkmanager.NewClient(networkmanager.Config{}, g.Base.Logger)
+	terminalConfig, err := terminal.GetConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't set up terminal config")
+	}
+	g.Terminal = terminal.NewClient(terminalConfig, g.Base.Audit, g.Base.Logger)
 
 	return g, nil
 }
@@ -0,0 +1,164 @@
+package networkmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/packetcapture"
+)
+
+const (
+	maxCaptureDuration = 5 * time.Minute
+	maxCaptureBytes    = 64 * 1024 * 1024
+)
+
+func (h *Handlers) CapturePackets(
+	ctx context.Context, call ipc.VarlinkCall,
+	iface string, maxDurationSec int64, maxBytes int64, rawFilter string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate options
+	if maxDurationSec <= 0 || time.Duration(maxDurationSec)*time.Second > maxCaptureDuration {
+		return call.ReplyInvalidCaptureOptions(ctx, fmt.Sprintf(
+			"capture duration must be between 1 and %d seconds", int(maxCaptureDuration.Seconds()),
+		))
+	}
+	if maxBytes <= 0 || maxBytes > maxCaptureBytes {
+		return call.ReplyInvalidCaptureOptions(ctx, fmt.Sprintf(
+			"capture size must be between 1 and %d bytes", maxCaptureBytes,
+		))
+	}
+	filter, err := packetcapture.ParseFilter(rawFilter)
+	if err != nil {
+		return call.ReplyInvalidCaptureOptions(ctx, err.Error())
+	}
+	if _, err = h.nmc.GetDeviceByIface(ctx, iface); err != nil {
+		return call.ReplyInvalidCaptureOptions(ctx, err.Error())
+	}
+
+	// Capture packets
+	captureCtx, cancelCapture := context.WithCancel(ctx)
+	defer cancelCapture()
+	w := &captureChunkWriter{
+		ctx:    ctx,
+		call:   &call,
+		stream: call.WantsMore(),
+	}
+	flushed := make(chan struct{})
+	if w.stream {
+		go w.flushPeriodically(captureCtx, cancelCapture, flushed)
+	} else {
+		close(flushed)
+	}
+	err = packetcapture.Capture(captureCtx, iface, packetcapture.Options{
+		MaxDuration: time.Duration(maxDurationSec) * time.Second,
+		MaxBytes:    maxBytes,
+		Filter:      filter,
+	}, w)
+	cancelCapture()
+	<-flushed
+	if w.err != nil {
+		// The client stopped receiving the capture (e.g. because the user's browser disconnected from
+		// the server), so there's no one to reply to
+		h.l.Warnf("stopped capturing packets on %s early: %s", iface, w.err)
+		return nil
+	}
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't capture packets on %s", iface,
+		), h.l)
+	}
+	return w.Close()
+}
+
+// captureChunkWriter sends the written data as a sequence of chunks in streaming replies, if the
+// client accepts more than one reply; otherwise, it buffers all data for a single final reply.
+type captureChunkWriter struct {
+	ctx    context.Context
+	call   *ipc.VarlinkCall
+	stream bool
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+	// err is the error from the first failed reply, after which no more replies can be sent
+	err error
+}
+
+const (
+	captureChunkSize = 32 * 1024
+	// captureFlushInterval is how often streamed chunks are sent even if they're smaller than
+	// captureChunkSize, so that captures of quiet links still show progress, and so that we notice
+	// soon when the client stops receiving the capture
+	captureFlushInterval = time.Second
+)
+
+func (w *captureChunkWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, _ = w.buf.Write(p)
+	if !w.stream || w.buf.Len() < captureChunkSize {
+		return n, nil
+	}
+	return n, w.flush(true)
+}
+
+// flushPeriodically sends the buffered data (which may be empty) as a streamed chunk at every
+// captureFlushInterval until the context is canceled, and cancels the capture if a chunk can't be
+// sent. It closes the done channel when it returns.
+func (w *captureChunkWriter) flushPeriodically(
+	ctx context.Context, cancelCapture context.CancelFunc, done chan<- struct{},
+) {
+	defer close(done)
+	ticker := time.NewTicker(captureFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			err := w.flush(true)
+			w.mu.Unlock()
+			if err != nil {
+				cancelCapture()
+				return
+			}
+		}
+	}
+}
+
+// flush sends the buffered data as a reply. It must be called while holding the mutex.
+func (w *captureChunkWriter) flush(continues bool) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.call.Continues = continues
+	if err := w.call.ReplyCapturePackets(
+		w.ctx, base64.StdEncoding.EncodeToString(w.buf.Bytes()),
+	); err != nil {
+		w.err = errors.Wrap(err, "couldn't send captured packets")
+		return w.err
+	}
+	w.buf.Reset()
+	return nil
+}
+
+func (w *captureChunkWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.flush(false)
+}
@@ -0,0 +1,160 @@
+package networkmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+const (
+	certsDir           = nm.CertsDir
+	maxCertificateSize = 64 * 1024
+)
+
+var certificateKinds = []string{"ca-cert", "client-cert", "private-key"}
+
+func (h *Handlers) StoreCertificate(
+	ctx context.Context, call ipc.VarlinkCall, rawUUID, kind, rawData string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return call.ReplyInvalidUUID(ctx, fmt.Sprintf("couldn't parse uuid %s", rawUUID))
+	}
+	if !slices.Contains(certificateKinds, kind) {
+		return call.ReplyInvalidCertificate(ctx, fmt.Sprintf(
+			"kind %s must be one of %s", kind, strings.Join(certificateKinds, ", "),
+		))
+	}
+	data, err := base64.StdEncoding.DecodeString(rawData)
+	if err != nil {
+		return call.ReplyInvalidCertificate(ctx, "file is not base64-encoded")
+	}
+	if len(data) > maxCertificateSize {
+		return call.ReplyInvalidCertificate(ctx, fmt.Sprintf(
+			"file must not be larger than %d bytes", maxCertificateSize,
+		))
+	}
+	if kind == "private-key" {
+		err = checkPrivateKey(data)
+	} else {
+		err = checkCertificates(data)
+	}
+	if err != nil {
+		return call.ReplyInvalidCertificate(ctx, err.Error())
+	}
+
+	// Store file
+	filePath, err := storeCertificate(uid, kind, data)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	return call.ReplyStoreCertificate(ctx, filePath)
+}
+
+// checkCertificates checks that the data consists of one or more X.509 certificates, in either
+// PEM or DER format.
+func checkCertificates(data []byte) error {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		if _, err := x509.ParseCertificates(data); err != nil {
+			return errors.Wrap(err, "file is neither a PEM-encoded nor a DER-encoded certificate")
+		}
+		return nil
+	}
+	found := false
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return errors.Wrap(err, "couldn't parse certificate")
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("file has no PEM-encoded certificates")
+	}
+	return nil
+}
+
+// checkPrivateKey checks that the data has a PEM-encoded private key, which may be encrypted.
+func checkPrivateKey(data []byte) error {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			return nil
+		}
+	}
+	return errors.New("file has no PEM-encoded private key")
+}
+
+func storeCertificate(uid uuid.UUID, kind string, data []byte) (filePath string, err error) {
+	const dirMode = 0o700 // drwx------
+	if err = os.MkdirAll(certsDir, dirMode); err != nil {
+		return "", errors.Wrapf(err, "couldn't make certificates directory %s", certsDir)
+	}
+	fsys, err := os.OpenRoot(certsDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't open certificates directory %s", certsDir)
+	}
+	defer func() {
+		if cerr := fsys.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "couldn't close certificates directory %s", certsDir)
+		}
+	}()
+
+	if err = fsys.MkdirAll(uid.String(), dirMode); err != nil {
+		return "", errors.Wrapf(err, "couldn't make certificates directory for %s", uid)
+	}
+	// Note: the server only accepts file paths which match nm.StoredCertPath
+	fileName := path.Join(uid.String(), kind+".der")
+	if bytes.Contains(data, []byte("-----BEGIN")) {
+		fileName = path.Join(uid.String(), kind+".pem")
+	}
+	// We replace any file previously stored for this kind of certificate, which might have had a
+	// different file extension:
+	for _, ext := range []string{".pem", ".der"} {
+		err = fsys.Remove(path.Join(uid.String(), kind+ext))
+		if err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrapf(err, "couldn't remove previous %s file for %s", kind, uid)
+		}
+	}
+	const fileMode = 0o600 // -rw-------
+	if err = fsys.WriteFile(fileName, data, fileMode); err != nil {
+		return "", errors.Wrapf(err, "couldn't write %s file for %s", kind, uid)
+	}
+	return path.Join(certsDir, fileName), nil
+}
+
+func (h *Handlers) DeleteCertificates(
+	ctx context.Context, call ipc.VarlinkCall, rawUUID string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return call.ReplyInvalidUUID(ctx, fmt.Sprintf("couldn't parse uuid %s", rawUUID))
+	}
+	dirPath := path.Join(certsDir, uid.String())
+	if err = os.RemoveAll(dirPath); err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't delete certificates directory %s", dirPath,
+		), h.l)
+	}
+	return call.ReplyDeleteCertificates(ctx)
+}
@@ -0,0 +1,231 @@
+package networkmanager
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+const maxCheckpointRollbackTimeout = 10 * time.Minute
+
+// dropInsDir holds the drop-in files from which some connection profiles (e.g. the hotspot's) are
+// assembled. NetworkManager's checkpoints don't cover these files, so we snapshot them together
+// with each checkpoint and restore them whenever NetworkManager rolls back to the checkpoint.
+const dropInsDir = "/etc/NetworkManager/system-connections.d"
+
+// dropInsRestoreRetryInterval is how often we check whether NetworkManager has rolled back to an
+// expired checkpoint, if it hasn't done so yet when the checkpoint's rollback timeout elapses.
+const dropInsRestoreRetryInterval = time.Second
+
+func (h *Handlers) CreateCheckpoint(
+	ctx context.Context, call ipc.VarlinkCall, rollbackTimeoutSec int64,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	rollbackTimeout := time.Duration(rollbackTimeoutSec) * time.Second
+	if rollbackTimeout <= 0 || rollbackTimeout > maxCheckpointRollbackTimeout {
+		return handling.ReportUnknownError(ctx, &call, errors.Errorf(
+			"rollback timeout must be between 1 and %d seconds",
+			int(maxCheckpointRollbackTimeout.Seconds()),
+		), h.l)
+	}
+	snapshot, err := snapshotDropIns()
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	id, err := h.nmc.CreateCheckpoint(ctx, rollbackTimeout)
+	if errors.Is(err, nm.ErrCheckpointExists) {
+		return call.ReplyCheckpointExists(ctx, err.Error())
+	}
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+
+	h.checkpointsMu.Lock()
+	defer h.checkpointsMu.Unlock()
+	h.dropInSnapshots[id] = snapshot
+	// NetworkManager rolls back to the checkpoint by itself once the rollback timeout elapses, without
+	// notifying us, so we must restore the drop-in files at around the same time:
+	time.AfterFunc(rollbackTimeout, func() {
+		h.restoreExpiredDropIns(id)
+	})
+	return call.ReplyCreateCheckpoint(ctx, id)
+}
+
+func (h *Handlers) DestroyCheckpoint(ctx context.Context, call ipc.VarlinkCall, id string) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// We discard the snapshot before destroying the checkpoint, so that the drop-in files can't be
+	// restored if the checkpoint's rollback timeout elapses while it's being destroyed:
+	snapshot, hasSnapshot := h.takeDropInsSnapshot(id)
+	if err := h.nmc.DestroyCheckpoint(ctx, id); err != nil {
+		if hasSnapshot {
+			h.putDropInsSnapshot(id, snapshot)
+		}
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	return call.ReplyDestroyCheckpoint(ctx)
+}
+
+func (h *Handlers) RollbackCheckpoint(ctx context.Context, call ipc.VarlinkCall, id string) error {
+	handling.LogMethod(call.Request, h.l)
+
+	snapshot, hasSnapshot := h.takeDropInsSnapshot(id)
+	if err := h.nmc.RollbackCheckpoint(ctx, id); err != nil {
+		if hasSnapshot {
+			h.putDropInsSnapshot(id, snapshot)
+		}
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	if hasSnapshot {
+		if err := snapshot.restore(); err != nil {
+			return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+				err, "couldn't restore drop-in files of checkpoint %s", id,
+			), h.l)
+		}
+	}
+	return call.ReplyRollbackCheckpoint(ctx)
+}
+
+func (h *Handlers) takeDropInsSnapshot(id string) (snapshot dropInsSnapshot, ok bool) {
+	h.checkpointsMu.Lock()
+	defer h.checkpointsMu.Unlock()
+
+	snapshot, ok = h.dropInSnapshots[id]
+	delete(h.dropInSnapshots, id)
+	return snapshot, ok
+}
+
+func (h *Handlers) putDropInsSnapshot(id string, snapshot dropInsSnapshot) {
+	h.checkpointsMu.Lock()
+	defer h.checkpointsMu.Unlock()
+
+	h.dropInSnapshots[id] = snapshot
+}
+
+// restoreExpiredDropIns restores the drop-in files of the checkpoint once NetworkManager has
+// automatically rolled back to it, unless the checkpoint was destroyed or rolled back through us.
+func (h *Handlers) restoreExpiredDropIns(id string) {
+	h.checkpointsMu.Lock()
+	_, pending := h.dropInSnapshots[id]
+	h.checkpointsMu.Unlock()
+	if !pending {
+		return
+	}
+
+	ids, err := h.nmc.ListCheckpointIDs()
+	if err != nil || slices.Contains(ids, id) {
+		// NetworkManager hasn't rolled back yet, or we can't tell whether it has
+		time.AfterFunc(dropInsRestoreRetryInterval, func() {
+			h.restoreExpiredDropIns(id)
+		})
+		return
+	}
+	snapshot, ok := h.takeDropInsSnapshot(id)
+	if !ok {
+		return
+	}
+	if err = snapshot.restore(); err != nil {
+		h.l.Error(errors.Wrapf(err, "couldn't restore drop-in files of expired checkpoint %s", id))
+		return
+	}
+	h.l.Infof("restored drop-in files of expired checkpoint %s", id)
+}
+
+// Drop-in snapshots
+
+// dropInsSnapshot maps the paths (relative to dropInsDir) of drop-in files to their contents.
+type dropInsSnapshot map[string]dropInFile
+
+type dropInFile struct {
+	data []byte
+	mode fs.FileMode
+}
+
+func snapshotDropIns() (dropInsSnapshot, error) {
+	snapshot := make(dropInsSnapshot)
+	fsys := os.DirFS(dropInsDir)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Note: swap files are only left behind by interrupted writes, so they're not drop-in files
+		if !d.Type().IsRegular() || strings.HasSuffix(p, ".swp") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return errors.Wrapf(err, "couldn't check drop-in file %s", p)
+		}
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't read drop-in file %s", p)
+		}
+		snapshot[p] = dropInFile{data: data, mode: info.Mode().Perm()}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return snapshot, nil // the machine has no drop-in files
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't snapshot drop-in files in %s", dropInsDir)
+	}
+	return snapshot, nil
+}
+
+// restore makes the drop-in files match the snapshot, replacing changed files and removing files
+// which were added since the snapshot was taken.
+func (s dropInsSnapshot) restore() (err error) {
+	current, err := snapshotDropIns()
+	if err != nil {
+		return err
+	}
+	if len(current) == 0 && len(s) == 0 {
+		return nil
+	}
+	fsys, err := os.OpenRoot(dropInsDir)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't open drop-ins directory %s", dropInsDir)
+	}
+	defer func() {
+		if cerr := fsys.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "couldn't close drop-ins directory %s", dropInsDir)
+		}
+	}()
+
+	for p := range current {
+		if _, ok := s[p]; ok {
+			continue
+		}
+		if err = fsys.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errors.Wrapf(err, "couldn't remove drop-in file %s", p)
+		}
+	}
+	for p, file := range s {
+		if c, ok := current[p]; ok && c.mode == file.mode && bytes.Equal(c.data, file.data) {
+			continue
+		}
+		swapPath := p + ".swp"
+		if err = fsys.WriteFile(swapPath, file.data, file.mode); err != nil {
+			return errors.Wrapf(err, "couldn't write drop-in file %s to swap file %s", p, swapPath)
+		}
+		// WriteFile doesn't change the mode of an existing file, so we must do so explicitly:
+		if err = fsys.Chmod(swapPath, file.mode); err != nil {
+			return errors.Wrapf(err, "couldn't restore permissions of drop-in file %s", p)
+		}
+		if err = fsys.Rename(swapPath, p); err != nil {
+			return errors.Wrapf(err, "couldn't move swap file %s to %s", swapPath, p)
+		}
+	}
+	return nil
+}
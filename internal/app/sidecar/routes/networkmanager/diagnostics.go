@@ -0,0 +1,50 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/diagnostics"
+)
+
+func (h *Handlers) PingGateway(
+	ctx context.Context, call ipc.VarlinkCall, iface string, count int64,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	// Note: we only ping gateways (rather than arbitrary addresses), so that this method can't be
+	// used to send ICMP traffic anywhere else
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return call.ReplyInvalidInterface(ctx, err.Error())
+	}
+	rawGateway := device.IPv4Config.Gateway
+	if rawGateway == "" {
+		return call.ReplyNoGateway(ctx, fmt.Sprintf("%s has no IPv4 default gateway", iface))
+	}
+	gateway, err := netip.ParseAddr(rawGateway)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't parse gateway address %s", rawGateway,
+		), h.l)
+	}
+	const maxCount = 10
+	count = min(max(count, 1), maxCount)
+
+	// Ping gateway
+	const interval = 1 * time.Second
+	stats, err := diagnostics.Ping(ctx, iface, gateway, int(count), interval)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	return call.ReplyPingGateway(
+		ctx, gateway.String(), int64(stats.Sent), int64(stats.Received), stats.RTT.Microseconds(),
+	)
+}
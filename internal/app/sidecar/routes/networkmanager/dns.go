@@ -0,0 +1,164 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+	"github.com/openUC2/machine-admin/internal/clients/hostsfile"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+// globalDNSDropInPath is the NetworkManager configuration drop-in file in which the global DNS
+// configuration is stored. Its name sorts after the drop-in files provided by the OS, so that it
+// takes precedence over them.
+const globalDNSDropInPath = "/etc/NetworkManager/conf.d/90-machine-admin-dns.conf"
+
+// Global DNS
+
+func (h *Handlers) GetGlobalDNS(ctx context.Context, call ipc.VarlinkCall) error {
+	handling.LogMethod(call.Request, h.l)
+
+	data, err := os.ReadFile(globalDNSDropInPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return call.ReplyGetGlobalDNS(ctx, []string{}, []string{})
+	}
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't read drop-in file %s", globalDNSDropInPath,
+		), h.l)
+	}
+	dns, err := nm.ParseGlobalDNSConf(data)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't parse drop-in file %s", globalDNSDropInPath,
+		), h.l)
+	}
+	servers := make([]string, 0, len(dns.Servers))
+	for _, server := range dns.Servers {
+		servers = append(servers, server.String())
+	}
+	return call.ReplyGetGlobalDNS(ctx, servers, append([]string{}, dns.Searches...))
+}
+
+func (h *Handlers) SetGlobalDNS(
+	ctx context.Context, call ipc.VarlinkCall, rawServers, searches []string, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	dns := nm.GlobalDNS{Searches: searches}
+	for _, rawServer := range rawServers {
+		server, err := netip.ParseAddr(rawServer)
+		if err != nil || server.Zone() != "" {
+			return call.ReplyInvalidDNSSettings(ctx, fmt.Sprintf(
+				"DNS server %s is not an IP address", rawServer,
+			))
+		}
+		dns.Servers = append(dns.Servers, server)
+	}
+	for _, search := range searches {
+		if !hostsfile.ValidHostname(search) {
+			return call.ReplyInvalidDNSSettings(ctx, fmt.Sprintf("invalid search domain %s", search))
+		}
+	}
+	if !dns.HasData() && len(dns.Searches) > 0 {
+		return call.ReplyInvalidDNSSettings(
+			ctx, "search domains can only be set together with DNS servers",
+		)
+	}
+
+	// Update drop-in file
+	if dns.HasData() {
+		const fileMode = 0o644 // -rw-r--r--
+		if err := os.WriteFile(globalDNSDropInPath, dns.Conf(), fileMode); err != nil {
+			return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+				err, "couldn't write drop-in file %s", globalDNSDropInPath,
+			), h.l)
+		}
+	} else if err := os.Remove(globalDNSDropInPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't remove drop-in file %s", globalDNSDropInPath,
+		), h.l)
+	}
+	if err := h.nmc.ReloadConf(ctx); err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+
+	message := fmt.Sprintf("removed global DNS servers for %s", requester)
+	if dns.HasData() {
+		message = fmt.Sprintf(
+			"set global DNS servers to %s (search domains: %s) for %s",
+			strings.Join(rawServers, ", "), strings.Join(searches, ", "), requester,
+		)
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "global-dns-updated",
+		Subject: globalDNSDropInPath,
+		Message: message,
+	})
+	return call.ReplySetGlobalDNS(ctx)
+}
+
+// Host entries
+
+func (h *Handlers) ListHostEntries(ctx context.Context, call ipc.VarlinkCall) error {
+	handling.LogMethod(call.Request, h.l)
+
+	entries, err := hostsfile.ReadManaged(hostsfile.Path)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	result := make([]ipc.HostEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, ipc.HostEntry{
+			Address: entry.Address.String(), Hostnames: entry.Hostnames,
+		})
+	}
+	return call.ReplyListHostEntries(ctx, result)
+}
+
+func (h *Handlers) SetHostEntries(
+	ctx context.Context, call ipc.VarlinkCall, rawEntries []ipc.HostEntry, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	entries := make([]hostsfile.Entry, 0, len(rawEntries))
+	for _, rawEntry := range rawEntries {
+		address, err := netip.ParseAddr(rawEntry.Address)
+		if err != nil {
+			return call.ReplyInvalidDNSSettings(ctx, fmt.Sprintf(
+				"%s is not an IP address", rawEntry.Address,
+			))
+		}
+		entries = append(entries, hostsfile.Entry{Address: address, Hostnames: rawEntry.Hostnames})
+	}
+	if err := hostsfile.Check(entries); err != nil {
+		return call.ReplyInvalidDNSSettings(ctx, err.Error())
+	}
+
+	// Update hosts file
+	if err := hostsfile.WriteManaged(hostsfile.Path, entries); err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s %s", entry.Address, strings.Join(entry.Hostnames, " ")))
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "host-entries-updated",
+		Subject: hostsfile.Path,
+		Message: fmt.Sprintf(
+			"set static host entries to [%s] for %s", strings.Join(lines, "; "), requester,
+		),
+	})
+	return call.ReplySetHostEntries(ctx)
+}
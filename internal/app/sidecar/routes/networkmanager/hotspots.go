@@ -0,0 +1,119 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+	"github.com/openUC2/machine-admin/internal/clients/hotspot"
+)
+
+func (h *Handlers) GetHotspotPSK(
+	ctx context.Context, call ipc.VarlinkCall, rawUUID, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return call.ReplyInvalidUUID(ctx, fmt.Sprintf("couldn't parse uuid %s", rawUUID))
+	}
+
+	// Check rate limit
+	// Note: the hotspot's password has its own budget, since it's requested whenever the hotspot's
+	// QR code is shown; this method can't reveal any other secrets, so the separate budget can't be
+	// used to sidestep the limit on revealing other secrets
+	if delay := h.reserveSecretReveal(h.hotspotPSKReveals, audit.Event{
+		Kind:    "hotspot-psk-reveal-rate-limited",
+		Subject: uid.String(),
+		Message: fmt.Sprintf("refused to reveal Wi-Fi hotspot password to %s", requester),
+	}); delay > 0 {
+		return call.ReplyRateLimited(
+			ctx, "too many Wi-Fi passwords were revealed recently",
+			int64(math.Ceil(delay.Seconds())),
+		)
+	}
+
+	// Get password
+	// Note: NetworkManager only returns the real PSK to root, which is why the server needs us
+	connProfile, err := h.nmc.GetConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't get connection profile %s", uid,
+		), h.l)
+	}
+	id := connProfile.Settings.Conn.ID
+	if id != h.hotspotConnProfileID {
+		return call.ReplyNotHotspot(ctx, fmt.Sprintf(
+			"connection profile %s is not the hotspot's connection profile %s",
+			id, h.hotspotConnProfileID,
+		))
+	}
+	if mode := connProfile.Settings.Wifi.Mode; mode != "ap" {
+		return call.ReplyNotHotspot(ctx, fmt.Sprintf(
+			"connection profile %s has Wi-Fi mode %s", id, mode.Info().Short,
+		))
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "hotspot-psk-revealed",
+		Subject: uid.String(),
+		Message: fmt.Sprintf(
+			"revealed Wi-Fi password of hotspot connection profile %s (network %s) to %s",
+			id, connProfile.Settings.Wifi.SSID, requester,
+		),
+	})
+	return call.ReplyGetHotspotPSK(ctx, connProfile.Settings.WifiSec.PSK)
+}
+
+func (h *Handlers) ListHotspotClients(
+	ctx context.Context, call ipc.VarlinkCall, iface string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	// Note: this also ensures that the interface name is safe to use in file paths
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return call.ReplyInvalidInterface(ctx, err.Error())
+	}
+
+	// List clients
+	var clients []hotspot.Client
+	switch device.Type.Info().Short {
+	default:
+		return call.ReplyInvalidInterface(ctx, fmt.Sprintf(
+			"%s is neither a Wi-Fi device nor an Ethernet device", iface,
+		))
+	case "wifi":
+		clients, err = hotspot.ListClients(iface)
+	case "ethernet":
+		clients, err = hotspot.ListWiredClients(iface)
+	}
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	reply := make([]ipc.HotspotClient, 0, len(clients))
+	for _, client := range clients {
+		c := ipc.HotspotClient{
+			MacAddress:   client.MACAddress,
+			Hostname:     client.Hostname,
+			SignalDBm:    int64(client.Signal),
+			ConnectedSec: int64(client.Connected.Seconds()),
+			InactiveMSec: client.Inactive.Milliseconds(),
+		}
+		if client.IPAddress.IsValid() {
+			c.IpAddress = client.IPAddress.String()
+		}
+		if !client.LeaseExpiry.IsZero() {
+			c.LeaseExpiry = client.LeaseExpiry.Unix()
+		}
+		reply = append(reply, c)
+	}
+	return call.ReplyListHotspotClients(ctx, reply)
+}
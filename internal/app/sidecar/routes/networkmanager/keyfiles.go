@@ -0,0 +1,205 @@
+package networkmanager
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+)
+
+const (
+	// keyfilesDir is where NetworkManager's keyfile plugin stores connection profiles persistently.
+	keyfilesDir    = "/etc/NetworkManager/system-connections"
+	keyfileExt     = ".nmconnection"
+	maxKeyfileSize = 64 * 1024
+)
+
+// Exporting
+
+func (h *Handlers) ExportConnProfile(
+	ctx context.Context, call ipc.VarlinkCall, rawUUID string, withSecrets bool, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return call.ReplyInvalidUUID(ctx, fmt.Sprintf("couldn't parse uuid %s", rawUUID))
+	}
+
+	// Check rate limit
+	if withSecrets {
+		if delay := h.reserveSecretReveal(h.secretReveals, audit.Event{
+			Kind:    "conn-profile-export-rate-limited",
+			Subject: uid.String(),
+			Message: fmt.Sprintf("refused to export connection profile with secrets to %s", requester),
+		}); delay > 0 {
+			return call.ReplyRateLimited(
+				ctx, "too many secrets were revealed recently", int64(math.Ceil(delay.Seconds())),
+			)
+		}
+	}
+
+	// Read keyfile
+	filename, err := h.nmc.GetConnProfileFilename(ctx, uid)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't determine filename of connection profile %s", uid,
+		), h.l)
+	}
+	if !strings.HasSuffix(filename, keyfileExt) {
+		return call.ReplyNotKeyfile(ctx, fmt.Sprintf(
+			"connection profile %s is stored in %s, which isn't a keyfile", uid, filename,
+		))
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't read keyfile %s", filename,
+		), h.l)
+	}
+	keyfile, err := nm.ParseKeyfile(data)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't parse keyfile %s", filename,
+		), h.l)
+	}
+	if !withSecrets {
+		return call.ReplyExportConnProfile(ctx, string(keyfile.WithoutSecrets().Bytes()))
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "conn-profile-exported",
+		Subject: uid.String(),
+		Message: fmt.Sprintf(
+			"exported connection profile %s with secrets to %s", keyfile.ID(), requester,
+		),
+	})
+	return call.ReplyExportConnProfile(ctx, string(keyfile.Bytes()))
+}
+
+// Importing
+
+func (h *Handlers) ImportConnProfile(
+	ctx context.Context, call ipc.VarlinkCall, rawKeyfile, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	if len(rawKeyfile) > maxKeyfileSize {
+		return call.ReplyInvalidKeyfile(ctx, fmt.Sprintf(
+			"keyfile must not be larger than %d bytes", maxKeyfileSize,
+		))
+	}
+	keyfile, err := nm.ParseKeyfile([]byte(rawKeyfile))
+	if err != nil {
+		return call.ReplyInvalidKeyfile(ctx, err.Error())
+	}
+	uid := keyfile.UUID()
+	if uid == (uuid.UUID{}) {
+		return call.ReplyInvalidKeyfile(ctx, "the [connection] section has no uuid")
+	}
+	if err = checkImportedKeyfile(keyfile, uid); err != nil {
+		return call.ReplyInvalidKeyfile(ctx, err.Error())
+	}
+
+	// Store keyfile
+	fileName, err := h.findImportedKeyfileName(ctx, uid)
+	if err != nil {
+		return call.ReplyInvalidKeyfile(ctx, err.Error())
+	}
+	if err = storeKeyfile(fileName, keyfile.Bytes()); err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "conn-profile-imported",
+		Subject: uid.String(),
+		Message: fmt.Sprintf(
+			"imported connection profile %s into %s for %s",
+			keyfile.ID(), path.Join(keyfilesDir, fileName), requester,
+		),
+	})
+	return call.ReplyImportConnProfile(ctx)
+}
+
+// checkImportedKeyfile refuses keyfiles which NetworkManager (which runs as root) can't safely
+// load: keyfiles whose 802.1X settings refer to any files other than those stored by the admin
+// panel for the connection profile, and VPN connection profiles, whose plugins the admin panel
+// doesn't manage.
+func checkImportedKeyfile(keyfile nm.Keyfile, uid uuid.UUID) error {
+	connType, _ := keyfile.Section("connection").Get("type")
+	if connType == "vpn" || keyfile.Section("vpn") != nil {
+		return errors.New(
+			"VPN connection profiles can't be imported (WireGuard connection profiles can be)",
+		)
+	}
+	for key, p := range keyfile.Referenced8021xFiles() {
+		if !nm.IsStoredCertPath(uid, key, p) {
+			return errors.Errorf(
+				"802-1x.%s refers to %s, but files can only be uploaded from the connection profile's "+
+					"settings form (or embedded in the keyfile)",
+				key, p,
+			)
+		}
+	}
+	return nil
+}
+
+// findImportedKeyfileName returns the name of the file (in keyfilesDir) for the keyfile of the
+// connection profile with the UUID, which replaces the keyfile of the existing connection profile
+// if there is one.
+func (h *Handlers) findImportedKeyfileName(ctx context.Context, uid uuid.UUID) (string, error) {
+	connProfiles, err := h.nmc.ListConnProfiles(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't list connection profiles")
+	}
+	for _, connProfile := range connProfiles {
+		if connProfile.Settings.Conn.UUID != uid {
+			continue
+		}
+		dir, fileName := path.Split(connProfile.Filename)
+		if path.Clean(dir) != keyfilesDir || !strings.HasSuffix(fileName, keyfileExt) {
+			// Note: a keyfile in keyfilesDir wouldn't take effect if NetworkManager stores the existing
+			// connection profile somewhere with a higher priority (e.g. in /run)
+			return "", errors.Errorf(
+				"the existing connection profile with uuid %s is stored in %s, so it can't be replaced",
+				uid, cmp.Or(connProfile.Filename, "memory"),
+			)
+		}
+		return fileName, nil
+	}
+	return uid.String() + keyfileExt, nil
+}
+
+func storeKeyfile(fileName string, data []byte) (err error) {
+	fsys, err := os.OpenRoot(keyfilesDir)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't open keyfiles directory %s", keyfilesDir)
+	}
+	defer func() {
+		if cerr := fsys.Close(); cerr != nil && err == nil {
+			err = errors.Wrapf(cerr, "couldn't close keyfiles directory %s", keyfilesDir)
+		}
+	}()
+
+	// NetworkManager ignores keyfiles which can be read by other users than root:
+	const fileMode = 0o600 // -rw-------
+	if err = fsys.WriteFile(fileName, data, fileMode); err != nil {
+		return errors.Wrapf(err, "couldn't write keyfile %s", fileName)
+	}
+	// WriteFile doesn't change the mode of an existing file, so we must do so explicitly:
+	if err = fsys.Chmod(fileName, fileMode); err != nil {
+		return errors.Wrapf(err, "couldn't restrict permissions of keyfile %s", fileName)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+	"github.com/openUC2/machine-admin/internal/clients/wifireg"
+)
+
+func (h *Handlers) GetWifiRegDomain(ctx context.Context, call ipc.VarlinkCall) error {
+	handling.LogMethod(call.Request, h.l)
+
+	domain, err := wifireg.GetDomain()
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	// Note: the persisted country is only informational, so it's fine if it can't be determined
+	// (e.g. on a machine which isn't a Raspberry Pi)
+	persistedCountry, err := wifireg.GetPersistedCountry()
+	if err != nil {
+		h.l.Warn(err)
+	}
+
+	rules := make([]ipc.WifiRegRule, 0, len(domain.Rules))
+	for _, rule := range domain.Rules {
+		rules = append(rules, ipc.WifiRegRule{
+			StartFreqMHz:    int64(rule.StartFreq),
+			EndFreqMHz:      int64(rule.EndFreq),
+			MaxBandwidthMHz: int64(rule.MaxBandwidth),
+			MaxEIRPmBm:      int64(rule.MaxEIRP),
+			Flags:           int64(rule.Flags),
+		})
+	}
+	return call.ReplyGetWifiRegDomain(ctx, domain.Country, rules, persistedCountry)
+}
+
+func (h *Handlers) SetWifiCountry(
+	ctx context.Context, call ipc.VarlinkCall, country, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	if !wifireg.ValidCountry(country) {
+		return call.ReplyInvalidCountry(ctx, fmt.Sprintf("invalid country code %s", country))
+	}
+
+	// Set country
+	// Note: we still set the country if it can't be persisted (e.g. on a machine which isn't a
+	// Raspberry Pi), since the country is useful until the next reboot. And we persist the country
+	// even if the kernel rejects it for now (e.g. if the Wi-Fi driver manages its own domain), so
+	// that it's still set after a reboot.
+	persistWarning := ""
+	if err := wifireg.PersistCountry(country); err != nil {
+		h.l.Warn(err)
+		persistWarning = err.Error()
+	}
+	if err := wifireg.SetCountry(country); err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+
+	message := fmt.Sprintf("set Wi-Fi country to %s for %s", country, requester)
+	if persistWarning != "" {
+		message = fmt.Sprintf(
+			"set Wi-Fi country to %s until the next reboot for %s (couldn't persist it: %s)",
+			country, requester, persistWarning,
+		)
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "wifi-country-updated",
+		Subject: country,
+		Message: message,
+	})
+	return call.ReplySetWifiCountry(ctx, persistWarning)
+}
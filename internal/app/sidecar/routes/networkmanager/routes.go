@@ -2,14 +2,18 @@ package networkmanager
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/sargassum-world/godest"
 	"github.com/varlink/go/varlink"
+	"golang.org/x/time/rate"
 
 	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
 	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
 	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
 )
 
@@ -17,14 +21,43 @@ type Handlers struct {
 	ipc.VarlinkInterface
 
 	nmc *nm.Client
+	ac  *audit.Client
+
+	// hotspotConnProfileID is the ID of the connection profile whose password may be shared with
+	// people joining the machine's Wi-Fi hotspot
+	hotspotConnProfileID string
+
+	// secretReveals limits how often stored secrets (e.g. Wi-Fi passwords) may be revealed
+	secretReveals *rate.Limiter
+	// hotspotPSKReveals separately limits how often the hotspot's password may be revealed (e.g. for
+	// its QR code), so that requests for it (e.g. from other sites' images) can't use up the budget
+	// for revealing other secrets
+	hotspotPSKReveals *rate.Limiter
+
+	// dropInSnapshots has the drop-in files to restore when rolling back to each checkpoint
+	dropInSnapshots map[string]dropInsSnapshot
+	checkpointsMu   sync.Mutex
 
 	l godest.Logger
 }
 
-func New(nmc *nm.Client, l godest.Logger) *Handlers {
+func New(
+	nmc *nm.Client, ac *audit.Client, hotspotConnProfileID string, l godest.Logger,
+) *Handlers {
+	const secretRevealInterval = time.Minute
+	const secretRevealBurst = 3
+	const hotspotPSKRevealInterval = 10 * time.Second
+	const hotspotPSKRevealBurst = 6
 	return &Handlers{
-		nmc: nmc,
-		l:   l,
+		nmc:                  nmc,
+		ac:                   ac,
+		hotspotConnProfileID: hotspotConnProfileID,
+		secretReveals:        rate.NewLimiter(rate.Every(secretRevealInterval), secretRevealBurst),
+		hotspotPSKReveals: rate.NewLimiter(
+			rate.Every(hotspotPSKRevealInterval), hotspotPSKRevealBurst,
+		),
+		dropInSnapshots: make(map[string]dropInsSnapshot),
+		l:               l,
 	}
 }
 
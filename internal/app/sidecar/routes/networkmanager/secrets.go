@@ -0,0 +1,84 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+)
+
+func (h *Handlers) RevealWifiPSK(
+	ctx context.Context, call ipc.VarlinkCall, rawUUID, requester string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	uid, err := uuid.Parse(rawUUID)
+	if err != nil {
+		return call.ReplyInvalidUUID(ctx, fmt.Sprintf("couldn't parse uuid %s", rawUUID))
+	}
+
+	// Check rate limit
+	// Note: we count every attempt (rather than only successful reveals), so that the limit can't be
+	// sidestepped by guessing among connection profiles
+	if delay := h.reserveSecretReveal(h.secretReveals, audit.Event{
+		Kind:    "wifi-psk-reveal-rate-limited",
+		Subject: uid.String(),
+		Message: fmt.Sprintf("refused to reveal Wi-Fi password to %s", requester),
+	}); delay > 0 {
+		return call.ReplyRateLimited(
+			ctx, "too many Wi-Fi passwords were revealed recently",
+			int64(math.Ceil(delay.Seconds())),
+		)
+	}
+
+	// Get password
+	// Note: NetworkManager only returns the real PSK to root, which is why the server needs us
+	connProfile, err := h.nmc.GetConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't get connection profile %s", uid,
+		), h.l)
+	}
+	id := connProfile.Settings.Conn.ID
+	if connProfile.Settings.Conn.Type != "802-11-wireless" {
+		return call.ReplyNoWifiPSK(ctx, fmt.Sprintf("connection profile %s is not for Wi-Fi", id))
+	}
+	psk := connProfile.Settings.WifiSec.PSK
+	if psk == "" {
+		return call.ReplyNoWifiPSK(ctx, fmt.Sprintf(
+			"connection profile %s has no stored Wi-Fi password", id,
+		))
+	}
+	h.ac.RecordOrLog(audit.Event{
+		Kind:    "wifi-psk-revealed",
+		Subject: uid.String(),
+		Message: fmt.Sprintf(
+			"revealed Wi-Fi password of connection profile %s (network %s) to %s",
+			id, connProfile.Settings.Wifi.SSID, requester,
+		),
+	})
+	return call.ReplyRevealWifiPSK(ctx, psk)
+}
+
+// reserveSecretReveal returns how long the caller must wait before the limiter allows it to reveal
+// a secret, or zero if it may reveal a secret now. Refusals are recorded in the audit log as the
+// refusal event.
+func (h *Handlers) reserveSecretReveal(limiter *rate.Limiter, refusal audit.Event) time.Duration {
+	now := time.Now()
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+		h.ac.RecordOrLog(refusal)
+	}
+	return delay
+}
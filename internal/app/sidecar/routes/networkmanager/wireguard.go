@@ -0,0 +1,49 @@
+package networkmanager
+
+import (
+	"context"
+	"fmt"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/networkmanager"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/wireguard"
+)
+
+func (h *Handlers) ListWireGuardPeers(
+	ctx context.Context, call ipc.VarlinkCall, iface string,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	device, err := h.nmc.GetDeviceByIface(ctx, iface)
+	if err != nil {
+		return call.ReplyInvalidInterface(ctx, err.Error())
+	}
+	if device.Type.Info().Short != "wireguard" {
+		return call.ReplyInvalidInterface(ctx, fmt.Sprintf("%s is not a WireGuard device", iface))
+	}
+
+	// List peers
+	statuses, err := wireguard.GetPeerStatuses(iface)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	peers := make([]ipc.WireGuardPeer, 0, len(statuses))
+	for _, status := range statuses {
+		peer := ipc.WireGuardPeer{
+			PublicKey:  status.PublicKey,
+			Endpoint:   status.Endpoint,
+			AllowedIPs: make([]string, 0, len(status.AllowedIPs)),
+			RxBytes:    status.ReceivedBytes,
+			TxBytes:    status.TransmittedBytes,
+		}
+		for _, prefix := range status.AllowedIPs {
+			peer.AllowedIPs = append(peer.AllowedIPs, prefix.String())
+		}
+		if !status.LastHandshake.IsZero() {
+			peer.LastHandshake = status.LastHandshake.Unix()
+		}
+		peers = append(peers, peer)
+	}
+	return call.ReplyListWireGuardPeers(ctx, peers)
+}
@@ -70,6 +70,56 @@ func (h *Handlers) UpdatePSKDropInFile(
 	return call.ReplyUpdatePSKDropInFile(ctx)
 }
 
+func (h *Handlers) UpdateWifiBandDropInFile(
+	ctx context.Context, call ipc.VarlinkCall, connProfile string, band string, channel int64,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	// Validate inputs
+	// Note: the values are written into the drop-in file, so they must not contain anything else
+	if band != "" && band != "a" && band != "bg" {
+		return handling.ReportUnknownError(
+			ctx, &call, errors.Errorf("unknown Wi-Fi band %s", band), h.l,
+		)
+	}
+	const maxChannel = 233
+	if channel < 0 || channel > maxChannel || (band == "" && channel != 0) {
+		return handling.ReportUnknownError(
+			ctx, &call, errors.Errorf("invalid Wi-Fi channel %d for band %s", channel, band), h.l,
+		)
+	}
+
+	dropInDir := path.Join("/etc/NetworkManager/system-connections.d", connProfile)
+	fsys, err := os.OpenRoot(dropInDir)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't open drop-in directory %s", dropInDir)
+	}
+	const dropInFile = "52-wifi-band.nmconnection"
+	if band == "" {
+		if err = fsys.Remove(dropInFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+				err, "couldn't remove band drop-in file %s", path.Join(dropInDir, dropInFile),
+			), h.l)
+		}
+		return call.ReplyUpdateWifiBandDropInFile(ctx)
+	}
+
+	lines := []string{
+		"[wifi]",
+		fmt.Sprintf("band=%s", band),
+		fmt.Sprintf("channel=%d", channel),
+		"",
+	}
+	const mode = 0o600 // -rw-------
+	if err = writeAtomically(fsys, dropInFile, lines, mode); err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrapf(
+			err, "couldn't atomically write updated drop-in file %s", path.Join(dropInDir, dropInFile),
+		), h.l)
+	}
+
+	return call.ReplyUpdateWifiBandDropInFile(ctx)
+}
+
 func readLines(fsys *os.Root, filePath string) ([]string, error) {
 	contents, err := fsys.ReadFile(filePath)
 	if err != nil {
@@ -8,14 +8,23 @@ import (
 	"github.com/openUC2/machine-admin/internal/app/sidecar/routes/boot"
 	"github.com/openUC2/machine-admin/internal/app/sidecar/routes/networkmanager"
 	"github.com/openUC2/machine-admin/internal/app/sidecar/routes/openuc2"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/routes/terminal"
 )
 
 type Handlers struct {
+	config  Config
 	globals *client.Globals
 }
 
-func New(globals *client.Globals) *Handlers {
+// Config has the settings which the sidecar's handlers need beyond their clients.
+type Config struct {
+	// HotspotConnProfileID is the ID of the connection profile for the machine's Wi-Fi hotspot.
+	HotspotConnProfileID string
+}
+
+func New(config Config, globals *client.Globals) *Handlers {
 	return &Handlers{
+		config:  config,
 		globals: globals,
 	}
 }
@@ -25,11 +34,16 @@ func (s *Handlers) Register(service *varlink.Service) error {
 	if err := boot.New(s.globals.Systemd, l).Register(service); err != nil {
 		return errors.Wrap(err, "couldn't register systemd handlers")
 	}
-	if err := networkmanager.New(s.globals.NetworkManager, l).Register(service); err != nil {
+	if err := networkmanager.New(
+		s.globals.NetworkManager, s.globals.Base.Audit, s.config.HotspotConnProfileID, l,
+	).Register(service); err != nil {
 		return errors.Wrap(err, "couldn't register networkmanager handlers")
 	}
 	if err := openuc2.New(s.globals.Systemd, l).Register(service); err != nil {
 		return errors.Wrap(err, "couldn't register openUC2 OS handlers")
 	}
+	if err := terminal.New(s.globals.Terminal, l).Register(service); err != nil {
+		return errors.Wrap(err, "couldn't register terminal handlers")
+	}
 	return nil
 }
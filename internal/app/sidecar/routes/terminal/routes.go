@@ -0,0 +1,187 @@
+// Package terminal contains the varlink handlers for remote-support terminal sessions.
+package terminal
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+	"github.com/varlink/go/varlink"
+
+	ipc "github.com/openUC2/machine-admin/internal/app/ipc/terminal"
+	"github.com/openUC2/machine-admin/internal/app/sidecar/handling"
+	"github.com/openUC2/machine-admin/internal/clients/terminal"
+)
+
+type Handlers struct {
+	ipc.VarlinkInterface
+
+	tc *terminal.Client
+
+	l godest.Logger
+}
+
+func New(tc *terminal.Client, l godest.Logger) *Handlers {
+	return &Handlers{
+		tc: tc,
+		l:  l,
+	}
+}
+
+func (h *Handlers) Register(service *varlink.Service) error {
+	return service.RegisterInterface(ipc.VarlinkNew(h))
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func (h *Handlers) GetStatus(ctx context.Context, call ipc.VarlinkCall) error {
+	handling.LogMethod(call.Request, h.l)
+
+	return call.ReplyGetStatus(ctx, toUnix(h.tc.EnabledUntil()))
+}
+
+func (h *Handlers) Enable(ctx context.Context, call ipc.VarlinkCall, durationSec int64) error {
+	handling.LogMethod(call.Request, h.l)
+
+	if durationSec <= 0 {
+		return handling.ReportUnknownError(ctx, &call, errors.Errorf(
+			"invalid duration %d sec", durationSec,
+		), h.l)
+	}
+	enabledUntil := h.tc.Enable(time.Duration(durationSec) * time.Second)
+	return call.ReplyEnable(ctx, toUnix(enabledUntil))
+}
+
+func (h *Handlers) Disable(ctx context.Context, call ipc.VarlinkCall) error {
+	handling.LogMethod(call.Request, h.l)
+
+	h.tc.Disable()
+	return call.ReplyDisable(ctx)
+}
+
+func parseSize(cols, rows int64) (parsedCols, parsedRows uint16, err error) {
+	if cols <= 0 || cols > math.MaxUint16 || rows <= 0 || rows > math.MaxUint16 {
+		return 0, 0, errors.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+	return uint16(cols), uint16(rows), nil
+}
+
+func (h *Handlers) OpenSession(
+	ctx context.Context, call ipc.VarlinkCall, rawCols int64, rawRows int64,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	cols, rows, err := parseSize(rawCols, rawRows)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	id, err := h.tc.OpenSession(cols, rows)
+	if err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	return call.ReplyOpenSession(ctx, id.String())
+}
+
+func (h *Handlers) reportError(ctx context.Context, call *ipc.VarlinkCall, err error) error {
+	switch {
+	default:
+		return handling.ReportUnknownError(ctx, call, err, h.l)
+	case errors.Is(err, terminal.ErrDisabled):
+		return call.ReplyDisabled(ctx, err.Error())
+	case errors.Is(err, terminal.ErrUnknownSession):
+		return call.ReplyUnknownSession(ctx, err.Error())
+	}
+}
+
+func parseSessionID(rawID string) (uuid.UUID, error) {
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return uuid.UUID{}, errors.Wrapf(terminal.ErrUnknownSession, "unparseable session ID %s", rawID)
+	}
+	return id, nil
+}
+
+func (h *Handlers) ReadSession(ctx context.Context, call ipc.VarlinkCall, rawID string) error {
+	handling.LogMethod(call.Request, h.l)
+
+	id, err := parseSessionID(rawID)
+	if err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	if !call.WantsMore() {
+		return handling.ReportUnknownError(ctx, &call, errors.New(
+			"terminal output can only be read as a stream of replies",
+		), h.l)
+	}
+	if err = h.tc.ReadSession(ctx, id, func(chunk []byte) error {
+		call.Continues = true
+		return call.ReplyReadSession(ctx, base64.StdEncoding.EncodeToString(chunk))
+	}); err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	call.Continues = false
+	return call.ReplyReadSession(ctx, "")
+}
+
+func (h *Handlers) WriteSession(
+	ctx context.Context, call ipc.VarlinkCall, rawID string, rawData string,
+) error {
+	// Note: we don't log this method call, because it's called for every keystroke; all input is
+	// instead recorded in the audit trail.
+
+	id, err := parseSessionID(rawID)
+	if err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(rawData)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, errors.Wrap(
+			err, "couldn't decode terminal input",
+		), h.l)
+	}
+	if err = h.tc.WriteSession(id, data); err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	return call.ReplyWriteSession(ctx)
+}
+
+func (h *Handlers) ResizeSession(
+	ctx context.Context, call ipc.VarlinkCall, rawID string, rawCols int64, rawRows int64,
+) error {
+	handling.LogMethod(call.Request, h.l)
+
+	id, err := parseSessionID(rawID)
+	if err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	cols, rows, err := parseSize(rawCols, rawRows)
+	if err != nil {
+		return handling.ReportUnknownError(ctx, &call, err, h.l)
+	}
+	if err = h.tc.ResizeSession(id, cols, rows); err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	return call.ReplyResizeSession(ctx)
+}
+
+func (h *Handlers) CloseSession(ctx context.Context, call ipc.VarlinkCall, rawID string) error {
+	handling.LogMethod(call.Request, h.l)
+
+	id, err := parseSessionID(rawID)
+	if err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	if err = h.tc.CloseSession(id); err != nil {
+		return h.reportError(ctx, &call, err)
+	}
+	return call.ReplyCloseSession(ctx)
+}
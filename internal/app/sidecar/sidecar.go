@@ -19,6 +19,7 @@ type Config struct {
 	Version string
 	URL     string
 	Address string
+	Routes  routes.Config
 }
 
 type Sidecar struct {
@@ -40,7 +41,7 @@ func New(config Config, logger godest.Logger) (s *Sidecar, err error) {
 		return s, errors.Wrap(err, "couldn't create new varlink service")
 	}
 
-	s.Handlers = routes.New(s.Globals)
+	s.Handlers = routes.New(config.Routes, s.Globals)
 	if err := s.Handlers.Register(s.service); err != nil {
 		return s, errors.Wrap(err, "couldn't register varlink interfaces with service")
 	}
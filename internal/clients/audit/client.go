@@ -0,0 +1,106 @@
+// Package audit provides an append-only audit trail of security-sensitive operations
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+)
+
+type Client struct {
+	Config Config
+
+	mu sync.Mutex
+
+	l godest.Logger
+}
+
+func NewClient(c Config, l godest.Logger) *Client {
+	return &Client{
+		Config: c,
+		l:      l,
+	}
+}
+
+// Event is a single entry in the audit trail.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Kind is a short machine-readable description of what happened, e.g. "terminal-session-opened".
+	Kind string `json:"kind"`
+	// Subject identifies what the event is about, e.g. a terminal session ID.
+	Subject string `json:"subject,omitempty"`
+	// Message is a human-readable description of what happened.
+	Message string `json:"message,omitempty"`
+	// Data holds any raw data associated with the event, e.g. terminal output.
+	Data string `json:"data,omitempty"`
+}
+
+// Record appends the event to the audit log file as a line of JSON. If the audit log file would
+// grow larger than the configured maximum size, it's first rotated to a file with the suffix ".1",
+// replacing any previously rotated file.
+func (c *Client) Record(e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't serialize audit event %+v", e)
+	}
+	line = append(line, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	const dirPerm = 0o700
+	if err = os.MkdirAll(filepath.Dir(c.Config.LogPath), dirPerm); err != nil {
+		return errors.Wrapf(err, "couldn't make directory for audit log %s", c.Config.LogPath)
+	}
+	if err = c.rotateIfFull(int64(len(line))); err != nil {
+		return err
+	}
+	const filePerm = 0o600
+	f, err := os.OpenFile(c.Config.LogPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, filePerm)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't open audit log %s", c.Config.LogPath)
+	}
+	if _, err = f.Write(line); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "couldn't write to audit log %s", c.Config.LogPath)
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrapf(err, "couldn't close audit log %s", c.Config.LogPath)
+	}
+	return nil
+}
+
+// rotateIfFull rotates the audit log file if it can't fit the number of additional bytes. It must
+// be called while holding the mutex.
+func (c *Client) rotateIfFull(additional int64) error {
+	info, err := os.Stat(c.Config.LogPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "couldn't check size of audit log %s", c.Config.LogPath)
+	}
+	if info.Size() == 0 || info.Size()+additional <= c.Config.LogMaxSize {
+		return nil
+	}
+	rotatedPath := c.Config.LogPath + ".1"
+	if err = os.Rename(c.Config.LogPath, rotatedPath); err != nil {
+		return errors.Wrapf(err, "couldn't rotate audit log %s to %s", c.Config.LogPath, rotatedPath)
+	}
+	return nil
+}
+
+// RecordOrLog records the event, and logs any error encountered in recording the event.
+func (c *Client) RecordOrLog(e Event) {
+	if err := c.Record(e); err != nil {
+		c.l.Error(errors.Wrap(err, "couldn't record audit event"))
+	}
+}
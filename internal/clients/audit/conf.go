@@ -0,0 +1,30 @@
+package audit
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest/env"
+)
+
+const envPrefix = "AUDIT_"
+
+type Config struct {
+	LogPath string
+	// LogMaxSize is the size (in bytes) at which the audit log is rotated; the rotated log replaces
+	// any previously rotated log, so that the audit log takes up at most twice this size
+	LogMaxSize int64
+}
+
+func GetConfig() (c Config, err error) {
+	const defaultLogPath = "/var/log/machine-admin/audit.jsonl"
+	c.LogPath = env.GetString(envPrefix+"LOG_PATH", defaultLogPath)
+
+	const defaultLogMaxSize = 16 * 1024 * 1024 // bytes
+	if c.LogMaxSize, err = env.GetInt64(envPrefix+"LOG_MAX_SIZE", defaultLogMaxSize); err != nil {
+		return Config{}, errors.Wrap(err, "couldn't make audit log max size config")
+	}
+	if c.LogMaxSize < 1 {
+		return Config{}, errors.Errorf("audit log max size %d must be positive", c.LogMaxSize)
+	}
+
+	return c, nil
+}
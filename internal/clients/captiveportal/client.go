@@ -0,0 +1,178 @@
+// Package captiveportal supports logging into captive portals (e.g. of hotel Wi-Fi networks)
+// through the admin panel, by making HTTP requests through the uplink network interface and
+// rewriting the portal's pages so that they can be served from the admin panel
+package captiveportal
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// NewHTTPClient makes an HTTP client which sends requests out through the network interface from
+// the local address, and resolves host names with the nameservers, so that requests go to the
+// captive portal of the network which the interface is connected to. The client doesn't follow
+// redirects, since redirects must be rewritten to go through the admin panel.
+//
+// Captive portals are commonly served from private addresses, so the client may reach the portal
+// host at a private address. But it refuses to connect to other hosts at non-public addresses (e.g.
+// private addresses), so that it can't be used to reach other devices on the machine's networks.
+// And since the portal host is chosen by the network, the client never connects to the machine's
+// own addresses or to link-local addresses, even for the portal host.
+func NewHTTPClient(
+	iface string, localAddr netip.Addr, nameservers []netip.Addr, portalHost string,
+	timeout time.Duration,
+) *http.Client {
+	bind := bindToDevice(iface)
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (conn net.Conn, err error) {
+			// Captive portals often answer DNS queries with their own address, so we must only use
+			// the network's nameservers (rather than e.g. a VPN's nameservers):
+			d := net.Dialer{Control: bind}
+			for _, nameserver := range nameservers {
+				if conn, err = d.DialContext(
+					ctx, network, net.JoinHostPort(nameserver.String(), "53"),
+				); err == nil {
+					return conn, nil
+				}
+			}
+			if err == nil {
+				err = errors.New("no nameservers")
+			}
+			return nil, err
+		},
+	}
+	// Note: the dialers check addresses after host names are resolved, so that host names which
+	// resolve to forbidden addresses can't be used to sidestep the checks
+	portalDialer := &net.Dialer{
+		Timeout:   timeout,
+		LocalAddr: &net.TCPAddr{IP: localAddr.AsSlice()},
+		Resolver:  resolver,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if err := checkPortalAddress(address); err != nil {
+				return err
+			}
+			return bind(network, address, c)
+		},
+	}
+	publicDialer := &net.Dialer{
+		Timeout:   timeout,
+		LocalAddr: &net.TCPAddr{IP: localAddr.AsSlice()},
+		Resolver:  resolver,
+		Control: func(network, address string, c syscall.RawConn) error {
+			if err := checkPublicAddress(address); err != nil {
+				return err
+			}
+			return bind(network, address, c)
+		},
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				if host, _, err := net.SplitHostPort(address); err == nil &&
+					portalHost != "" && strings.EqualFold(host, portalHost) {
+					return portalDialer.DialContext(ctx, network, address)
+				}
+				return publicDialer.DialContext(ctx, network, address)
+			},
+			TLSHandshakeTimeout: timeout,
+			DisableKeepAlives:   true,
+			// Note: we must receive uncompressed responses so that we can rewrite them:
+			DisableCompression: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: timeout,
+	}
+}
+
+// bindToDevice returns a [net.Dialer.Control] function which binds sockets to the network
+// interface, so that their traffic goes out through the interface even if the routing table would
+// send it elsewhere (e.g. through a VPN tunnel which provides the machine's default route). Note
+// that Linux only allows unprivileged processes to do this since Linux 5.7.
+func bindToDevice(iface string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var err error
+		if cerr := c.Control(func(fd uintptr) {
+			err = unix.BindToDevice(int(fd), iface)
+		}); cerr != nil {
+			return errors.Wrap(cerr, "couldn't access socket")
+		}
+		return errors.Wrapf(err, "couldn't bind socket to %s", iface)
+	}
+}
+
+// sharedAddressSpace is used by carrier-grade NATs and by overlay networks (e.g. Tailscale), so it
+// isn't public even though netip doesn't consider it private.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkPortalAddress checks whether the address (with a port) may belong to a captive portal:
+// captive portals may have private addresses, but not loopback, link-local, unspecified, or
+// multicast addresses, nor any of the machine's own addresses.
+func checkPortalAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't parse address %s", address)
+	}
+	return checkPortalAddr(addrPort.Addr().Unmap())
+}
+
+func checkPortalAddr(addr netip.Addr) error {
+	if addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsUnspecified() ||
+		addr.IsMulticast() {
+		return errors.Errorf("refusing to connect to %s, which is not a routable address", addr)
+	}
+	local, err := isLocalAddress(addr)
+	if err != nil {
+		return err
+	}
+	if local {
+		return errors.Errorf("refusing to connect to %s, which is the machine's own address", addr)
+	}
+	return nil
+}
+
+// isLocalAddress checks whether the address is assigned to any of the machine's network
+// interfaces.
+func isLocalAddress(addr netip.Addr) (bool, error) {
+	ifaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false, errors.Wrap(err, "couldn't list the machine's addresses")
+	}
+	for _, ifaceAddr := range ifaceAddrs {
+		if prefix, err := netip.ParsePrefix(ifaceAddr.String()); err == nil &&
+			prefix.Addr().Unmap() == addr {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkPublicAddress checks whether the address (with a port) is a public unicast address.
+func checkPublicAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't parse address %s", address)
+	}
+	addr := addrPort.Addr().Unmap()
+	if err = checkPortalAddr(addr); err != nil {
+		return err
+	}
+	if addr.IsPrivate() || sharedAddressSpace.Contains(addr) {
+		return errors.Errorf(
+			"refusing to connect to %s, which is not a public address (only the captive portal's own "+
+				"host may have a non-public address)",
+			addr,
+		)
+	}
+	return nil
+}
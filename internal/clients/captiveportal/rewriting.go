@@ -0,0 +1,147 @@
+package captiveportal
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// cookiePrefix is prepended to the names of cookies set by captive portals, so that they can be
+// told apart from the admin panel's own cookies (which must never be sent to captive portals).
+const cookiePrefix = "portal_"
+
+// Rewriter rewrites URLs so that they go through the admin panel's proxy for captive portals. The
+// proxy serves each target URL at a path of the form "<prefix><scheme>/<host>/<path>", so that
+// relative URLs in the portal's pages keep working without changes.
+type Rewriter struct {
+	// Prefix is the path of the proxy, with a trailing slash.
+	Prefix string
+}
+
+// ProxyPath returns the path (and query) under which the proxy serves the target URL.
+func (r Rewriter) ProxyPath(target *url.URL) string {
+	path := r.Prefix + target.Scheme + "/" + target.Host + target.EscapedPath()
+	if target.Path == "" {
+		path += "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return path
+}
+
+// ParseProxyPath returns the target URL for the path (relative to the proxy's prefix) and the
+// query of a request to the proxy.
+func (r Rewriter) ParseProxyPath(path, rawQuery string) (*url.URL, error) {
+	scheme, rest, _ := strings.Cut(path, "/")
+	if scheme != "http" && scheme != "https" {
+		return nil, errors.Errorf("unsupported scheme %s", scheme)
+	}
+	host, targetPath, _ := strings.Cut(rest, "/")
+	if host == "" {
+		return nil, errors.New("missing host")
+	}
+	target, err := url.Parse(scheme + "://" + host + "/" + targetPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't parse target URL for %s", path)
+	}
+	target.RawQuery = rawQuery
+	return target, nil
+}
+
+// RewriteURL rewrites the reference (e.g. from a link) in a page of the base URL, if needed for it
+// to go through the proxy. Relative references other than root-relative references don't need to
+// be rewritten, since the proxy preserves the structure of the target paths.
+func (r Rewriter) RewriteURL(ref string, base *url.URL) string {
+	switch {
+	case strings.HasPrefix(ref, "//"):
+		ref = base.Scheme + ":" + ref
+	case strings.HasPrefix(ref, "/"):
+		ref = base.Scheme + "://" + base.Host + ref
+	}
+	lowerRef := strings.ToLower(ref)
+	if !strings.HasPrefix(lowerRef, "http://") && !strings.HasPrefix(lowerRef, "https://") {
+		return ref
+	}
+	target, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	rewritten := r.ProxyPath(target)
+	if target.Fragment != "" {
+		rewritten += "#" + target.EscapedFragment()
+	}
+	return rewritten
+}
+
+var (
+	htmlURLAttrPattern = regexp.MustCompile(
+		`(?i)(\s(?:href|src|action|formaction|poster)\s*=\s*)(?:"([^"]*)"|'([^']*)')`,
+	)
+	htmlRefreshPattern = regexp.MustCompile(`(?i)(;\s*url\s*=\s*['"]?)([^'";>\s]+)`)
+	cssURLPattern      = regexp.MustCompile(`(?i)(url\(\s*['"]?)([^'")\s]+)`)
+)
+
+// RewriteHTML rewrites the URLs in the HTML page from the base URL so that they go through the
+// proxy. This only rewrites URLs in attributes, meta refresh tags, and styles; URLs constructed by
+// scripts won't go through the proxy.
+func (r Rewriter) RewriteHTML(page []byte, base *url.URL) []byte {
+	page = htmlURLAttrPattern.ReplaceAllFunc(page, func(match []byte) []byte {
+		groups := htmlURLAttrPattern.FindSubmatch(match)
+		quote, ref := `"`, string(groups[2])
+		if len(groups[3]) > 0 {
+			quote, ref = `'`, string(groups[3])
+		}
+		return []byte(string(groups[1]) + quote + r.RewriteURL(ref, base) + quote)
+	})
+	page = htmlRefreshPattern.ReplaceAllFunc(page, func(match []byte) []byte {
+		groups := htmlRefreshPattern.FindSubmatch(match)
+		return []byte(string(groups[1]) + r.RewriteURL(string(groups[2]), base))
+	})
+	return r.RewriteCSS(page, base)
+}
+
+// RewriteCSS rewrites the URLs in the stylesheet from the base URL so that they go through the
+// proxy.
+func (r Rewriter) RewriteCSS(stylesheet []byte, base *url.URL) []byte {
+	return cssURLPattern.ReplaceAllFunc(stylesheet, func(match []byte) []byte {
+		groups := cssURLPattern.FindSubmatch(match)
+		return []byte(string(groups[1]) + r.RewriteURL(string(groups[2]), base))
+	})
+}
+
+// RewriteSetCookie rewrites a cookie set by the target URL so that the browser only sends it back
+// to the proxy for the target's host.
+func (r Rewriter) RewriteSetCookie(rawCookie string, target *url.URL) (string, error) {
+	cookie, err := http.ParseSetCookie(rawCookie)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't parse cookie set by %s", target.Host)
+	}
+	cookie.Name = cookiePrefix + cookie.Name
+	cookie.Domain = ""
+	cookie.Path = r.Prefix + target.Scheme + "/" + target.Host + "/"
+	// The admin panel may be served over plain HTTP, in which case the browser would ignore secure
+	// cookies:
+	cookie.Secure = false
+	// Proxied pages are sandboxed with an opaque origin, so their form submissions are cross-site
+	// requests; browsers wouldn't send Lax or Strict cookies with them:
+	cookie.SameSite = http.SameSiteDefaultMode
+	return cookie.String(), nil
+}
+
+// TargetCookies returns the cookies from a request to the proxy which should be sent to the target
+// URL.
+func TargetCookies(req *http.Request) []*http.Cookie {
+	var cookies []*http.Cookie
+	for _, cookie := range req.Cookies() {
+		name, ok := strings.CutPrefix(cookie.Name, cookiePrefix)
+		if !ok {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: name, Value: cookie.Value})
+	}
+	return cookies
+}
@@ -0,0 +1,311 @@
+// Package diagnostics runs step-by-step checks of the machine's internet connectivity, and keeps
+// the results of recent runs so that they can be shown while the checks are still in progress
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+)
+
+var ErrUnknownRun = errors.New("unknown diagnostics run")
+
+// maxRuns is the number of recent runs whose results are kept in memory.
+const maxRuns = 8
+
+type Client struct {
+	Config Config
+
+	mu          sync.Mutex
+	runs        map[uuid.UUID]*Run
+	order       []uuid.UUID // oldest first
+	subscribers map[uuid.UUID]map[chan struct{}]struct{}
+
+	l godest.Logger
+}
+
+func NewClient(c Config, l godest.Logger) *Client {
+	return &Client{
+		Config:      c,
+		runs:        make(map[uuid.UUID]*Run),
+		subscribers: make(map[uuid.UUID]map[chan struct{}]struct{}),
+		l:           l,
+	}
+}
+
+// Checks
+
+// Check is a step of a diagnostics run.
+type Check struct {
+	Name string
+	// Required checks must pass (possibly with warnings) for the remaining steps to be run, because
+	// the remaining steps would fail anyways.
+	Required bool
+	Run      func(ctx context.Context) Result
+}
+
+type Result struct {
+	Status  Status
+	Summary string
+	// Fixes are suggestions for what the user could do to fix the problem found by the check.
+	Fixes []string
+}
+
+func Passed(format string, a ...any) Result {
+	return Result{Status: StatusPassed, Summary: fmt.Sprintf(format, a...)}
+}
+
+func Warning(fixes []string, format string, a ...any) Result {
+	return Result{Status: StatusWarning, Summary: fmt.Sprintf(format, a...), Fixes: fixes}
+}
+
+func Failed(fixes []string, format string, a ...any) Result {
+	return Result{Status: StatusFailed, Summary: fmt.Sprintf(format, a...), Fixes: fixes}
+}
+
+func Skipped(format string, a ...any) Result {
+	return Result{Status: StatusSkipped, Summary: fmt.Sprintf(format, a...)}
+}
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusPassed  Status = "passed"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+var statusInfo = map[Status]EnumInfo{
+	StatusPending: {
+		Short:   "pending",
+		Details: "this step will be run after the previous steps",
+		Level:   "light",
+	},
+	StatusRunning: {
+		Short:   "running",
+		Details: "this step is being run",
+		Level:   "info",
+	},
+	StatusPassed: {
+		Short:   "passed",
+		Details: "no problems were found",
+		Level:   "success",
+	},
+	StatusWarning: {
+		Short:   "warning",
+		Details: "a possible problem was found",
+		Level:   "warning",
+	},
+	StatusFailed: {
+		Short:   "failed",
+		Details: "a problem was found",
+		Level:   "danger",
+	},
+	StatusSkipped: {
+		Short:   "skipped",
+		Details: "this step was not run",
+		Level:   "light",
+	},
+}
+
+func (s Status) Info() EnumInfo {
+	info, ok := statusInfo[s]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown status (%s)", s),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+// IsFinal reports whether the status is the outcome of a step, rather than an intermediate status.
+func (s Status) IsFinal() bool {
+	return s != StatusPending && s != StatusRunning
+}
+
+// Runs
+
+type Run struct {
+	ID uuid.UUID
+	// Subject describes what the diagnostics run is checking, e.g. a network interface.
+	Subject  string
+	Started  time.Time
+	Finished time.Time
+	Steps    []Step
+}
+
+type Step struct {
+	Name   string
+	Result Result
+}
+
+// Status returns the worst status among the run's steps, or the running status if the run hasn't
+// finished.
+func (r Run) Status() Status {
+	if r.Finished.IsZero() {
+		return StatusRunning
+	}
+	status := StatusPassed
+	for _, step := range r.Steps {
+		switch step.Result.Status {
+		case StatusFailed:
+			return StatusFailed
+		case StatusWarning:
+			status = StatusWarning
+		}
+	}
+	return status
+}
+
+// StartRun starts running the checks one after another in the background, and returns the ID of
+// the new run.
+func (c *Client) StartRun(subject string, checks []Check) uuid.UUID {
+	run := &Run{
+		ID:      uuid.New(),
+		Subject: subject,
+		Started: time.Now(),
+		Steps:   make([]Step, len(checks)),
+	}
+	for i, check := range checks {
+		run.Steps[i] = Step{Name: check.Name, Result: Result{Status: StatusPending}}
+	}
+
+	c.mu.Lock()
+	c.runs[run.ID] = run
+	c.order = append(c.order, run.ID)
+	if len(c.order) > maxRuns {
+		for _, id := range c.order[:len(c.order)-maxRuns] {
+			delete(c.runs, id)
+		}
+		c.order = slices.Clone(c.order[len(c.order)-maxRuns:])
+	}
+	c.mu.Unlock()
+
+	go c.execute(run.ID, checks)
+	return run.ID
+}
+
+func (c *Client) execute(id uuid.UUID, checks []Check) {
+	skipReason := ""
+	for i, check := range checks {
+		if skipReason != "" {
+			c.setResult(id, i, Skipped("%s", skipReason))
+			continue
+		}
+
+		c.setResult(id, i, Result{Status: StatusRunning})
+		// Note: runs aren't tied to the request which started them, since the user may reload the
+		// page while the run is in progress
+		ctx, cancel := context.WithTimeout(context.Background(), c.Config.StepTimeout)
+		result := check.Run(ctx)
+		if ctx.Err() != nil && !result.Status.IsFinal() {
+			result = Failed(nil, "the step didn't finish within %s", c.Config.StepTimeout)
+		}
+		cancel()
+		c.setResult(id, i, result)
+
+		if check.Required && result.Status == StatusFailed {
+			skipReason = fmt.Sprintf("this step depends on the step \"%s\"", check.Name)
+		}
+	}
+
+	c.mu.Lock()
+	if run, ok := c.runs[id]; ok {
+		run.Finished = time.Now()
+	}
+	c.mu.Unlock()
+	c.notify(id)
+}
+
+func (c *Client) setResult(id uuid.UUID, step int, result Result) {
+	c.mu.Lock()
+	if run, ok := c.runs[id]; ok {
+		run.Steps[step].Result = result
+	}
+	c.mu.Unlock()
+	c.notify(id)
+}
+
+// GetRun returns a snapshot of the run's progress.
+func (c *Client) GetRun(id uuid.UUID) (Run, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	run, ok := c.runs[id]
+	if !ok {
+		return Run{}, errors.Wrapf(ErrUnknownRun, "couldn't find run %s", id)
+	}
+	snapshot := *run
+	snapshot.Steps = slices.Clone(run.Steps)
+	return snapshot, nil
+}
+
+// ListRuns returns snapshots of the recent runs, newest first.
+func (c *Client) ListRuns() []Run {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runs := make([]Run, 0, len(c.order))
+	for _, id := range slices.Backward(c.order) {
+		snapshot := *c.runs[id]
+		snapshot.Steps = slices.Clone(snapshot.Steps)
+		runs = append(runs, snapshot)
+	}
+	return runs
+}
+
+// Subscribe returns a channel which receives a value whenever the run's progress changes, until the
+// context is canceled. Multiple changes may be coalesced into a single notification.
+func (c *Client) Subscribe(ctx context.Context, id uuid.UUID) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	c.mu.Lock()
+	if c.subscribers[id] == nil {
+		c.subscribers[id] = make(map[chan struct{}]struct{})
+	}
+	c.subscribers[id][changes] = struct{}{}
+	c.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		c.mu.Lock()
+		delete(c.subscribers[id], changes)
+		if len(c.subscribers[id]) == 0 {
+			delete(c.subscribers, id)
+		}
+		c.mu.Unlock()
+	}()
+	return changes
+}
+
+func (c *Client) notify(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for changes := range c.subscribers[id] {
+		select {
+		case changes <- struct{}{}:
+		default: // the subscriber already has a pending notification
+		}
+	}
+}
+
+// EnumInfo
+
+type EnumInfo struct {
+	Short   string
+	Details string
+	Level   string
+}
+
+const EnumInfoLevelError = "error"
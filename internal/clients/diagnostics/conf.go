@@ -0,0 +1,46 @@
+package diagnostics
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest/env"
+)
+
+const envPrefix = "DIAGNOSTICS_"
+
+type Config struct {
+	// Endpoints are the URLs of services which the machine needs to reach over the internet (e.g.
+	// the Tailscale control plane), to be checked as part of each diagnostics run.
+	Endpoints []*url.URL
+	// StepTimeout is the longest amount of time which each step of a diagnostics run may take.
+	StepTimeout time.Duration
+}
+
+func GetConfig() (c Config, err error) {
+	// Note: endpoints are separated by whitespace
+	const defaultEndpoints = "https://controlplane.tailscale.com/key?v=71"
+	for rawEndpoint := range strings.FieldsSeq(
+		env.GetString(envPrefix+"ENDPOINTS", defaultEndpoints),
+	) {
+		endpoint, err := url.Parse(rawEndpoint)
+		if err != nil {
+			return Config{}, errors.Wrapf(err, "couldn't parse endpoint %s", rawEndpoint)
+		}
+		if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+			return Config{}, errors.Errorf("endpoint %s isn't an HTTP or HTTPS URL", rawEndpoint)
+		}
+		c.Endpoints = append(c.Endpoints, endpoint)
+	}
+
+	const defaultStepTimeout = 10 // sec
+	rawStepTimeout, err := env.GetInt64(envPrefix+"STEP_TIMEOUT", defaultStepTimeout)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "couldn't make step timeout config")
+	}
+	c.StepTimeout = time.Duration(rawStepTimeout) * time.Second
+
+	return c, nil
+}
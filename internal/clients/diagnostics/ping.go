@@ -0,0 +1,120 @@
+package diagnostics
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sys/unix"
+)
+
+type PingStats struct {
+	Sent     int
+	Received int
+	// RTT is the average round-trip time of the received replies.
+	RTT time.Duration
+}
+
+// Ping sends ICMP echo requests to the IPv4 address through the network interface, one per
+// interval, and waits up to the interval for each reply. This requires root privileges, since it
+// uses a raw socket.
+func Ping(
+	ctx context.Context, iface string, addr netip.Addr, count int, interval time.Duration,
+) (s PingStats, err error) {
+	if !addr.Is4() {
+		return s, errors.Errorf("%s isn't an IPv4 address", addr)
+	}
+	lc := net.ListenConfig{
+		Control: func(_, _ string, rc syscall.RawConn) (err error) {
+			if cerr := rc.Control(func(fd uintptr) {
+				err = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, iface)
+			}); cerr != nil {
+				return cerr
+			}
+			return err
+		},
+	}
+	conn, err := lc.ListenPacket(ctx, "ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return s, errors.Wrapf(err, "couldn't open ICMP socket on %s", iface)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	id := os.Getpid() & 0xffff
+	var totalRTT time.Duration
+	for seq := range count {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		rtt, ok, err := pingOnce(conn, addr, id, seq, interval)
+		if err != nil {
+			return s, errors.Wrapf(err, "couldn't ping %s", addr)
+		}
+		s.Sent++
+		if ok {
+			s.Received++
+			totalRTT += rtt
+			if remaining := interval - rtt; remaining > 0 && seq < count-1 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(remaining):
+				}
+			}
+		}
+	}
+	if s.Received > 0 {
+		s.RTT = totalRTT / time.Duration(s.Received)
+	}
+	return s, nil
+}
+
+func pingOnce(
+	conn net.PacketConn, addr netip.Addr, id, seq int, timeout time.Duration,
+) (rtt time.Duration, ok bool, err error) {
+	request, err := (&icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("machine-admin")},
+	}).Marshal(nil)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "couldn't make echo request")
+	}
+	sent := time.Now()
+	if _, err = conn.WriteTo(request, &net.IPAddr{IP: addr.AsSlice()}); err != nil {
+		return 0, false, errors.Wrap(err, "couldn't send echo request")
+	}
+	if err = conn.SetReadDeadline(sent.Add(timeout)); err != nil {
+		return 0, false, errors.Wrap(err, "couldn't set deadline for echo reply")
+	}
+
+	const protocolICMP = 1
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return 0, false, nil
+			}
+			return 0, false, errors.Wrap(err, "couldn't receive echo reply")
+		}
+		// Note: the raw socket receives all ICMP messages, so we must ignore unrelated messages
+		if peerAddr, isIP := peer.(*net.IPAddr); !isIP || !peerAddr.IP.Equal(addr.AsSlice()) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(protocolICMP, buf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		if echo, isEcho := reply.Body.(*icmp.Echo); !isEcho || echo.ID != id || echo.Seq != seq {
+			continue
+		}
+		return time.Since(sent), true, nil
+	}
+}
@@ -0,0 +1,84 @@
+package diagnostics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// LookupHost resolves the host name by querying only the specified nameserver, bypassing the
+// system's resolver configuration.
+func LookupHost(ctx context.Context, nameserver netip.Addr, host string) ([]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, net.JoinHostPort(nameserver.String(), "53"))
+		},
+	}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't resolve %s with nameserver %s", host, nameserver)
+	}
+	return addrs, nil
+}
+
+// HTTPResponse summarizes the response to an HTTP request made by ProbeHTTP.
+type HTTPResponse struct {
+	StatusCode int
+	// Location is the target of a redirect, if the response is a redirect.
+	Location string
+	Header   http.Header
+	// Body is the beginning of the response body.
+	Body string
+}
+
+func (r HTTPResponse) IsRedirect() bool {
+	return r.StatusCode >= http.StatusMultipleChoices && r.StatusCode < http.StatusBadRequest
+}
+
+func (r HTTPResponse) Status() string {
+	return strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode)
+}
+
+// ProbeHTTP makes a GET request to the URL without following redirects, since a redirect is often
+// the only sign of a captive portal.
+func ProbeHTTP(ctx context.Context, u *url.URL) (r HTTPResponse, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return r, errors.Wrapf(err, "couldn't make request for %s", u)
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return r, errors.Wrapf(err, "couldn't get %s", u)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	r.StatusCode = res.StatusCode
+	r.Location = res.Header.Get("Location")
+	r.Header = res.Header
+	const maxBodySize = 1024 // bytes
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return r, errors.Wrapf(err, "couldn't read response from %s", u)
+	}
+	r.Body = string(body)
+	return r, nil
+}
@@ -0,0 +1,177 @@
+// Package hostsfile manages a block of static host entries in the machine's hosts file, leaving
+// all other entries unchanged.
+package hostsfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Path is the machine's hosts file.
+const Path = "/etc/hosts"
+
+// The managed entries are kept between these lines, so that they can be replaced without touching
+// the entries added by the OS or by the machine's operator:
+const (
+	beginMarker = "# BEGIN machine-admin managed entries (edit them from the admin panel)"
+	endMarker   = "# END machine-admin managed entries"
+)
+
+// MaxEntries is the maximum number of managed entries.
+const MaxEntries = 64
+
+// Entry is a static host entry, which resolves each of its hostnames to its address.
+type Entry struct {
+	Address   netip.Addr
+	Hostnames []string
+}
+
+func (e Entry) String() string {
+	return e.Address.String() + "\t" + strings.Join(e.Hostnames, " ")
+}
+
+var hostnamePattern = regexp.MustCompile(
+	`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?)*$`,
+)
+
+// ValidHostname checks whether the name is a valid hostname (e.g. "fileserver" or
+// "fileserver.lab.example.org").
+func ValidHostname(name string) bool {
+	const maxHostnameLength = 253
+	return len(name) <= maxHostnameLength && hostnamePattern.MatchString(name)
+}
+
+// Check checks whether the entries may be written to the hosts file.
+func Check(entries []Entry) error {
+	if len(entries) > MaxEntries {
+		return errors.Errorf("at most %d host entries are allowed", MaxEntries)
+	}
+	for _, entry := range entries {
+		if !entry.Address.IsValid() {
+			return errors.New("each host entry must have an address")
+		}
+		if entry.Address.Zone() != "" {
+			return errors.Errorf("address %s must not have a zone", entry.Address)
+		}
+		if len(entry.Hostnames) == 0 {
+			return errors.Errorf("host entry for %s has no hostnames", entry.Address)
+		}
+		for _, hostname := range entry.Hostnames {
+			if !ValidHostname(hostname) {
+				return errors.Errorf("invalid hostname %s for %s", hostname, entry.Address)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseEntry parses a line of the hosts file, which must have an address followed by at least one
+// hostname (e.g. "192.168.1.20 fileserver fileserver.lab.example.org").
+func ParseEntry(line string) (e Entry, err error) {
+	if comment := strings.Index(line, "#"); comment >= 0 {
+		line = line[:comment]
+	}
+	fields := strings.Fields(line)
+	const minFields = 2 // an address and a hostname
+	if len(fields) < minFields {
+		return e, errors.Errorf("%s must be an address followed by at least one hostname", line)
+	}
+	if e.Address, err = netip.ParseAddr(fields[0]); err != nil {
+		return e, errors.Wrapf(err, "couldn't parse address %s", fields[0])
+	}
+	e.Hostnames = fields[1:]
+	return e, Check([]Entry{e})
+}
+
+// ReadManaged returns the managed entries of the hosts file at the path.
+func ReadManaged(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read hosts file %s", path)
+	}
+	entries := make([]Entry, 0)
+	managed := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == beginMarker:
+			managed = true
+		case line == endMarker:
+			managed = false
+		case managed && line != "" && !strings.HasPrefix(line, "#"):
+			entry, err := ParseEntry(line)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s line %d", path, lineNum)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "couldn't read hosts file %s", path)
+	}
+	return entries, nil
+}
+
+// WriteManaged replaces the managed entries of the hosts file at the path, keeping all other lines
+// of the file. If there are no entries, the managed block is removed. This requires root
+// privileges.
+func WriteManaged(path string, entries []Entry) error {
+	if err := Check(entries); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't check hosts file %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read hosts file %s", path)
+	}
+
+	var b bytes.Buffer
+	managed := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch strings.TrimSpace(line) {
+		case beginMarker:
+			managed = true
+			continue
+		case endMarker:
+			managed = false
+			continue
+		}
+		if !managed {
+			b.WriteString(line + "\n")
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Wrapf(err, "couldn't read hosts file %s", path)
+	}
+	if len(entries) > 0 {
+		fmt.Fprintln(&b, beginMarker)
+		for _, entry := range entries {
+			fmt.Fprintln(&b, entry.String())
+		}
+		fmt.Fprintln(&b, endMarker)
+	}
+
+	// Note: we replace the file atomically, so that name resolution never sees a partial file
+	swapPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".swp")
+	if err = os.WriteFile(swapPath, b.Bytes(), info.Mode().Perm()); err != nil {
+		return errors.Wrapf(err, "couldn't write hosts file to swap file %s", swapPath)
+	}
+	if err = os.Rename(swapPath, path); err != nil {
+		return errors.Wrapf(err, "couldn't move swap file %s to %s", swapPath, path)
+	}
+	return nil
+}
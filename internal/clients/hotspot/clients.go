@@ -0,0 +1,110 @@
+// Package hotspot exposes information about the devices connected to the machine's Wi-Fi hotspots
+// and to its shared Ethernet connections
+package hotspot
+
+import (
+	"bytes"
+	"cmp"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Client is a device connected to a Wi-Fi hotspot or to a shared Ethernet connection.
+type Client struct {
+	MACAddress string
+	// IPAddress is the zero value if the device has no DHCP lease.
+	IPAddress netip.Addr
+	// Hostname is empty if the device didn't report a hostname when requesting a DHCP lease.
+	Hostname string
+	// LeaseExpiry is the zero value if the device has no DHCP lease or the lease never expires.
+	LeaseExpiry time.Time
+	// Signal, Connected, and Inactive are only known for devices connected to Wi-Fi hotspots.
+	Signal    int // dBm
+	Connected time.Duration
+	Inactive  time.Duration
+}
+
+// ListClients lists the devices associated with the Wi-Fi hotspot on the specified network
+// interface, combining the kernel's information about associated stations with the DHCP leases
+// which NetworkManager handed out for the hotspot. Reading this information requires root
+// privileges.
+func ListClients(iface string) ([]Client, error) {
+	stations, err := listStations(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't list stations associated with %s", iface)
+	}
+	leases, err := readLeases(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read DHCP leases for %s", iface)
+	}
+
+	clients := make([]Client, 0, len(stations))
+	for _, station := range stations {
+		client := Client{
+			MACAddress: station.MACAddress.String(),
+			Signal:     station.Signal,
+			Connected:  station.Connected,
+			Inactive:   station.Inactive,
+		}
+		// Note: a device may have had several leases, e.g. if it was assigned a new address after
+		// its previous lease expired; only the lease which expires last is relevant
+		for _, lease := range leases {
+			if !bytes.Equal(lease.MACAddress, station.MACAddress) {
+				continue
+			}
+			if client.IPAddress.IsValid() && lease.Expiry.Before(client.LeaseExpiry) {
+				continue
+			}
+			client.IPAddress = lease.IPAddress
+			client.Hostname = lease.Hostname
+			client.LeaseExpiry = lease.Expiry
+		}
+		clients = append(clients, client)
+	}
+	slices.SortFunc(clients, func(a, b Client) int {
+		return cmp.Compare(b.Connected, a.Connected) // longest-connected devices first
+	})
+	return clients, nil
+}
+
+// ListWiredClients lists the devices which hold DHCP leases which NetworkManager handed out for the
+// shared connection on the specified wired network interface. Unlike for Wi-Fi hotspots, the
+// kernel doesn't track which devices are attached, so devices whose leases expired are omitted.
+// Reading this information requires root privileges.
+func ListWiredClients(iface string) ([]Client, error) {
+	leases, err := readLeases(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't read DHCP leases for %s", iface)
+	}
+
+	now := time.Now()
+	clients := make([]Client, 0, len(leases))
+	for _, lease := range leases {
+		if !lease.Expiry.IsZero() && lease.Expiry.Before(now) {
+			continue
+		}
+		client := Client{
+			MACAddress:  lease.MACAddress.String(),
+			IPAddress:   lease.IPAddress,
+			Hostname:    lease.Hostname,
+			LeaseExpiry: lease.Expiry,
+		}
+		// Note: as for hotspots, only the lease which expires last is relevant for each device
+		i := slices.IndexFunc(clients, func(c Client) bool {
+			return c.MACAddress == client.MACAddress
+		})
+		switch {
+		case i < 0:
+			clients = append(clients, client)
+		case !client.LeaseExpiry.Before(clients[i].LeaseExpiry):
+			clients[i] = client
+		}
+	}
+	slices.SortFunc(clients, func(a, b Client) int {
+		return a.IPAddress.Compare(b.IPAddress)
+	})
+	return clients, nil
+}
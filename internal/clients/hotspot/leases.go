@@ -0,0 +1,76 @@
+package hotspot
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// leasesFileTemplate is where the dnsmasq instance which NetworkManager runs for a shared-mode
+// connection stores its DHCP leases.
+const leasesFileTemplate = "/var/lib/NetworkManager/dnsmasq-%s.leases"
+
+type lease struct {
+	Expiry     time.Time
+	MACAddress net.HardwareAddr
+	IPAddress  netip.Addr
+	Hostname   string
+}
+
+func readLeases(iface string) ([]lease, error) {
+	data, err := os.ReadFile(fmt.Sprintf(leasesFileTemplate, iface))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil // no device has requested a lease yet
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseLeases(data), nil
+}
+
+// parseLeases parses the IPv4 leases in a dnsmasq leases file, whose lines are formatted like
+// "<expiry unix time> <MAC address> <IP address> <hostname or *> <client ID or *>". Other lines
+// (e.g. for DHCPv6 leases) are ignored.
+func parseLeases(data []byte) []lease {
+	var leases []lease
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		const minFields = 4
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < minFields {
+			continue
+		}
+		rawExpiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		macAddress, err := net.ParseMAC(fields[1])
+		if err != nil {
+			continue
+		}
+		ipAddress, err := netip.ParseAddr(fields[2])
+		if err != nil {
+			continue
+		}
+		l := lease{
+			MACAddress: macAddress,
+			IPAddress:  ipAddress,
+		}
+		if rawExpiry > 0 { // dnsmasq uses 0 for leases which never expire
+			l.Expiry = time.Unix(rawExpiry, 0)
+		}
+		if fields[3] != "*" {
+			l.Hostname = fields[3]
+		}
+		leases = append(leases, l)
+	}
+	return leases
+}
@@ -0,0 +1,107 @@
+package hotspot
+
+import (
+	"net"
+	"time"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/pkg/errors"
+)
+
+// Constants from linux/nl80211.h
+const (
+	nl80211Family = "nl80211"
+
+	nl80211CmdGetStation = 17
+
+	nl80211AttrIfindex = 3
+	nl80211AttrMAC     = 6
+	nl80211AttrStaInfo = 21
+
+	nl80211StaInfoInactiveTime  = 1  // u32, ms
+	nl80211StaInfoSignal        = 7  // s8, dBm
+	nl80211StaInfoConnectedTime = 16 // u32, s
+)
+
+type station struct {
+	MACAddress net.HardwareAddr
+	Signal     int // dBm
+	Connected  time.Duration
+	Inactive   time.Duration
+}
+
+// listStations asks the kernel which stations are associated with the access point on the
+// specified network interface, like `iw dev <iface> station dump`.
+func listStations(iface string) (stations []station, err error) {
+	netIface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't find network interface %s", iface)
+	}
+
+	conn, err := genetlink.Dial(nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open generic netlink connection")
+	}
+	defer func() {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "couldn't close generic netlink connection")
+		}
+	}()
+	family, err := conn.GetFamily(nl80211Family)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't find generic netlink family %s", nl80211Family)
+	}
+
+	ae := netlink.NewAttributeEncoder()
+	ae.Uint32(nl80211AttrIfindex, uint32(netIface.Index)) //nolint:gosec // indices are positive
+	data, err := ae.Encode()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't encode request attributes")
+	}
+	messages, err := conn.Execute(genetlink.Message{
+		Header: genetlink.Header{Command: nl80211CmdGetStation, Version: family.Version},
+		Data:   data,
+	}, family.ID, netlink.Request|netlink.Dump)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't request station information")
+	}
+
+	stations = make([]station, 0, len(messages))
+	for _, message := range messages {
+		s, err := parseStation(message.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't parse station information")
+		}
+		stations = append(stations, s)
+	}
+	return stations, nil
+}
+
+func parseStation(data []byte) (s station, err error) {
+	ad, err := netlink.NewAttributeDecoder(data)
+	if err != nil {
+		return s, err
+	}
+	for ad.Next() {
+		switch ad.Type() {
+		case nl80211AttrMAC:
+			s.MACAddress = net.HardwareAddr(ad.Bytes())
+		case nl80211AttrStaInfo:
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					switch nad.Type() {
+					case nl80211StaInfoInactiveTime:
+						s.Inactive = time.Duration(nad.Uint32()) * time.Millisecond
+					case nl80211StaInfoSignal:
+						s.Signal = int(nad.Int8())
+					case nl80211StaInfoConnectedTime:
+						s.Connected = time.Duration(nad.Uint32()) * time.Second
+					}
+				}
+				return nil
+			})
+		}
+	}
+	return s, ad.Err()
+}
@@ -0,0 +1,57 @@
+// Package modemmanager provides an interface for ModemManager via its D-Bus API.
+package modemmanager
+
+import (
+	"context"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+)
+
+type Client struct {
+	Config Config
+
+	bus *dbus.Conn
+
+	l godest.Logger
+}
+
+type Config struct{}
+
+func NewClient(c Config, l godest.Logger) *Client {
+	return &Client{
+		Config: c,
+		l:      l,
+	}
+}
+
+func (c *Client) Open(ctx context.Context) (err error) {
+	if c.bus, err = dbus.ConnectSystemBus(dbus.WithContext(ctx)); err != nil {
+		return errors.Wrap(err, "couldn't connect to SystemBus bus to interact with ModemManager")
+	}
+	return nil
+}
+
+func (c *Client) getModemManager() dbus.BusObject {
+	return c.bus.Object(mmName, "/org/freedesktop/ModemManager1")
+}
+
+const mmName = "org.freedesktop.ModemManager1"
+
+type EnumInfo struct {
+	Short   string
+	Details string
+	Level   string
+}
+
+func storeVar[T any](properties map[string]dbus.Variant, key string, value *T) error {
+	rawValue, ok := properties[key]
+	if !ok {
+		return errors.Errorf("missing property %s", key)
+	}
+	if err := rawValue.Store(value); err != nil {
+		return errors.Wrapf(err, "couldn't parse property %s", key)
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+package modemmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+type Modem struct {
+	Manufacturer string
+	Model        string
+	Revision     string
+	// EquipmentID is the IMEI for GSM/UMTS/LTE modems
+	EquipmentID string
+	// PrimaryPort is the name of the modem's control port, which NetworkManager reports as the control
+	// interface of the modem's device
+	PrimaryPort        string
+	State              ModemState
+	StateFailedReason  ModemStateFailedReason
+	UnlockRequired     ModemLock
+	AccessTechnologies ModemAccessTechnologies
+	SignalQuality      uint32 // percent
+	// SignalRecent is false if the signal quality is a cached value which may be outdated
+	SignalRecent bool
+	OwnNumbers   []string
+	SIM          SIM
+	Registration Registration3GPP
+}
+
+// SIMState describes whether the modem's SIM card can be used.
+func (m Modem) SIMState() EnumInfo {
+	switch m.StateFailedReason {
+	case 2:
+		return EnumInfo{
+			Short:   "missing",
+			Details: "no SIM card is inserted",
+			Level:   "error",
+		}
+	case 3:
+		return EnumInfo{
+			Short:   "error",
+			Details: "the SIM card is unusable or damaged",
+			Level:   "error",
+		}
+	}
+	if lock := m.UnlockRequired; lock > 1 {
+		info := lock.Info()
+		return EnumInfo{
+			Short:   "locked",
+			Details: fmt.Sprintf("the SIM card must be unlocked with a %s", info.Short),
+			Level:   "warning",
+		}
+	}
+	if !m.SIM.HasData() {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: "the modem hasn't reported a SIM card yet",
+			Level:   "warning",
+		}
+	}
+	return EnumInfo{
+		Short: "ready",
+		Level: "success",
+	}
+}
+
+type SIM struct {
+	// Identifier is the ICCID of the SIM card
+	Identifier   string
+	IMSI         string
+	OperatorName string
+}
+
+func (s SIM) HasData() bool {
+	return s.Identifier != "" || s.IMSI != ""
+}
+
+// Registration3GPP describes the modem's registration with a GSM/UMTS/LTE network.
+type Registration3GPP struct {
+	State        Modem3GPPRegistrationState
+	OperatorCode string // MCC and MNC of the network
+	OperatorName string
+}
+
+// ModemState
+
+type ModemState int32
+
+var modemStateInfo = map[ModemState]EnumInfo{
+	-1: {
+		Short:   "failed",
+		Details: "the modem is unusable",
+		Level:   "error",
+	},
+	0: {
+		Short:   "unknown",
+		Details: "state unknown or not reportable",
+		Level:   "warning",
+	},
+	1: {
+		Short:   "initializing",
+		Details: "the modem is currently being initialized",
+		Level:   "info",
+	},
+	2: {
+		Short:   "locked",
+		Details: "the modem needs to be unlocked",
+		Level:   "warning",
+	},
+	3: {
+		Short:   "disabled",
+		Details: "the modem is not enabled and is powered down",
+		Level:   "warning",
+	},
+	4: {
+		Short:   "disabling",
+		Details: "the modem is currently transitioning to the disabled state",
+		Level:   "info",
+	},
+	5: {
+		Short:   "enabling",
+		Details: "the modem is currently transitioning to the enabled state",
+		Level:   "info",
+	},
+	6: {
+		Short:   "enabled",
+		Details: "the modem is enabled and powered on but not registered with a network provider",
+		Level:   "warning",
+	},
+	7: {
+		Short:   "searching",
+		Details: "the modem is searching for a network provider to register with",
+		Level:   "info",
+	},
+	8: {
+		Short:   "registered",
+		Details: "the modem is registered with a network provider but has no data connection",
+		Level:   "info",
+	},
+	9: {
+		Short:   "disconnecting",
+		Details: "the modem is disconnecting and deactivating the last active data connection",
+		Level:   "info",
+	},
+	10: {
+		Short:   "connecting",
+		Details: "the modem is activating and connecting the first data connection",
+		Level:   "info",
+	},
+	11: {
+		Short:   "connected",
+		Details: "the modem has an active data connection",
+		Level:   "success",
+	},
+}
+
+func (s ModemState) Info() EnumInfo {
+	info, ok := modemStateInfo[s]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown state (%d)", s),
+			Level:   "error",
+		}
+	}
+	return info
+}
+
+// ModemStateFailedReason
+
+type ModemStateFailedReason uint32
+
+var modemStateFailedReasonInfo = map[ModemStateFailedReason]EnumInfo{
+	0: {
+		Short: "none",
+		Level: "info",
+	},
+	1: {
+		Short:   "unknown",
+		Details: "unknown error",
+		Level:   "error",
+	},
+	2: {
+		Short:   "SIM missing",
+		Details: "the modem needs a SIM card, but none is inserted",
+		Level:   "error",
+	},
+	3: {
+		Short:   "SIM error",
+		Details: "the SIM card is inserted but unusable",
+		Level:   "error",
+	},
+	4: {
+		Short:   "unknown capabilities",
+		Details: "the modem's capabilities couldn't be determined",
+		Level:   "error",
+	},
+	5: {
+		Short:   "eSIM without profiles",
+		Details: "the modem has an eSIM without any profiles",
+		Level:   "error",
+	},
+}
+
+func (r ModemStateFailedReason) Info() EnumInfo {
+	info, ok := modemStateFailedReasonInfo[r]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown reason (%d)", r),
+			Level:   "error",
+		}
+	}
+	return info
+}
+
+// ModemLock
+
+type ModemLock uint32
+
+var modemLockInfo = map[ModemLock]EnumInfo{
+	0: {
+		Short:   "unknown",
+		Details: "lock reason unknown",
+		Level:   "warning",
+	},
+	1: {
+		Short:   "none",
+		Details: "the modem is unlocked",
+		Level:   "success",
+	},
+	2: {
+		Short:   "SIM PIN",
+		Details: "the SIM card requires its PIN code",
+		Level:   "warning",
+	},
+	3: {
+		Short:   "SIM PIN2",
+		Details: "the SIM card requires its PIN2 code",
+		Level:   "warning",
+	},
+	4: {
+		Short:   "SIM PUK",
+		Details: "the SIM card requires its PUK code, because the PIN was entered wrongly too often",
+		Level:   "error",
+	},
+	5: {
+		Short:   "SIM PUK2",
+		Details: "the SIM card requires its PUK2 code",
+		Level:   "error",
+	},
+}
+
+func (l ModemLock) Info() EnumInfo {
+	info, ok := modemLockInfo[l]
+	if !ok {
+		return EnumInfo{
+			Short:   "network or operator code",
+			Details: fmt.Sprintf("the modem requires a network or operator unlock code (%d)", l),
+			Level:   "error",
+		}
+	}
+	return info
+}
+
+// ModemAccessTechnologies
+
+type ModemAccessTechnologies uint32
+
+// modemAccessTechnologyNames are the names of the bits of [ModemAccessTechnologies], in order.
+var modemAccessTechnologyNames = []string{
+	"POTS", "GSM", "GSM Compact", "GPRS", "EDGE", "UMTS", "HSDPA", "HSUPA", "HSPA", "HSPA+",
+	"1xRTT", "EVDO rev. 0", "EVDO rev. A", "EVDO rev. B", "LTE", "5G NR", "LTE Cat-M", "NB-IoT",
+}
+
+// Names returns the names of the access technologies, from oldest to newest.
+func (t ModemAccessTechnologies) Names() []string {
+	names := make([]string, 0)
+	for i, name := range modemAccessTechnologyNames {
+		if t&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Modem3GPPRegistrationState
+
+type Modem3GPPRegistrationState uint32
+
+var modem3GPPRegistrationStateInfo = map[Modem3GPPRegistrationState]EnumInfo{
+	0: {
+		Short:   "idle",
+		Details: "not registered and not searching for a network",
+		Level:   "warning",
+	},
+	1: {
+		Short:   "home",
+		Details: "registered with the home network",
+		Level:   "success",
+	},
+	2: {
+		Short:   "searching",
+		Details: "searching for a network",
+		Level:   "info",
+	},
+	3: {
+		Short:   "denied",
+		Details: "registration was denied by the network",
+		Level:   "error",
+	},
+	4: {
+		Short:   "unknown",
+		Details: "unknown registration status",
+		Level:   "warning",
+	},
+	5: {
+		Short:   "roaming",
+		Details: "registered with a roaming network",
+		Level:   "success",
+	},
+	6: {
+		Short:   "home, SMS only",
+		Details: "registered with the home network for SMS only",
+		Level:   "warning",
+	},
+	7: {
+		Short:   "roaming, SMS only",
+		Details: "registered with a roaming network for SMS only",
+		Level:   "warning",
+	},
+	8: {
+		Short:   "emergency only",
+		Details: "only emergency services are available",
+		Level:   "error",
+	},
+}
+
+func (s Modem3GPPRegistrationState) Info() EnumInfo {
+	info, ok := modem3GPPRegistrationStateInfo[s]
+	if !ok {
+		return EnumInfo{
+			Short:   "registered",
+			Details: fmt.Sprintf("registered, with restrictions (%d)", s),
+			Level:   "info",
+		}
+	}
+	return info
+}
+
+// Queries
+
+func (c *Client) GetModems(ctx context.Context) (modems []Modem, err error) {
+	objects := make(map[dbus.ObjectPath]map[string]map[string]dbus.Variant, 0)
+	if err = c.getModemManager().CallWithContext(
+		ctx, "org.freedesktop.DBus.ObjectManager.GetManagedObjects", 0,
+	).Store(&objects); err != nil {
+		return nil, errors.Wrap(err, "couldn't query for managed objects")
+	}
+	modemPaths := make([]dbus.ObjectPath, 0, len(objects))
+	for objectPath := range objects {
+		if !strings.HasPrefix(string(objectPath), "/org/freedesktop/ModemManager1/Modem/") {
+			continue
+		}
+		modemPaths = append(modemPaths, objectPath)
+	}
+	slices.Sort(modemPaths)
+
+	for _, modemPath := range modemPaths {
+		modem, err := dumpModem(ctx, objects[modemPath], c.bus)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't dump modem %s", modemPath)
+		}
+		modems = append(modems, modem)
+	}
+	return modems, nil
+}
+
+func dumpModem(
+	ctx context.Context, interfaces map[string]map[string]dbus.Variant, bus *dbus.Conn,
+) (modem Modem, err error) {
+	properties := interfaces[mmName+".Modem"]
+	if err = storeVar(properties, "Manufacturer", &modem.Manufacturer); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "Model", &modem.Model); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "Revision", &modem.Revision); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "EquipmentIdentifier", &modem.EquipmentID); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "PrimaryPort", &modem.PrimaryPort); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "State", &modem.State); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "StateFailedReason", &modem.StateFailedReason); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "UnlockRequired", &modem.UnlockRequired); err != nil {
+		return Modem{}, err
+	}
+	if err = storeVar(properties, "AccessTechnologies", &modem.AccessTechnologies); err != nil {
+		return Modem{}, err
+	}
+	var signalQuality struct {
+		Quality uint32
+		Recent  bool
+	}
+	if err = storeVar(properties, "SignalQuality", &signalQuality); err != nil {
+		return Modem{}, err
+	}
+	modem.SignalQuality = signalQuality.Quality
+	modem.SignalRecent = signalQuality.Recent
+	if err = storeVar(properties, "OwnNumbers", &modem.OwnNumbers); err != nil {
+		return Modem{}, err
+	}
+
+	var simPath dbus.ObjectPath
+	if err = storeVar(properties, "Sim", &simPath); err != nil {
+		return Modem{}, err
+	}
+	if simPath != "" && simPath != "/" {
+		if modem.SIM, err = dumpSIM(ctx, bus.Object(mmName, simPath)); err != nil {
+			return Modem{}, errors.Wrapf(err, "couldn't dump SIM %s", simPath)
+		}
+	}
+
+	// Note: the 3GPP interface is only available for GSM/UMTS/LTE modems which are enabled
+	if properties, ok := interfaces[mmName+".Modem.Modem3gpp"]; ok {
+		if modem.Registration, err = dumpRegistration3GPP(properties); err != nil {
+			return Modem{}, errors.Wrap(err, "couldn't dump 3GPP registration")
+		}
+	}
+	return modem, nil
+}
+
+func dumpSIM(ctx context.Context, simo dbus.BusObject) (sim SIM, err error) {
+	properties := make(map[string]dbus.Variant)
+	if err = simo.CallWithContext(
+		ctx, "org.freedesktop.DBus.Properties.GetAll", 0, mmName+".Sim",
+	).Store(&properties); err != nil {
+		return SIM{}, errors.Wrap(err, "couldn't query for properties")
+	}
+	if err = storeVar(properties, "SimIdentifier", &sim.Identifier); err != nil {
+		return SIM{}, err
+	}
+	if err = storeVar(properties, "Imsi", &sim.IMSI); err != nil {
+		return SIM{}, err
+	}
+	if err = storeVar(properties, "OperatorName", &sim.OperatorName); err != nil {
+		return SIM{}, err
+	}
+	return sim, nil
+}
+
+func dumpRegistration3GPP(properties map[string]dbus.Variant) (r Registration3GPP, err error) {
+	if err = storeVar(properties, "RegistrationState", &r.State); err != nil {
+		return Registration3GPP{}, err
+	}
+	if err = storeVar(properties, "OperatorCode", &r.OperatorCode); err != nil {
+		return Registration3GPP{}, err
+	}
+	if err = storeVar(properties, "OperatorName", &r.OperatorName); err != nil {
+		return Registration3GPP{}, err
+	}
+	return r, nil
+}
@@ -10,11 +10,15 @@ import (
 
 type AccessPoint struct {
 	SSID      string
+	BSSID     string
 	Frequency uint32 // MHz
-	Strength  uint8  // %
-	LastSeen  time.Duration
-	Mode      DeviceWifiMode
-	RSN       RSNFlags // for WPA2 & WPA3
+	// Bandwidth is zero if NetworkManager is too old to report it
+	Bandwidth  uint32 // MHz
+	Strength   uint8  // %
+	MaxBitrate uint32 // kb/s
+	LastSeen   time.Duration
+	Mode       DeviceWifiMode
+	RSN        RSNFlags // for WPA2 & WPA3
 }
 
 func (ap AccessPoint) HasData() bool {
@@ -55,6 +59,20 @@ func (f RSNFlags) SupportsEAPSuiteB192() bool {
 
 func (c *Client) ScanNetworks(
 	ctx context.Context, iface string,
+) (networks map[string][]AccessPoint, err error) {
+	if networks, ok := c.getCachedNetworks(iface); ok {
+		return networks, nil
+	}
+	generation := c.cacheGeneration()
+	if networks, err = c.queryNetworks(ctx, iface); err != nil {
+		return nil, err
+	}
+	c.cacheNetworks(generation, iface, networks)
+	return networks, nil
+}
+
+func (c *Client) queryNetworks(
+	ctx context.Context, iface string,
 ) (networks map[string][]AccessPoint, err error) {
 	dev, err := c.findDevice(ctx, iface)
 	if err != nil {
@@ -85,14 +103,25 @@ func dumpAccessPoint(apo dbus.BusObject) (ap AccessPoint, err error) {
 	}
 	ap.SSID = string(rawSSID)
 
+	if err = apo.StoreProperty(nmName+".AccessPoint.HwAddress", &ap.BSSID); err != nil {
+		return AccessPoint{}, errors.Wrap(err, "couldn't query for BSSID")
+	}
+
 	if err = apo.StoreProperty(nmName+".AccessPoint.Frequency", &ap.Frequency); err != nil {
-		return AccessPoint{}, errors.Wrap(err, "couldn't query for SSID")
+		return AccessPoint{}, errors.Wrap(err, "couldn't query for frequency")
 	}
 
+	// Note: NetworkManager only reports the bandwidth since v1.46, so we leave it unknown otherwise
+	_ = apo.StoreProperty(nmName+".AccessPoint.Bandwidth", &ap.Bandwidth)
+
 	if err = apo.StoreProperty(nmName+".AccessPoint.Strength", &ap.Strength); err != nil {
 		return AccessPoint{}, errors.Wrap(err, "couldn't query for signal strength")
 	}
 
+	if err = apo.StoreProperty(nmName+".AccessPoint.MaxBitrate", &ap.MaxBitrate); err != nil {
+		return AccessPoint{}, errors.Wrap(err, "couldn't query for maximum bitrate")
+	}
+
 	var rawLastSeen int32
 	if err = apo.StoreProperty(nmName+".AccessPoint.LastSeen", &rawLastSeen); err != nil {
 		return AccessPoint{}, errors.Wrap(err, "couldn't query for signal strength")
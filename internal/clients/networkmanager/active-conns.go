@@ -28,6 +28,8 @@ func (c ActiveConn) HasData() bool {
 
 type ActiveConnState uint32
 
+const activeConnStateDeactivated ActiveConnState = 4
+
 var activeConnectionStateInfo = map[ActiveConnState]EnumInfo{
 	0: {
 		Short: "unknown",
@@ -48,8 +50,8 @@ var activeConnectionStateInfo = map[ActiveConnState]EnumInfo{
 		Details: "network connection is being torn down and cleaned up",
 		Level:   "info",
 	},
-	4: {
-		Short:   "deactivating",
+	activeConnStateDeactivated: {
+		Short:   "deactivated",
 		Details: "network connection is disconnected and will be removed",
 		Level:   "info",
 	},
@@ -67,6 +69,79 @@ func (s ActiveConnState) Info() EnumInfo {
 	return info
 }
 
+type ActiveConnStateReason uint32
+
+var activeConnStateReasons = map[ActiveConnStateReason]EnumInfo{
+	0: {
+		Short: "unknown",
+	},
+	1: {},
+	2: {
+		Short:   "user disconnected",
+		Details: "disconnected by the user",
+	},
+	3: {
+		Short:   "device disconnected",
+		Details: "the base network connection was interrupted",
+	},
+	4: {
+		Short:   "service stopped",
+		Details: "the VPN service stopped unexpectedly",
+	},
+	5: {
+		Short:   "IP config invalid",
+		Details: "the VPN service returned an invalid configuration",
+	},
+	6: {
+		Short:   "connect timeout",
+		Details: "the connection attempt timed out",
+	},
+	7: {
+		Short:   "service start timeout",
+		Details: "the VPN service didn't start in time",
+	},
+	8: {
+		Short:   "service start failed",
+		Details: "the VPN service failed to start",
+	},
+	9: {
+		Short:   "no secrets",
+		Details: "necessary secrets weren't provided",
+	},
+	10: {
+		Short:   "login failed",
+		Details: "authentication to the server failed",
+	},
+	11: {
+		Short:   "connection removed",
+		Details: "the connection profile was deleted",
+	},
+	12: {
+		Short:   "dependency failed",
+		Details: "a connection which this connection depends on failed",
+	},
+	13: {
+		Short:   "device realize failed",
+		Details: "the software device couldn't be created",
+	},
+	14: {
+		Short:   "device removed",
+		Details: "the device was removed",
+	},
+}
+
+func (r ActiveConnStateReason) Info() EnumInfo {
+	info, ok := activeConnStateReasons[r]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("reason (%d) was reported but could not be determined", r),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
 type ConnectionActivationStateFlags uint32
 
 func (f ConnectionActivationStateFlags) HasNone() bool {
@@ -151,6 +226,9 @@ func dumpActiveConn(conno dbus.BusObject, bus *dbus.Conn) (conn ActiveConn, err
 	if err = conno.StoreProperty(connName+".Vpn", &conn.IsVPN); err != nil {
 		return ActiveConn{}, errors.Wrap(err, "couldn't query for VPN")
 	}
+	// Note: NetworkManager only reports connections of VPN plugins as VPNs, but WireGuard
+	// connections are also VPN tunnels from the user's perspective
+	conn.IsVPN = conn.IsVPN || conn.Type == "wireguard"
 
 	if conn.DeviceInterfaces, err = dumpActiveConnDevices(conno, bus); err != nil {
 		return ActiveConn{}, errors.Wrap(err, "couldn't query for interface names of devices")
@@ -0,0 +1,176 @@
+package networkmanager
+
+import (
+	"context"
+	"path"
+	"slices"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const checkpointPathPrefix = "/org/freedesktop/NetworkManager/Checkpoint/"
+
+// Checkpoint is a snapshot of network devices and connection profiles, which NetworkManager will
+// automatically roll back to when its rollback timeout elapses, unless it's destroyed first.
+type Checkpoint struct {
+	// ID is the last element of the checkpoint's D-Bus object path.
+	ID              string
+	Created         time.Time
+	RollbackTimeout time.Duration
+}
+
+// RollbackTime returns the time when NetworkManager will automatically roll back to the checkpoint.
+func (c Checkpoint) RollbackTime() time.Time {
+	return c.Created.Add(c.RollbackTimeout)
+}
+
+type CheckpointCreateFlags uint32
+
+const (
+	CheckpointCreateFlagDestroyAll              = 0x1
+	CheckpointCreateFlagDeleteNewConnections    = 0x2
+	CheckpointCreateFlagDisconnectNewDevices    = 0x4
+	CheckpointCreateFlagAllowOverlapping        = 0x8
+	CheckpointCreateFlagNoPreserveExternalPorts = 0x10
+)
+
+func checkpointPath(id string) (dbus.ObjectPath, error) {
+	p := dbus.ObjectPath(checkpointPathPrefix + id)
+	if id == "" || path.Base(string(p)) != id || !p.IsValid() {
+		return "", errors.Errorf("invalid checkpoint id %s", id)
+	}
+	return p, nil
+}
+
+// ErrCheckpointExists is returned when a checkpoint can't be created because another checkpoint
+// hasn't been destroyed or rolled back yet.
+var ErrCheckpointExists = errors.New("another checkpoint already exists")
+
+// ListCheckpointIDs returns the IDs of all checkpoints which haven't been destroyed or rolled back
+// yet.
+func (c *Client) ListCheckpointIDs() ([]string, error) {
+	nm := c.getNetworkManager()
+	var checkpointPaths []dbus.ObjectPath
+	if err := nm.StoreProperty(nmName+".Checkpoints", &checkpointPaths); err != nil {
+		return nil, errors.Wrap(err, "couldn't query for checkpoints")
+	}
+	ids := make([]string, 0, len(checkpointPaths))
+	for _, p := range checkpointPaths {
+		ids = append(ids, path.Base(string(p)))
+	}
+	return ids, nil
+}
+
+// CreateCheckpoint creates a checkpoint of all network devices. If the checkpoint isn't destroyed
+// before the rollback timeout elapses, NetworkManager will restore all devices and connection
+// profiles to their current states. It returns an error wrapping ErrCheckpointExists if another
+// checkpoint exists, since replacing that checkpoint would keep someone else's unconfirmed changes
+// without their consent.
+func (c *Client) CreateCheckpoint(
+	ctx context.Context, rollbackTimeout time.Duration,
+) (id string, err error) {
+	ids, err := c.ListCheckpointIDs()
+	if err != nil {
+		return "", err
+	}
+	if len(ids) > 0 {
+		return "", errors.Wrapf(
+			ErrCheckpointExists, "changes since checkpoint %s are still waiting for confirmation", ids[0],
+		)
+	}
+
+	nm := c.getNetworkManager()
+	var flags CheckpointCreateFlags = CheckpointCreateFlagDeleteNewConnections |
+		CheckpointCreateFlagDisconnectNewDevices
+	var p dbus.ObjectPath
+	if err = nm.CallWithContext(
+		ctx, nmName+".CheckpointCreate", 0,
+		[]dbus.ObjectPath{}, uint32(rollbackTimeout.Seconds()), uint32(flags),
+	).Store(&p); err != nil {
+		return "", errors.Wrap(err, "couldn't create checkpoint")
+	}
+	return path.Base(string(p)), nil
+}
+
+// DestroyCheckpoint destroys the checkpoint without rolling back to it, so that all changes since
+// the checkpoint was created are kept.
+func (c *Client) DestroyCheckpoint(ctx context.Context, id string) error {
+	p, err := checkpointPath(id)
+	if err != nil {
+		return err
+	}
+	nm := c.getNetworkManager()
+	if err = nm.CallWithContext(
+		ctx, nmName+".CheckpointDestroy", 0, p,
+	).Store(); err != nil {
+		return errors.Wrapf(err, "couldn't destroy checkpoint %s", id)
+	}
+	return nil
+}
+
+// RollbackCheckpoint immediately rolls back to the checkpoint, which also destroys the checkpoint.
+func (c *Client) RollbackCheckpoint(ctx context.Context, id string) error {
+	p, err := checkpointPath(id)
+	if err != nil {
+		return err
+	}
+	nm := c.getNetworkManager()
+	var results map[dbus.ObjectPath]uint32
+	if err = nm.CallWithContext(
+		ctx, nmName+".CheckpointRollback", 0, p,
+	).Store(&results); err != nil {
+		return errors.Wrapf(err, "couldn't roll back to checkpoint %s", id)
+	}
+	for devPath, result := range results {
+		const rollbackResultOK = 0
+		if result != rollbackResultOK {
+			return errors.Errorf(
+				"couldn't roll back device %s to checkpoint %s (result %d)", devPath, id, result,
+			)
+		}
+	}
+	return nil
+}
+
+// GetCheckpoint returns the specified checkpoint, if it still exists.
+func (c *Client) GetCheckpoint(id string) (checkpoint Checkpoint, err error) {
+	p, err := checkpointPath(id)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	ids, err := c.ListCheckpointIDs()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if !slices.Contains(ids, id) {
+		return Checkpoint{}, errors.Errorf("checkpoint %s not found", id)
+	}
+
+	return dumpCheckpoint(c.bus.Object(nmName, p), id)
+}
+
+func dumpCheckpoint(checkpointo dbus.BusObject, id string) (checkpoint Checkpoint, err error) {
+	checkpoint.ID = id
+	const checkpointName = nmName + ".Checkpoint"
+	// Note: Created is reported in milliseconds of CLOCK_BOOTTIME, not as a wall-clock time
+	var rawCreated int64
+	if err = checkpointo.StoreProperty(checkpointName+".Created", &rawCreated); err != nil {
+		return Checkpoint{}, errors.Wrap(err, "couldn't query for creation time")
+	}
+	var now unix.Timespec
+	if err = unix.ClockGettime(unix.CLOCK_BOOTTIME, &now); err != nil {
+		return Checkpoint{}, errors.Wrap(err, "couldn't query for system boot time")
+	}
+	age := time.Duration(now.Nano()) - time.Duration(rawCreated)*time.Millisecond
+	checkpoint.Created = time.Now().Add(-age)
+
+	var rawTimeout uint32
+	if err = checkpointo.StoreProperty(checkpointName+".RollbackTimeout", &rawTimeout); err != nil {
+		return Checkpoint{}, errors.Wrap(err, "couldn't query for rollback timeout")
+	}
+	checkpoint.RollbackTimeout = time.Duration(rawTimeout) * time.Second
+	return checkpoint, nil
+}
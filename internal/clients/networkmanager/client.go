@@ -13,17 +13,19 @@ import (
 type Client struct {
 	Config Config
 
-	bus *dbus.Conn
+	bus     *dbus.Conn
+	model   *model
+	history *history
 
 	l godest.Logger
 }
 
-type Config struct{}
-
 func NewClient(c Config, l godest.Logger) *Client {
 	return &Client{
-		Config: c,
-		l:      l,
+		Config:  c,
+		model:   newModel(),
+		history: newHistory(c.HistoryPath, c.HistorySize),
+		l:       l,
 	}
 }
 
@@ -31,6 +33,14 @@ func (c *Client) Open(ctx context.Context) (err error) {
 	if c.bus, err = dbus.ConnectSystemBus(dbus.WithContext(ctx)); err != nil {
 		return errors.Wrap(err, "couldn't connect to SystemBus bus to interact with NetworkManager")
 	}
+	if err = c.history.load(); err != nil {
+		c.l.Warn(errors.Wrap(err, "couldn't load state history, so it will only be kept in memory"))
+	}
+	if err = c.watchSignals(ctx); err != nil {
+		// Without signals, we can still query NetworkManager directly; subscribers will just be
+		// notified periodically rather than on changes
+		c.l.Warn(errors.Wrap(err, "couldn't watch for changes to NetworkManager's state"))
+	}
 	return nil
 }
 
@@ -155,6 +165,18 @@ func (c NetworkManagerConnectivity) Info() EnumInfo {
 }
 
 func (c *Client) Get() (nm NetworkManager, err error) {
+	if nm, ok := c.getCachedNetworkManager(); ok {
+		return nm, nil
+	}
+	generation := c.cacheGeneration()
+	if nm, err = c.query(); err != nil {
+		return nm, err
+	}
+	c.cacheNetworkManager(generation, nm)
+	return nm, nil
+}
+
+func (c *Client) query() (nm NetworkManager, err error) {
 	nmo := c.getNetworkManager()
 
 	if err = nmo.StoreProperty(nmName+".NetworkingEnabled", &nm.NetworkingEnabled); err != nil {
@@ -199,3 +221,16 @@ func (c *Client) Get() (nm NetworkManager, err error) {
 
 	return nm, nil
 }
+
+// CheckConnectivity makes NetworkManager immediately re-check the internet connectivity (e.g. after
+// the user has logged in through a captive portal), and returns the resulting connectivity.
+func (c *Client) CheckConnectivity(ctx context.Context) (NetworkManagerConnectivity, error) {
+	var rawUint uint32
+	if err := c.getNetworkManager().CallWithContext(
+		ctx, nmName+".CheckConnectivity", 0,
+	).Store(&rawUint); err != nil {
+		return 0, errors.Wrap(err, "couldn't check connectivity")
+	}
+	c.invalidate()
+	return NetworkManagerConnectivity(rawUint), nil
+}
@@ -0,0 +1,33 @@
+package networkmanager
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest/env"
+)
+
+const envPrefix = "NETWORKMANAGER_"
+
+type Config struct {
+	// HistoryPath is the path of the file in which state transitions of devices and active
+	// connections are recorded; if it's empty, state transitions are only kept in memory.
+	HistoryPath string
+	// HistorySize is the maximum number of state transitions which are kept.
+	HistorySize int
+}
+
+func GetConfig() (c Config, err error) {
+	const defaultHistoryPath = "/var/lib/machine-admin/networkmanager-history.jsonl"
+	c.HistoryPath = env.GetString(envPrefix+"HISTORY_PATH", defaultHistoryPath)
+
+	const defaultHistorySize = 2000
+	rawHistorySize, err := env.GetInt64(envPrefix+"HISTORY_SIZE", defaultHistorySize)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "couldn't make history size config")
+	}
+	if rawHistorySize < 1 {
+		return Config{}, errors.Errorf("history size %d must be positive", rawHistorySize)
+	}
+	c.HistorySize = int(rawHistorySize)
+
+	return c, nil
+}
@@ -0,0 +1,198 @@
+package networkmanager
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/google/uuid"
+)
+
+type ConnProfileSettings8021x struct {
+	AnonymousIdentity string
+	CACert            ConnProfileSettings8021xCert
+	ClientCert        ConnProfileSettings8021xCert
+	DomainSuffixMatch string
+	EAP               ConnProfileSettings8021xEAP
+	Identity          string
+	// Warning: NetworkManager only returns the real password if we're running as root; otherwise,
+	// it returns an empty string!
+	Password           string
+	Phase2Auth         ConnProfileSettings8021xPhase2Auth
+	PrivateKey         ConnProfileSettings8021xCert
+	PrivateKeyPassword string
+}
+
+// ConnProfileSettings8021xEAP is the EAP method. NetworkManager allows a list of methods, but
+// networks in practice expect exactly one, so we only represent the first method of the list.
+type ConnProfileSettings8021xEAP string
+
+var connProfileSettings8021xEAPInfo = map[ConnProfileSettings8021xEAP]EnumInfo{
+	"peap": {
+		Short:   "PEAP",
+		Details: "Protected EAP, with a username and password",
+	},
+	"ttls": {
+		Short:   "TTLS",
+		Details: "Tunneled Transport Layer Security, with a username and password",
+	},
+	"tls": {
+		Short:   "TLS",
+		Details: "Transport Layer Security, with a client certificate",
+	},
+}
+
+func (m ConnProfileSettings8021xEAP) Info() EnumInfo {
+	info, ok := connProfileSettings8021xEAPInfo[m]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown EAP method (%s)", m),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+type ConnProfileSettings8021xPhase2Auth string
+
+var connProfileSettings8021xPhase2AuthInfo = map[ConnProfileSettings8021xPhase2Auth]EnumInfo{
+	"": {
+		Short:   "none",
+		Details: "no inner authentication",
+	},
+	"mschapv2": {
+		Short: "MSCHAPv2",
+	},
+	"mschap": {
+		Short: "MSCHAP",
+	},
+	"pap": {
+		Short: "PAP",
+	},
+	"chap": {
+		Short: "CHAP",
+	},
+	"gtc": {
+		Short: "GTC",
+	},
+	"md5": {
+		Short: "MD5",
+	},
+}
+
+func (a ConnProfileSettings8021xPhase2Auth) Info() EnumInfo {
+	info, ok := connProfileSettings8021xPhase2AuthInfo[a]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown phase 2 authentication method (%s)", a),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+// ConnProfileSettings8021xCert refers to a certificate or private key, which NetworkManager either
+// reads from a file or stores embedded in the connection profile.
+type ConnProfileSettings8021xCert struct {
+	Path     string
+	Embedded bool
+}
+
+func (c ConnProfileSettings8021xCert) IsSet() bool {
+	return c.Path != "" || c.Embedded
+}
+
+// CertsDir is where the admin panel stores the certificate and private key files referenced by
+// connection profiles' 802.1X settings, in a subdirectory for each connection profile.
+const CertsDir = "/etc/NetworkManager/certs"
+
+// StoredCertPath returns the path at which the admin panel stores a certificate or private key file
+// of the kind (e.g. "ca-cert") for the connection profile. The extension is ".pem" for PEM-encoded
+// files, and ".der" for DER-encoded files.
+func StoredCertPath(uid uuid.UUID, kind, ext string) string {
+	return path.Join(CertsDir, uid.String(), kind+ext)
+}
+
+// IsStoredCertPath checks whether the path is where the admin panel stores a certificate or private
+// key file of the kind for the connection profile.
+func IsStoredCertPath(uid uuid.UUID, kind, p string) bool {
+	return p == StoredCertPath(uid, kind, ".pem") || p == StoredCertPath(uid, kind, ".der")
+}
+
+// certPathScheme is how NetworkManager marks certificate values which are paths rather than blobs.
+const certPathScheme = "file://"
+
+func parseConnProfileSettings8021xCert(raw []byte) ConnProfileSettings8021xCert {
+	if len(raw) == 0 {
+		return ConnProfileSettings8021xCert{}
+	}
+	if !bytes.HasPrefix(raw, []byte(certPathScheme)) {
+		return ConnProfileSettings8021xCert{Embedded: true}
+	}
+	return ConnProfileSettings8021xCert{
+		Path: string(bytes.TrimSuffix(bytes.TrimPrefix(raw, []byte(certPathScheme)), []byte{0})),
+	}
+}
+
+func (c ConnProfileSettings8021xCert) dbusValue() []byte {
+	// Note: NetworkManager requires paths to be NUL-terminated
+	return append([]byte(certPathScheme+c.Path), 0)
+}
+
+func dumpConnProfileSettings8021x(
+	rawSettings map[string]dbus.Variant, rawSecrets map[string]dbus.Variant,
+) (s ConnProfileSettings8021x, err error) {
+	if s.AnonymousIdentity, err = ensureVar(
+		rawSettings, "anonymous-identity", "", false, "",
+	); err != nil {
+		return s, err
+	}
+	if s.DomainSuffixMatch, err = ensureVar(
+		rawSettings, "domain-suffix-match", "", false, "",
+	); err != nil {
+		return s, err
+	}
+	rawEAP, err := ensureVar(rawSettings, "eap", "EAP methods", false, []string{})
+	if err != nil {
+		return s, err
+	}
+	if len(rawEAP) > 0 {
+		s.EAP = ConnProfileSettings8021xEAP(rawEAP[0])
+	}
+	if s.Identity, err = ensureVar(rawSettings, "identity", "", false, ""); err != nil {
+		return s, err
+	}
+	rawPhase2Auth, err := ensureVar(
+		rawSettings, "phase2-auth", "phase 2 authentication method", false, "",
+	)
+	if err != nil {
+		return s, err
+	}
+	s.Phase2Auth = ConnProfileSettings8021xPhase2Auth(rawPhase2Auth)
+
+	for key, cert := range map[string]*ConnProfileSettings8021xCert{
+		"ca-cert":     &s.CACert,
+		"client-cert": &s.ClientCert,
+		"private-key": &s.PrivateKey,
+	} {
+		raw, err := ensureVar(rawSettings, key, "", false, []byte{})
+		if err != nil {
+			return s, err
+		}
+		*cert = parseConnProfileSettings8021xCert(raw)
+	}
+
+	if s.Password, err = ensureVar(rawSecrets, "password", "", false, ""); err != nil {
+		return s, err
+	}
+	if s.PrivateKeyPassword, err = ensureVar(
+		rawSecrets, "private-key-password", "", false, "",
+	); err != nil {
+		return s, err
+	}
+
+	return s, nil
+}
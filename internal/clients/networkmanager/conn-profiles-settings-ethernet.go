@@ -0,0 +1,157 @@
+package networkmanager
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+type ConnProfileSettings8023Ethernet struct {
+	AssignedMACAddress string
+	AutoNegotiate      bool
+	Duplex             ConnProfileSettings8023EthernetDuplex
+	MTU                uint32
+	Speed              uint32 // Mb/s
+	WakeOnLAN          ConnProfileSettings8023EthernetWoL
+}
+
+// AssignedMACAddressModes are the special values which the assigned-mac-address settings of the
+// 802-3-ethernet and 802-11-wireless sections accept instead of an explicit MAC address.
+var AssignedMACAddressModes = map[string]EnumInfo{
+	"preserve": {
+		Short:   "preserve",
+		Details: "keep the MAC address which the device had before activation",
+	},
+	"permanent": {
+		Short:   "permanent",
+		Details: "use the device's permanent hardware address",
+	},
+	"random": {
+		Short:   "random",
+		Details: "generate a new random MAC address on each connection",
+	},
+	"stable": {
+		Short:   "stable",
+		Details: "generate a random MAC address which stays the same for this connection profile",
+	},
+}
+
+type ConnProfileSettings8023EthernetDuplex string
+
+var connProfileSettings8023EthernetDuplexInfo = map[ConnProfileSettings8023EthernetDuplex]EnumInfo{
+	"": {
+		Short:   "unspecified",
+		Details: "don't require a duplex mode",
+	},
+	"half": {
+		Short: "half",
+	},
+	"full": {
+		Short: "full",
+	},
+}
+
+func (d ConnProfileSettings8023EthernetDuplex) Info() EnumInfo {
+	info, ok := connProfileSettings8023EthernetDuplexInfo[d]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown duplex mode (%s)", d),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+type ConnProfileSettings8023EthernetWoL uint32
+
+// connProfileSettings8023EthernetWoLInfo describes the Wake-on-LAN flags, in the order in which
+// they should be listed.
+var connProfileSettings8023EthernetWoLInfo = []struct {
+	Flag ConnProfileSettings8023EthernetWoL
+	Info EnumInfo
+}{
+	{0x1, EnumInfo{Short: "default", Details: "use the global default"}},
+	{0x2, EnumInfo{Short: "phy", Details: "wake on PHY activity"}},
+	{0x4, EnumInfo{Short: "unicast", Details: "wake on unicast messages"}},
+	{0x8, EnumInfo{Short: "multicast", Details: "wake on multicast messages"}},
+	{0x10, EnumInfo{Short: "broadcast", Details: "wake on broadcast messages"}},
+	{0x20, EnumInfo{Short: "arp", Details: "wake on ARP"}},
+	{0x40, EnumInfo{Short: "magic", Details: "wake on magic packet"}},
+	{0x8000, EnumInfo{Short: "ignore", Details: "leave the device's settings unchanged"}},
+}
+
+// NewConnProfileSettings8023EthernetWoL combines the Wake-on-LAN flags with the specified short
+// names. No flags means that Wake-on-LAN is disabled.
+func NewConnProfileSettings8023EthernetWoL(
+	infoShorts []string,
+) (w ConnProfileSettings8023EthernetWoL, err error) {
+	for _, infoShort := range infoShorts {
+		found := false
+		for _, flag := range connProfileSettings8023EthernetWoLInfo {
+			if flag.Info.Short == infoShort {
+				w |= flag.Flag
+				found = true
+			}
+		}
+		if !found {
+			return 0, errors.Errorf("unknown Wake-on-LAN flag %s", infoShort)
+		}
+	}
+	return w, nil
+}
+
+// Has checks whether the Wake-on-LAN flag with the specified short name is set.
+func (w ConnProfileSettings8023EthernetWoL) Has(infoShort string) bool {
+	for _, flag := range connProfileSettings8023EthernetWoLInfo {
+		if flag.Info.Short == infoShort {
+			return w&flag.Flag > 0
+		}
+	}
+	return false
+}
+
+// Infos describes each Wake-on-LAN flag which is set.
+func (w ConnProfileSettings8023EthernetWoL) Infos() []EnumInfo {
+	infos := make([]EnumInfo, 0)
+	for _, flag := range connProfileSettings8023EthernetWoLInfo {
+		if w&flag.Flag > 0 {
+			infos = append(infos, flag.Info)
+		}
+	}
+	return infos
+}
+
+func dumpConnProfileSettings8023Ethernet(
+	rawSettings map[string]dbus.Variant,
+) (s ConnProfileSettings8023Ethernet, err error) {
+	if s.AssignedMACAddress, err = ensureVar(
+		rawSettings, "assigned-mac-address", "assigned MAC address", false, "",
+	); err != nil {
+		return s, err
+	}
+	if s.AutoNegotiate, err = ensureVar(
+		rawSettings, "auto-negotiate", "", false, false,
+	); err != nil {
+		return s, err
+	}
+	rawDuplex, err := ensureVar(rawSettings, "duplex", "", false, "")
+	if err != nil {
+		return s, err
+	}
+	s.Duplex = ConnProfileSettings8023EthernetDuplex(rawDuplex)
+	if s.MTU, err = ensureVar[uint32](rawSettings, "mtu", "MTU", false, 0); err != nil {
+		return s, err
+	}
+	if s.Speed, err = ensureVar[uint32](rawSettings, "speed", "", false, 0); err != nil {
+		return s, err
+	}
+	rawWoL, err := ensureVar[uint32](rawSettings, "wake-on-lan", "Wake-on-LAN flags", false, 0x1)
+	if err != nil {
+		return s, err
+	}
+	s.WakeOnLAN = ConnProfileSettings8023EthernetWoL(rawWoL)
+
+	return s, nil
+}
@@ -0,0 +1,46 @@
+package networkmanager
+
+import (
+	"github.com/godbus/dbus/v5"
+)
+
+// ConnProfileSettingsGSM holds the settings for cellular (GSM/UMTS/LTE) connections through a modem
+// managed by ModemManager. The password and PIN are secrets, which are only meant for adding or
+// updating connection profiles, so they aren't included here.
+type ConnProfileSettingsGSM struct {
+	APN string // empty if the APN should be determined automatically
+	// AutoConfig is true if the APN, username, and password should be looked up from the mobile
+	// broadband provider database (only if the APN is empty)
+	AutoConfig bool
+	// HomeOnly is true if the connection should not be used while roaming
+	HomeOnly bool
+	MTU      uint32 // zero if the MTU is determined automatically
+	// NetworkID is the MCC and MNC of the only network which the modem should register with, or empty
+	// if the modem may register with any network
+	NetworkID string
+	Username  string
+}
+
+func dumpConnProfileSettingsGSM(
+	rawSettings map[string]dbus.Variant,
+) (s ConnProfileSettingsGSM, err error) {
+	if s.APN, err = ensureVar(rawSettings, "apn", "APN", false, ""); err != nil {
+		return s, err
+	}
+	if s.AutoConfig, err = ensureVar(rawSettings, "auto-config", "", false, false); err != nil {
+		return s, err
+	}
+	if s.HomeOnly, err = ensureVar(rawSettings, "home-only", "", false, false); err != nil {
+		return s, err
+	}
+	if s.MTU, err = ensureVar[uint32](rawSettings, "mtu", "MTU", false, 0); err != nil {
+		return s, err
+	}
+	if s.NetworkID, err = ensureVar(rawSettings, "network-id", "network ID", false, ""); err != nil {
+		return s, err
+	}
+	if s.Username, err = ensureVar(rawSettings, "username", "", false, ""); err != nil {
+		return s, err
+	}
+	return s, nil
+}
@@ -8,8 +8,8 @@ import (
 
 type ConnProfileSettingsWifi struct {
 	// APIsolation int32 // TODO: change this to an int32 enum
-	// AssignedMACAddress  string
-	Band ConnProfileSettingsWifiBand
+	AssignedMACAddress string
+	Band               ConnProfileSettingsWifiBand
 	// BSSID               []string
 	Channel uint32 // TODO
 	// ChannelWidth int32
@@ -18,7 +18,7 @@ type ConnProfileSettingsWifi struct {
 	// MACAddressBlacklist []string
 	// MACAddressDenylist  []string
 	Mode ConnProfileSettingsWifiMode // TODO
-	// MTU                 uint32
+	MTU  uint32
 	// Powersave uint32 // TODO: change this to a uint32 enum
 	// SeenBSSIDs          []string
 	SSID []byte // TODO
@@ -183,6 +183,11 @@ func (m ConnProfileSettingsWifiSecKeyMgmt) Info() EnumInfo {
 	return info
 }
 
+// UsesEAP checks whether the key management mode authenticates with the 802-1x settings.
+func (m ConnProfileSettingsWifiSecKeyMgmt) UsesEAP() bool {
+	return m == "wpa-eap" || m == "wpa-eap-suite-b-192" || m == "ieee8021x"
+}
+
 type ConnProfileSettingsWifiSecPairwise string
 
 var ConnProfileSettingsWifiSecPairwiseInfo = map[ConnProfileSettingsWifiSecPairwise]EnumInfo{
@@ -273,6 +278,12 @@ func (f ConnProfileSettingsWifiSecPSKFlags) WithNotRequired() ConnProfileSetting
 func dumpConnProfileSettingsWifi(
 	rawSettings map[string]dbus.Variant,
 ) (s ConnProfileSettingsWifi, err error) {
+	if s.AssignedMACAddress, err = ensureVar(
+		rawSettings, "assigned-mac-address", "assigned MAC address", false, "",
+	); err != nil {
+		return s, err
+	}
+
 	rawBand, err := ensureVar(rawSettings, "band", "", false, "")
 	if err != nil {
 		return s, err
@@ -296,6 +307,10 @@ func dumpConnProfileSettingsWifi(
 	}
 	s.Mode = ConnProfileSettingsWifiMode(rawMode)
 
+	if s.MTU, err = ensureVar[uint32](rawSettings, "mtu", "MTU", false, 0); err != nil {
+		return s, err
+	}
+
 	if s.SSID, err = ensureVar(rawSettings, "ssid", "SSID", false, []byte{}); err != nil {
 		return s, err
 	}
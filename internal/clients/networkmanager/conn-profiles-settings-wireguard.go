@@ -0,0 +1,110 @@
+package networkmanager
+
+import (
+	"net/netip"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+type ConnProfileSettingsWireGuard struct {
+	FwMark     uint32
+	ListenPort uint32 // zero if a random port is used
+	MTU        uint32 // zero if the MTU is determined automatically
+	// PeerRoutes is true if routes are automatically added for the allowed IPs of the peers
+	PeerRoutes bool
+	Peers      []WireGuardPeer
+	// Warning: NetworkManager only returns the real private key if we're running as root; otherwise,
+	// it returns an empty string! This is only meant for adding connection profiles.
+	PrivateKey string
+}
+
+// WireGuardPeer is a peer configured in the wireguard settings of a connection profile.
+type WireGuardPeer struct {
+	PublicKey string
+	// Endpoint is the host and port of the peer, or empty if the peer will connect to us
+	Endpoint            string
+	AllowedIPs          []netip.Prefix
+	PersistentKeepalive uint32 // seconds; zero if disabled
+	// Warning: NetworkManager only returns the real preshared key if we're running as root;
+	// otherwise, it returns an empty string! This is only meant for adding connection profiles.
+	PresharedKey string
+}
+
+func (p WireGuardPeer) dbusValue() map[string]dbus.Variant {
+	allowedIPs := make([]string, 0, len(p.AllowedIPs))
+	for _, prefix := range p.AllowedIPs {
+		allowedIPs = append(allowedIPs, prefix.String())
+	}
+	value := map[string]dbus.Variant{
+		"public-key":  dbus.MakeVariant(p.PublicKey),
+		"allowed-ips": dbus.MakeVariant(allowedIPs),
+	}
+	if p.Endpoint != "" {
+		value["endpoint"] = dbus.MakeVariant(p.Endpoint)
+	}
+	if p.PersistentKeepalive > 0 {
+		value["persistent-keepalive"] = dbus.MakeVariant(p.PersistentKeepalive)
+	}
+	if p.PresharedKey != "" {
+		value["preshared-key"] = dbus.MakeVariant(p.PresharedKey)
+		const systemOwned = 0 // i.e. NetworkManager stores the key in the connection profile
+		value["preshared-key-flags"] = dbus.MakeVariant(uint32(systemOwned))
+	}
+	return value
+}
+
+func parseWireGuardPeer(rawPeer map[string]dbus.Variant) (p WireGuardPeer, err error) {
+	if p.PublicKey, err = ensureVar(rawPeer, "public-key", "", true, ""); err != nil {
+		return p, err
+	}
+	if p.Endpoint, err = ensureVar(rawPeer, "endpoint", "", false, ""); err != nil {
+		return p, err
+	}
+	rawAllowedIPs, err := ensureVar[[]string](rawPeer, "allowed-ips", "allowed IPs", false, nil)
+	if err != nil {
+		return p, err
+	}
+	for _, rawPrefix := range rawAllowedIPs {
+		prefix, err := netip.ParsePrefix(rawPrefix)
+		if err != nil {
+			return p, errors.Wrapf(err, "couldn't parse allowed IPs %s", rawPrefix)
+		}
+		p.AllowedIPs = append(p.AllowedIPs, prefix)
+	}
+	if p.PersistentKeepalive, err = ensureVar[uint32](
+		rawPeer, "persistent-keepalive", "", false, 0,
+	); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func dumpConnProfileSettingsWireGuard(
+	rawSettings map[string]dbus.Variant,
+) (s ConnProfileSettingsWireGuard, err error) {
+	if s.FwMark, err = ensureVar[uint32](rawSettings, "fwmark", "", false, 0); err != nil {
+		return s, err
+	}
+	if s.ListenPort, err = ensureVar[uint32](rawSettings, "listen-port", "", false, 0); err != nil {
+		return s, err
+	}
+	if s.MTU, err = ensureVar[uint32](rawSettings, "mtu", "MTU", false, 0); err != nil {
+		return s, err
+	}
+	if s.PeerRoutes, err = ensureVar(rawSettings, "peer-routes", "", false, true); err != nil {
+		return s, err
+	}
+	rawPeers, err := ensureVar[[]map[string]dbus.Variant](rawSettings, "peers", "", false, nil)
+	if err != nil {
+		return s, err
+	}
+	for _, rawPeer := range rawPeers {
+		peer, err := parseWireGuardPeer(rawPeer)
+		if err != nil {
+			return s, errors.Wrapf(err, "couldn't parse peer %+v", rawPeer)
+		}
+		s.Peers = append(s.Peers, peer)
+	}
+	return s, nil
+}
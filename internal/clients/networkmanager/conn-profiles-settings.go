@@ -2,8 +2,10 @@ package networkmanager
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -13,13 +15,15 @@ import (
 )
 
 type ConnProfileSettings struct {
-	Conn    ConnProfileSettingsConn    // connection
-	Wifi    ConnProfileSettingsWifi    // 802-11-wireless
-	WifiSec ConnProfileSettingsWifiSec // 802-11-wireless-security
-	// WifiAuthn  ConnProfileSettings8021x // 802-1x
-	// Ethernet ConnProfileSettings8023Ethernet // 802-3-ethernet
-	IPv4 ConnProfileSettingsIPv4 // ipv4
-	IPv6 ConnProfileSettingsIPv6 // ipv6
+	Conn      ConnProfileSettingsConn         // connection
+	Wifi      ConnProfileSettingsWifi         // 802-11-wireless
+	WifiSec   ConnProfileSettingsWifiSec      // 802-11-wireless-security
+	WifiAuthn ConnProfileSettings8021x        // 802-1x
+	Ethernet  ConnProfileSettings8023Ethernet // 802-3-ethernet
+	GSM       ConnProfileSettingsGSM          // gsm
+	WireGuard ConnProfileSettingsWireGuard    // wireguard
+	IPv4      ConnProfileSettingsIPv4         // ipv4
+	IPv6      ConnProfileSettingsIPv6         // ipv6
 }
 
 func (s ConnProfileSettings) HasData() bool {
@@ -53,6 +57,13 @@ var connProfileSettingsConnTypeInfo = map[ConnProfileSettingsConnType]EnumInfo{
 	"802-3-ethernet": {
 		Short: "ethernet",
 	},
+	"gsm": {
+		Short:   "gsm",
+		Details: "cellular (GSM/UMTS/LTE) network through a modem",
+	},
+	"wireguard": {
+		Short: "wireguard",
+	},
 }
 
 func (t ConnProfileSettingsConnType) Info() EnumInfo {
@@ -67,8 +78,22 @@ func (t ConnProfileSettingsConnType) Info() EnumInfo {
 	return info
 }
 
+// ConnProfileSettingsIPRouting holds the addressing, routing, and DNS settings which the ipv4 and
+// ipv6 sections have in common.
+type ConnProfileSettingsIPRouting struct {
+	Addresses []IPAddress
+	Gateway   netip.Addr
+	DNS       []netip.Addr
+	DNSSearch []string
+	// IgnoreAutoDNS specifies to only use the DNS servers and search domains above, instead of
+	// also using any obtained automatically (e.g. from DHCP)
+	IgnoreAutoDNS bool
+	Routes        []IPRoute
+	RouteMetric   int64
+}
+
 type ConnProfileSettingsIPv4 struct {
-	Addresses   []IPAddress
+	ConnProfileSettingsIPRouting
 	DHCPTimeout time.Duration
 	LinkLocal   ConnProfileSettingsIPv4LinkLocal
 	MayFail     bool
@@ -149,21 +174,139 @@ func (m ConnProfileSettingsIPv4Method) Info() EnumInfo {
 }
 
 type ConnProfileSettingsIPv6 struct {
-	Addresses   []IPAddress
-	AddrGenMode int32  // TODO: change this to an int32 enum // TODO
-	Method      string // TODO: change this to a string enum // TODO
+	ConnProfileSettingsIPRouting
+	AddrGenMode ConnProfileSettingsIPv6AddrGenMode
+	Privacy     ConnProfileSettingsIPv6Privacy
+	MayFail     bool
+	Method      ConnProfileSettingsIPv6Method
 }
 
-func dumpConnProfileSettings(
-	ctx context.Context, conno dbus.BusObject,
-) (s ConnProfileSettings, err error) {
-	var rawSettings map[string]map[string]dbus.Variant
-	if err = conno.CallWithContext(
-		ctx, nmName+".Settings.Connection.GetSettings", 0,
-	).Store(&rawSettings); err != nil {
-		return s, errors.Wrap(err, "couldn't get settings")
+type ConnProfileSettingsIPv6AddrGenMode int32
+
+var connProfileSettingsIPv6AddrGenModeInfo = map[ConnProfileSettingsIPv6AddrGenMode]EnumInfo{
+	0: {
+		Short:   "eui64",
+		Details: "derive the interface identifier from the hardware address",
+	},
+	1: {
+		Short:   "stable-privacy",
+		Details: "generate a stable interface identifier which doesn't reveal the hardware address",
+	},
+	2: {
+		Short:   "default-or-eui64",
+		Details: "use the global default, falling back to eui64",
+	},
+	3: {
+		Short:   "default",
+		Details: "use the global default, falling back to stable-privacy",
+	},
+}
+
+func NewConnProfileSettingsIPv6AddrGenMode(infoShort string) ConnProfileSettingsIPv6AddrGenMode {
+	for key, value := range connProfileSettingsIPv6AddrGenModeInfo {
+		if value.Short == infoShort {
+			return key
+		}
+	}
+	return -1
+}
+
+func (m ConnProfileSettingsIPv6AddrGenMode) Info() EnumInfo {
+	info, ok := connProfileSettingsIPv6AddrGenModeInfo[m]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown address generation mode (%d)", m),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+type ConnProfileSettingsIPv6Privacy int32
+
+var connProfileSettingsIPv6PrivacyInfo = map[ConnProfileSettingsIPv6Privacy]EnumInfo{
+	-1: {
+		Short: "default",
+	},
+	0: {
+		Short:   "disabled",
+		Details: "don't use temporary addresses",
+	},
+	1: {
+		Short:   "prefer-public",
+		Details: "use temporary addresses, but prefer public addresses for outgoing connections",
+	},
+	2: {
+		Short:   "prefer-temporary",
+		Details: "use temporary addresses, and prefer them for outgoing connections",
+	},
+}
+
+// NewConnProfileSettingsIPv6Privacy looks up the privacy extensions setting by its short name.
+// Because -1 is a valid setting, it returns -2 for unknown names.
+func NewConnProfileSettingsIPv6Privacy(infoShort string) ConnProfileSettingsIPv6Privacy {
+	for key, value := range connProfileSettingsIPv6PrivacyInfo {
+		if value.Short == infoShort {
+			return key
+		}
+	}
+	return -2
+}
+
+func (p ConnProfileSettingsIPv6Privacy) Info() EnumInfo {
+	info, ok := connProfileSettingsIPv6PrivacyInfo[p]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown privacy extensions setting (%d)", p),
+			Level:   EnumInfoLevelError,
+		}
+	}
+	return info
+}
+
+type ConnProfileSettingsIPv6Method string
+
+var connProfileSettingsIPv6MethodInfo = map[ConnProfileSettingsIPv6Method]EnumInfo{
+	"disabled": {
+		Short: "disabled",
+	},
+	"ignore": {
+		Short: "ignore",
+	},
+	"auto": {
+		Short: "auto",
+	},
+	"dhcp": {
+		Short: "dhcp",
+	},
+	"manual": {
+		Short: "manual",
+	},
+	"link-local": {
+		Short: "link-local",
+	},
+	"shared": {
+		Short: "shared",
+	},
+}
+
+func (m ConnProfileSettingsIPv6Method) Info() EnumInfo {
+	info, ok := connProfileSettingsIPv6MethodInfo[m]
+	if !ok {
+		return EnumInfo{
+			Short:   "unknown",
+			Details: fmt.Sprintf("unknown setting (%s)", m),
+			Level:   EnumInfoLevelError,
+		}
 	}
+	return info
+}
 
+func dumpConnProfileSettings(
+	ctx context.Context, conno dbus.BusObject, rawSettings map[string]map[string]dbus.Variant,
+) (s ConnProfileSettings, err error) {
 	if s.Conn, err = dumpConnProfileSettingsConn(
 		rawSettings["connection"],
 	); err != nil {
@@ -191,6 +334,45 @@ func dumpConnProfileSettings(
 		); err != nil {
 			return s, errors.Wrap(err, "couldn't parse '802-11-wireless-security' section")
 		}
+
+		if s.WifiSec.KeyMgmt.UsesEAP() {
+			if err = conno.CallWithContext(
+				ctx, nmName+".Settings.Connection.GetSecrets", 0, "802-1x",
+			).Store(&rawSecrets); err != nil {
+				// Note: this fails if there are no secrets, which is fine for certificate-based
+				// authentication:
+				rawSecrets["802-1x"] = make(map[string]dbus.Variant)
+			}
+			if s.WifiAuthn, err = dumpConnProfileSettings8021x(
+				rawSettings["802-1x"], rawSecrets["802-1x"],
+			); err != nil {
+				return s, errors.Wrap(err, "couldn't parse '802-1x' section")
+			}
+		}
+	}
+
+	if s.Conn.Type == "802-3-ethernet" {
+		if s.Ethernet, err = dumpConnProfileSettings8023Ethernet(
+			rawSettings["802-3-ethernet"],
+		); err != nil {
+			return s, errors.Wrap(err, "couldn't parse '802-3-ethernet' section")
+		}
+	}
+
+	if s.Conn.Type == "gsm" {
+		if s.GSM, err = dumpConnProfileSettingsGSM(
+			rawSettings["gsm"],
+		); err != nil {
+			return s, errors.Wrap(err, "couldn't parse 'gsm' section")
+		}
+	}
+
+	if s.Conn.Type == "wireguard" {
+		if s.WireGuard, err = dumpConnProfileSettingsWireGuard(
+			rawSettings["wireguard"],
+		); err != nil {
+			return s, errors.Wrap(err, "couldn't parse 'wireguard' section")
+		}
 	}
 
 	if s.IPv4, err = dumpConnProfileSettingsIPv4(
@@ -310,16 +492,10 @@ func dumpConnProfileSettingsConnAutoconnect(
 func dumpConnProfileSettingsIPv4(
 	rawSettings map[string]dbus.Variant,
 ) (s ConnProfileSettingsIPv4, err error) {
-	rawObjs, err := ensureVar[[]map[string]dbus.Variant](rawSettings, "address-data", "", false, nil)
-	if err != nil {
-		return s, nil
-	}
-	for _, obj := range rawObjs {
-		address, err := parseIPAddress(obj)
-		if err != nil {
-			return s, errors.Wrapf(err, "couldn't parse IP address %+v", address)
-		}
-		s.Addresses = append(s.Addresses, address)
+	if s.ConnProfileSettingsIPRouting, err = dumpConnProfileSettingsIPRouting(
+		rawSettings, ipv4Version,
+	); err != nil {
+		return s, err
 	}
 
 	if s.MayFail, err = ensureVar(rawSettings, "may-fail", "", false, true); err != nil {
@@ -349,9 +525,46 @@ func dumpConnProfileSettingsIPv4(
 func dumpConnProfileSettingsIPv6(
 	rawSettings map[string]dbus.Variant,
 ) (s ConnProfileSettingsIPv6, err error) {
+	if s.ConnProfileSettingsIPRouting, err = dumpConnProfileSettingsIPRouting(
+		rawSettings, ipv6Version,
+	); err != nil {
+		return s, err
+	}
+
+	if s.MayFail, err = ensureVar(rawSettings, "may-fail", "", false, true); err != nil {
+		return s, err
+	}
+
+	const defaultAddrGenMode = 3
+	rawAddrGenMode, err := ensureVar[int32](
+		rawSettings, "addr-gen-mode", "address generation mode", false, defaultAddrGenMode,
+	)
+	if err != nil {
+		return s, err
+	}
+	s.AddrGenMode = ConnProfileSettingsIPv6AddrGenMode(rawAddrGenMode)
+	rawPrivacy, err := ensureVar[int32](
+		rawSettings, "ip6-privacy", "privacy extensions setting", false, -1,
+	)
+	if err != nil {
+		return s, err
+	}
+	s.Privacy = ConnProfileSettingsIPv6Privacy(rawPrivacy)
+	rawMethod, err := ensureVar(rawSettings, "method", "", true, "")
+	if err != nil {
+		return s, err
+	}
+	s.Method = ConnProfileSettingsIPv6Method(rawMethod)
+
+	return s, nil
+}
+
+func dumpConnProfileSettingsIPRouting(
+	rawSettings map[string]dbus.Variant, ipVersion uint8,
+) (s ConnProfileSettingsIPRouting, err error) {
 	rawObjs, err := ensureVar[[]map[string]dbus.Variant](rawSettings, "address-data", "", false, nil)
 	if err != nil {
-		return s, nil
+		return s, err
 	}
 	for _, obj := range rawObjs {
 		address, err := parseIPAddress(obj)
@@ -361,5 +574,75 @@ func dumpConnProfileSettingsIPv6(
 		s.Addresses = append(s.Addresses, address)
 	}
 
+	rawGateway, err := ensureVar(rawSettings, "gateway", "", false, "")
+	if err != nil {
+		return s, err
+	}
+	if rawGateway != "" {
+		if s.Gateway, err = netip.ParseAddr(rawGateway); err != nil {
+			return s, errors.Wrapf(err, "couldn't parse gateway %s", rawGateway)
+		}
+	}
+
+	if s.DNS, err = dumpConnProfileSettingsDNS(rawSettings, ipVersion); err != nil {
+		return s, err
+	}
+	if s.DNSSearch, err = ensureVar[[]string](
+		rawSettings, "dns-search", "DNS search domains", false, nil,
+	); err != nil {
+		return s, err
+	}
+	if s.IgnoreAutoDNS, err = ensureVar(rawSettings, "ignore-auto-dns", "", false, false); err != nil {
+		return s, err
+	}
+
+	if rawObjs, err = ensureVar[[]map[string]dbus.Variant](
+		rawSettings, "route-data", "", false, nil,
+	); err != nil {
+		return s, err
+	}
+	for _, obj := range rawObjs {
+		route, err := parseIPRoute(obj)
+		if err != nil {
+			return s, errors.Wrapf(err, "couldn't parse IP route %+v", obj)
+		}
+		s.Routes = append(s.Routes, route)
+	}
+	if s.RouteMetric, err = ensureVar[int64](rawSettings, "route-metric", "", false, -1); err != nil {
+		return s, err
+	}
+
 	return s, nil
 }
+
+func dumpConnProfileSettingsDNS(
+	rawSettings map[string]dbus.Variant, ipVersion uint8,
+) (servers []netip.Addr, err error) {
+	if ipVersion == ipv6Version {
+		// Note: NetworkManager represents IPv6 DNS servers as arrays of 16 bytes
+		rawDNS, err := ensureVar[[][]byte](rawSettings, "dns", "DNS servers", false, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, rawAddr := range rawDNS {
+			addr, ok := netip.AddrFromSlice(rawAddr)
+			if !ok {
+				return nil, errors.Errorf("couldn't parse DNS server %v", rawAddr)
+			}
+			servers = append(servers, addr)
+		}
+		return servers, nil
+	}
+
+	// Note: NetworkManager represents IPv4 DNS servers as uint32s in network byte order
+	rawDNS, err := ensureVar[[]uint32](rawSettings, "dns", "DNS servers", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, rawAddr := range rawDNS {
+		var addr [4]byte
+		binary.NativeEndian.PutUint32(addr[:], rawAddr)
+		servers = append(servers, netip.AddrFrom4(addr))
+	}
+	return servers, nil
+}
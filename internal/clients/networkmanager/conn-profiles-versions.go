@@ -0,0 +1,138 @@
+package networkmanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErrConnProfileChanged is reported when a connection profile can't be updated because it was
+// changed after the caller got the version of the connection profile which the caller wanted to
+// update.
+var ErrConnProfileChanged = errors.New("the connection profile was changed by someone else")
+
+func getRawConnProfileSettings(
+	ctx context.Context, conno dbus.BusObject,
+) (rawSettings map[string]map[string]dbus.Variant, err error) {
+	if err = conno.CallWithContext(
+		ctx, nmName+".Settings.Connection.GetSettings", 0,
+	).Store(&rawSettings); err != nil {
+		return nil, errors.Wrap(err, "couldn't get settings")
+	}
+	return rawSettings, nil
+}
+
+// connProfileVersion returns NetworkManager's version ID of the connection profile if
+// NetworkManager reports version IDs (since NetworkManager 1.44); otherwise, it returns a hash of
+// the connection profile's settings.
+func connProfileVersion(
+	conno dbus.BusObject, rawSettings map[string]map[string]dbus.Variant,
+) string {
+	var versionID uint64
+	if err := conno.StoreProperty(
+		nmName+".Settings.Connection.VersionId", &versionID,
+	); err == nil && versionID > 0 {
+		return fmt.Sprintf("v%d", versionID)
+	}
+
+	h := sha256.New()
+	// Note: variants format maps with sorted keys, so the hash is deterministic
+	for _, section := range slices.Sorted(maps.Keys(rawSettings)) {
+		for _, key := range slices.Sorted(maps.Keys(rawSettings[section])) {
+			if IsVolatileConnProfileSetting(section + "." + key) {
+				continue
+			}
+			_, _ = fmt.Fprintf(h, "%s.%s=%s\n", section, key, rawSettings[section][key])
+		}
+	}
+	const hashSize = 16 // bytes
+	return "h" + base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:hashSize])
+}
+
+// IsVolatileConnProfileSetting checks whether NetworkManager changes the setting (keyed by its
+// "section.key" name) on its own, rather than only when someone changes the connection profile. For
+// example, NetworkManager updates connection.timestamp whenever the connection profile is active,
+// so it must not be treated as a conflicting change.
+func IsVolatileConnProfileSetting(key string) bool {
+	return key == "connection.timestamp"
+}
+
+func parseVersionID(version string) (versionID uint64, ok bool) {
+	rawVersionID, ok := strings.CutPrefix(version, "v")
+	if !ok {
+		return 0, false
+	}
+	versionID, err := strconv.ParseUint(rawVersionID, 10, 64)
+	return versionID, err == nil
+}
+
+func isVersionIDMismatch(err error) bool {
+	var dbusErr dbus.Error
+	if !errors.As(err, &dbusErr) {
+		return false
+	}
+	return strings.HasSuffix(dbusErr.Name, ".VersionIdMismatch")
+}
+
+// ConnProfileSnapshot is a human-readable copy of a connection profile's settings (not including
+// secrets) at some version.
+type ConnProfileSnapshot struct {
+	Version string
+	// Settings are keyed by "section.key" names, e.g. "802-11-wireless.ssid"
+	Settings map[string]string
+}
+
+// GetConnProfileSnapshot returns the current version and settings of the connection profile, for
+// showing how the connection profile changes across versions.
+func (c *Client) GetConnProfileSnapshot(
+	ctx context.Context, uid uuid.UUID,
+) (s ConnProfileSnapshot, err error) {
+	conno, err := c.findConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return s, errors.Wrapf(err, "couldn't find connection profile with uuid %s", uid)
+	}
+	rawSettings, err := getRawConnProfileSettings(ctx, conno)
+	if err != nil {
+		return s, errors.Wrapf(err, "couldn't get settings of connection profile %s", uid)
+	}
+	s.Version = connProfileVersion(conno, rawSettings)
+	s.Settings = make(map[string]string)
+	for section, values := range rawSettings {
+		for key, value := range values {
+			s.Settings[section+"."+key] = formatSettingValue(value)
+		}
+	}
+	return s, nil
+}
+
+func formatSettingValue(v dbus.Variant) string {
+	switch value := v.Value().(type) {
+	default:
+		return v.String()
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ", ")
+	case []byte:
+		// Some settings (e.g. SSIDs) are stored as bytes, but they're usually text
+		if utf8.Valid(value) && !strings.ContainsFunc(string(value), func(r rune) bool {
+			return !unicode.IsPrint(r)
+		}) {
+			return string(value)
+		}
+		return v.String()
+	case bool, int32, uint32, int64, uint64:
+		return fmt.Sprint(value)
+	}
+}
@@ -3,7 +3,10 @@ package networkmanager
 import (
 	"cmp"
 	"context"
+	"encoding/binary"
 	"fmt"
+	"maps"
+	"net/netip"
 	"slices"
 	"strings"
 
@@ -16,6 +19,9 @@ type ConnProfile struct {
 	Unsaved  bool
 	Flags    ConnProfileFlags
 	Filename string
+	// Version identifies the revision of the connection profile's settings, so that updates based on
+	// an outdated revision can be rejected
+	Version  string
 	Settings ConnProfileSettings
 }
 
@@ -90,7 +96,12 @@ func dumpConnProfile(ctx context.Context, conno dbus.BusObject) (conn ConnProfil
 		return ConnProfile{}, errors.Wrap(err, "couldn't query for filename")
 	}
 
-	if conn.Settings, err = dumpConnProfileSettings(ctx, conno); err != nil {
+	rawSettings, err := getRawConnProfileSettings(ctx, conno)
+	if err != nil {
+		return ConnProfile{}, err
+	}
+	conn.Version = connProfileVersion(conno, rawSettings)
+	if conn.Settings, err = dumpConnProfileSettings(ctx, conno, rawSettings); err != nil {
 		return ConnProfile{}, errors.Wrap(err, "couldn't query for connection settings")
 	}
 
@@ -98,6 +109,18 @@ func dumpConnProfile(ctx context.Context, conno dbus.BusObject) (conn ConnProfil
 }
 
 func (c *Client) ListConnProfiles(ctx context.Context) (conns []ConnProfile, err error) {
+	if conns, ok := c.getCachedConnProfiles(); ok {
+		return conns, nil
+	}
+	generation := c.cacheGeneration()
+	if conns, err = c.queryConnProfiles(ctx); err != nil {
+		return nil, err
+	}
+	c.cacheConnProfiles(generation, conns)
+	return conns, nil
+}
+
+func (c *Client) queryConnProfiles(ctx context.Context) (conns []ConnProfile, err error) {
 	nm := c.getNetworkManagerSettings()
 
 	var connPaths []dbus.ObjectPath
@@ -195,6 +218,34 @@ func (c *Client) ActivateConnProfile(ctx context.Context, uid uuid.UUID) error {
 	return nil
 }
 
+// DeactivateConnProfile deactivates the connection profile, if it's active.
+func (c *Client) DeactivateConnProfile(ctx context.Context, uid uuid.UUID) error {
+	const connName = nmName + ".Connection.Active"
+	nm := c.getNetworkManager()
+
+	var connPaths []dbus.ObjectPath
+	if err := nm.StoreProperty(nmName+".ActiveConnections", &connPaths); err != nil {
+		return errors.Wrap(err, "couldn't query for active connections")
+	}
+	for _, connPath := range connPaths {
+		var rawUUID string
+		if err := c.bus.Object(nmName, connPath).StoreProperty(
+			connName+".Uuid", &rawUUID,
+		); err != nil {
+			return errors.Wrapf(err, "couldn't query for UUID of active connection %s", connPath)
+		}
+		if rawUUID != uid.String() {
+			continue
+		}
+		if err := nm.CallWithContext(
+			ctx, nmName+".DeactivateConnection", 0, connPath,
+		).Store(); err != nil {
+			return errors.Wrapf(err, "couldn't deactivate connection profile with UUID %s", uid)
+		}
+	}
+	return nil
+}
+
 type ConnProfileSettingsKey struct {
 	Section   string
 	Key       string
@@ -221,26 +272,86 @@ func (k ConnProfileSettingsKey) String() string {
 	return fmt.Sprintf("%s.%s", k.Section, k.Key)
 }
 
+// UpdateConnProfileByUUID applies the new settings to the connection profile. Callers making
+// changes which might break the network connection to the device should first create a checkpoint
+// with [Client.CreateCheckpoint], so that the changes are automatically rolled back unless
+// confirmed. If expectedVersion is non-empty and the connection profile's version is different,
+// the update is rejected with [ErrConnProfileChanged].
 func (c *Client) UpdateConnProfileByUUID(
-	ctx context.Context, uid uuid.UUID, updateType string, newSettings map[ConnProfileSettingsKey]any,
+	ctx context.Context, uid uuid.UUID, updateType, expectedVersion string,
+	newSettings map[ConnProfileSettingsKey]any,
 ) error {
 	conno, err := c.findConnProfileByUUID(ctx, uid)
 	if err != nil {
 		return errors.Wrapf(err, "couldn't find connection profile with uuid %s", uid.String())
 	}
 
-	var rawSettings map[string]map[string]dbus.Variant
-	if err = conno.CallWithContext(
-		ctx, nmName+".Settings.Connection.GetSettings", 0,
-	).Store(&rawSettings); err != nil {
+	rawSettings, err := getRawConnProfileSettings(ctx, conno)
+	if err != nil {
 		return errors.Wrapf(err, "couldn't get settings of connection profile %s", uid.String())
 	}
-	// Remove deprecated fields which would override non-deprecated fields:
+	args := make(map[string]dbus.Variant)
+	// TODO: set plugin in args to store the password somehow?
+	if expectedVersion != "" {
+		if version := connProfileVersion(conno, rawSettings); version != expectedVersion {
+			return errors.Wrapf(
+				ErrConnProfileChanged, "connection profile %s is at version %s instead of %s",
+				uid, version, expectedVersion,
+			)
+		}
+		// Note: with NetworkManager 1.44 or newer, NetworkManager checks the version itself, which
+		// also catches changes made between our check and the update
+		if versionID, ok := parseVersionID(expectedVersion); ok {
+			args["version-id"] = dbus.MakeVariant(versionID)
+		}
+	}
+	deleteDeprecatedConnProfileSettings(rawSettings)
+	if err = applyConnProfileSettings(rawSettings, newSettings); err != nil {
+		return err
+	}
+
+	var flags UpdateFlags
+	switch updateType {
+	default:
+		return errors.Errorf("unknown update type %s", updateType)
+	case "apply":
+		flags |= UpdateFlagInMemory
+	case "save":
+		flags |= UpdateFlagToDisk
+	}
+
+	var rawResult map[string]dbus.Variant
+	if err = conno.CallWithContext(
+		ctx, nmName+".Settings.Connection.Update2", 0, rawSettings, uint32(flags), args,
+	).Store(&rawResult); err != nil {
+		if isVersionIDMismatch(err) {
+			return errors.Wrapf(
+				ErrConnProfileChanged, "connection profile %s is no longer at version %s",
+				uid, expectedVersion,
+			)
+		}
+		return errors.Wrapf(err, "couldn't apply settings of connection profile %s", uid.String())
+	}
+
+	return nil
+}
+
+// deleteDeprecatedConnProfileSettings removes deprecated fields from the raw settings, since they
+// would override the non-deprecated fields if they're sent back to NetworkManager.
+func deleteDeprecatedConnProfileSettings(rawSettings map[string]map[string]dbus.Variant) {
 	delete(rawSettings["ipv4"], "addresses")
 	delete(rawSettings["ipv4"], "routes")
 	delete(rawSettings["ipv6"], "addresses")
 	delete(rawSettings["ipv6"], "routes")
+	delete(rawSettings["802-3-ethernet"], "cloned-mac-address")
+	delete(rawSettings["802-11-wireless"], "cloned-mac-address")
+}
 
+// applyConnProfileSettings modifies the raw settings of a connection profile with the new
+// settings.
+func applyConnProfileSettings(
+	rawSettings map[string]map[string]dbus.Variant, newSettings map[ConnProfileSettingsKey]any,
+) error {
 	for fullKey, value := range newSettings {
 		if err := handleField(fullKey, value, rawSettings); err != nil {
 			return errors.Errorf("couldn't handle (key, value) pair: (%s, %+v)", fullKey, value)
@@ -254,31 +365,132 @@ func (c *Client) UpdateConnProfileByUUID(
 		// section (because it rejects an empty string for psk):
 		delete(rawSettings, "802-11-wireless-security")
 	}
+	if keyMgmt, ok := newSettings[ConnProfileSettingsKey{
+		Section: "802-11-wireless-security", Key: "key-mgmt",
+	}].(ConnProfileSettingsWifiSecKeyMgmt); ok && !keyMgmt.UsesEAP() {
+		// NetworkManager rejects 802-1x sections without EAP methods, so we must remove the section
+		// when it's no longer needed:
+		delete(rawSettings, "802-1x")
+	}
+	return nil
+}
 
-	var flags UpdateFlags
-	switch updateType {
+// ListChangedConnProfileSettings returns the "section.key" names of the settings which
+// [Client.UpdateConnProfileByUUID] would change if it applied the new settings to the connection
+// profile, without changing the connection profile. Settings which NetworkManager doesn't report
+// without being asked for secrets (e.g. passwords) are reported as changed whenever they're set.
+func (c *Client) ListChangedConnProfileSettings(
+	ctx context.Context, uid uuid.UUID, newSettings map[ConnProfileSettingsKey]any,
+) (changed []string, err error) {
+	conno, err := c.findConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't find connection profile with uuid %s", uid.String())
+	}
+	original, err := getRawConnProfileSettings(ctx, conno)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get settings of connection profile %s", uid.String())
+	}
+	deleteDeprecatedConnProfileSettings(original)
+	updated := make(map[string]map[string]dbus.Variant, len(original))
+	for section, values := range original {
+		updated[section] = maps.Clone(values)
+	}
+	if err = applyConnProfileSettings(updated, newSettings); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, settings := range []map[string]map[string]dbus.Variant{original, updated} {
+		for section, values := range settings {
+			for key := range values {
+				keys[section+"."+key] = true
+			}
+		}
+	}
+	for _, fullKey := range slices.Sorted(maps.Keys(keys)) {
+		section, key, _ := strings.Cut(fullKey, ".")
+		originalValue, inOriginal := original[section][key]
+		updatedValue, inUpdated := updated[section][key]
+		// Note: variants format maps with sorted keys, so equal values are formatted equally
+		if inOriginal != inUpdated || originalValue.String() != updatedValue.String() {
+			changed = append(changed, fullKey)
+		}
+	}
+	return changed, nil
+}
+
+// AddConnProfile creates a new connection profile with the specified settings, and returns its
+// UUID. A new UUID is generated if the settings don't specify one.
+func (c *Client) AddConnProfile(
+	ctx context.Context, addType string, newSettings map[ConnProfileSettingsKey]any,
+) (uid uuid.UUID, err error) {
+	rawSettings := make(map[string]map[string]dbus.Variant)
+	for fullKey, value := range newSettings {
+		if err := handleField(fullKey, value, rawSettings); err != nil {
+			return uuid.UUID{}, errors.Errorf(
+				"couldn't handle (key, value) pair: (%s, %+v)", fullKey, value,
+			)
+		}
+	}
+	if connType, ok := newSettings[NewConnProfileSettingsKey("connection", "type")]; ok {
+		// NetworkManager requires a settings section for the connection type, even if it's empty:
+		if section := fmt.Sprint(connType); rawSettings[section] == nil {
+			rawSettings[section] = make(map[string]dbus.Variant)
+		}
+	}
+	uuidKey := NewConnProfileSettingsKey("connection", "uuid")
+	if rawUUID, ok := newSettings[uuidKey].(string); ok {
+		if uid, err = uuid.Parse(rawUUID); err != nil {
+			return uuid.UUID{}, errors.Wrapf(err, "couldn't parse uuid %s", rawUUID)
+		}
+	} else {
+		uid = uuid.New()
+		if err = handleField(uuidKey, uid.String(), rawSettings); err != nil {
+			return uuid.UUID{}, err
+		}
+	}
+
+	var flags AddFlags
+	switch addType {
 	default:
-		return errors.Errorf("unknown update type %s", updateType)
+		return uuid.UUID{}, errors.Errorf("unknown add type %s", addType)
 	case "apply":
-		flags |= UpdateFlagInMemory
+		flags |= AddFlagInMemory
 	case "save":
-		flags |= UpdateFlagToDisk
+		flags |= AddFlagToDisk
 	}
 
+	nm := c.getNetworkManagerSettings()
 	args := make(map[string]dbus.Variant)
-	// TODO: set plugin in args to store the password somehow?
-	// TODO: set version-id in args to detect data races (requires NetworkManager 1.44, which is too
-	// recent)
-
+	var connPath dbus.ObjectPath
 	var rawResult map[string]dbus.Variant
-	if err = conno.CallWithContext(
-		ctx, nmName+".Settings.Connection.Update2", 0, rawSettings, uint32(flags), args,
-	).Store(&rawResult); err != nil {
-		return errors.Wrapf(err, "couldn't apply settings of connection profile %s", uid.String())
+	if err = nm.CallWithContext(
+		ctx, nmName+".Settings.AddConnection2", 0, rawSettings, uint32(flags), args,
+	).Store(&connPath, &rawResult); err != nil {
+		return uuid.UUID{}, errors.Wrap(err, "couldn't add connection profile")
 	}
+	return uid, nil
+}
 
-	// TODO: add checkpointing behavior for auto-rollback in the absence of manual confirmation
+type AddFlags uint32
 
+const (
+	AddFlagToDisk           = 0x1
+	AddFlagInMemory         = 0x2
+	AddFlagBlockAutoconnect = 0x20
+)
+
+// DeleteConnProfile deletes the connection profile, including its file (if it has one).
+func (c *Client) DeleteConnProfile(ctx context.Context, uid uuid.UUID) error {
+	conno, err := c.findConnProfileByUUID(ctx, uid)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't find connection profile with uuid %s", uid.String())
+	}
+	if err = conno.CallWithContext(
+		ctx, nmName+".Settings.Connection.Delete", 0,
+	).Store(); err != nil {
+		return errors.Wrapf(err, "couldn't delete connection profile %s", uid.String())
+	}
 	return nil
 }
 
@@ -293,7 +505,24 @@ func handleField(
 			return nil
 		}
 	}
+	if key.Section == "gsm" {
+		if s, ok := value.(string); ok && s == "" {
+			// NetworkManager rejects empty strings for the gsm settings; instead, to clear such a
+			// setting, we must omit it from the settings:
+			delete(settings[key.Section], key.Key)
+			return nil
+		}
+	}
 
+	if value, err = toDBusValue(key, value); err != nil {
+		return err
+	}
+	if value == nil {
+		// NetworkManager rejects empty values for some settings (e.g. the gateway); instead, to
+		// clear such a setting, we must omit it from the settings:
+		delete(settings[key.Section], key.Key)
+		return nil
+	}
 	result, err := makeVariant(value)
 	if err != nil {
 		return err
@@ -305,6 +534,76 @@ func handleField(
 	return nil
 }
 
+// toDBusValue converts values of types which have a different representation in D-Bus, and
+// returns nil for values which must be omitted from the settings.
+func toDBusValue(key ConnProfileSettingsKey, value any) (converted any, err error) {
+	switch v := value.(type) {
+	default:
+		return value, nil
+	case netip.Addr:
+		if !v.IsValid() {
+			return nil, nil
+		}
+		return v.String(), nil
+	case []IPAddress:
+		addresses := make([]map[string]dbus.Variant, 0, len(v))
+		for _, address := range v {
+			addresses = append(addresses, address.dbusValue())
+		}
+		return addresses, nil
+	case []IPRoute:
+		routes := make([]map[string]dbus.Variant, 0, len(v))
+		for _, route := range v {
+			routes = append(routes, route.dbusValue())
+		}
+		return routes, nil
+	case []WireGuardPeer:
+		peers := make([]map[string]dbus.Variant, 0, len(v))
+		for _, peer := range v {
+			peers = append(peers, peer.dbusValue())
+		}
+		return peers, nil
+	case []netip.Addr:
+		return dnsServersDBusValue(key, v)
+	case ConnProfileSettings8021xEAP:
+		return []string{string(v)}, nil
+	case ConnProfileSettings8021xCert:
+		if v.Path == "" {
+			return nil, nil
+		}
+		return v.dbusValue(), nil
+	}
+}
+
+func dnsServersDBusValue(key ConnProfileSettingsKey, servers []netip.Addr) (any, error) {
+	switch key.Section {
+	default:
+		return nil, errors.Errorf("unimplemented conversion of IP addresses for %s", key)
+	case "ipv4":
+		// Note: NetworkManager represents IPv4 DNS servers as uint32s in network byte order
+		addrs := make([]uint32, 0, len(servers))
+		for _, addr := range servers {
+			if !addr.Is4() {
+				return nil, errors.Errorf("%s is not an IPv4 address", addr)
+			}
+			raw := addr.As4()
+			addrs = append(addrs, binary.NativeEndian.Uint32(raw[:]))
+		}
+		return addrs, nil
+	case "ipv6":
+		// Note: NetworkManager represents IPv6 DNS servers as arrays of 16 bytes
+		addrs := make([][]byte, 0, len(servers))
+		for _, addr := range servers {
+			if !addr.Is6() || addr.Is4In6() {
+				return nil, errors.Errorf("%s is not an IPv6 address", addr)
+			}
+			raw := addr.As16()
+			addrs = append(addrs, raw[:])
+		}
+		return addrs, nil
+	}
+}
+
 type UpdateFlags uint32
 
 const (
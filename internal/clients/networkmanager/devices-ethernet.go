@@ -0,0 +1,31 @@
+package networkmanager
+
+import (
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+type EthernetDevice struct {
+	PermHardwareAddress string
+	Speed               uint32 // Mb/s
+	Carrier             bool
+}
+
+func (d EthernetDevice) HasData() bool {
+	return d != EthernetDevice{}
+}
+
+func dumpEthernetDevice(devo dbus.BusObject) (dev EthernetDevice, err error) {
+	if err = devo.StoreProperty(
+		nmName+".Device.Wired.PermHwAddress", &dev.PermHardwareAddress,
+	); err != nil {
+		return EthernetDevice{}, errors.Wrap(err, "couldn't query for permanent hardware address")
+	}
+	if err = devo.StoreProperty(nmName+".Device.Wired.Speed", &dev.Speed); err != nil {
+		return EthernetDevice{}, errors.Wrap(err, "couldn't query for link speed")
+	}
+	if err = devo.StoreProperty(nmName+".Device.Wired.Carrier", &dev.Carrier); err != nil {
+		return EthernetDevice{}, errors.Wrap(err, "couldn't query for carrier")
+	}
+	return dev, nil
+}
@@ -9,10 +9,12 @@ import (
 )
 
 type WifiDevice struct {
-	Mode     DeviceWifiMode
-	ActiveAP AccessPoint
-	Caps     DeviceWifiCaps
-	LastScan time.Duration
+	Mode                DeviceWifiMode
+	ActiveAP            AccessPoint
+	Caps                DeviceWifiCaps
+	LastScan            time.Duration
+	PermHardwareAddress string
+	Bitrate             uint32 // kb/s
 }
 
 func (d WifiDevice) HasData() bool {
@@ -129,6 +131,15 @@ func dumpWifiDevice(devo dbus.BusObject, bus *dbus.Conn) (dev WifiDevice, err er
 		return WifiDevice{}, errors.Wrap(err, "couldn't query for last scan")
 	}
 
+	if err = devo.StoreProperty(
+		nmName+".Device.Wireless.PermHwAddress", &dev.PermHardwareAddress,
+	); err != nil {
+		return WifiDevice{}, errors.Wrap(err, "couldn't query for permanent hardware address")
+	}
+	if err = devo.StoreProperty(nmName+".Device.Wireless.Bitrate", &dev.Bitrate); err != nil {
+		return WifiDevice{}, errors.Wrap(err, "couldn't query for bitrate")
+	}
+
 	return dev, nil
 }
 
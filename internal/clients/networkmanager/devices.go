@@ -34,9 +34,11 @@ type Device struct {
 	IPv6Connectivity IPConnectivityState
 	InterfaceFlags   DeviceInterfaceFlags
 	HardwareAddress  string
+	MTU              uint32
 
 	// Device type-dependent sections:
-	Wifi WifiDevice
+	Ethernet EthernetDevice
+	Wifi     WifiDevice
 }
 
 type DeviceCaps uint32
@@ -717,6 +719,18 @@ func (f DeviceInterfaceFlags) LLDPClient() bool {
 }
 
 func (c *Client) GetDevices(ctx context.Context) (devs []Device, err error) {
+	if devs, ok := c.getCachedDevices(); ok {
+		return devs, nil
+	}
+	generation := c.cacheGeneration()
+	if devs, err = c.queryDevices(ctx); err != nil {
+		return nil, err
+	}
+	c.cacheDevices(generation, devs)
+	return devs, nil
+}
+
+func (c *Client) queryDevices(ctx context.Context) (devs []Device, err error) {
 	nm := c.getNetworkManager()
 	devPaths := make([]dbus.ObjectPath, 0)
 	if err = nm.CallWithContext(ctx, nmName+".GetDevices", 0).Store(&devPaths); err != nil {
@@ -783,6 +797,9 @@ func dumpDevice(ctx context.Context, devo dbus.BusObject, bus *dbus.Conn) (dev D
 	if err = devo.StoreProperty(nmName+".Device.HwAddress", &dev.HardwareAddress); err != nil {
 		return Device{}, errors.Wrap(err, "couldn't query for hardware address")
 	}
+	if err = devo.StoreProperty(nmName+".Device.Mtu", &dev.MTU); err != nil {
+		return Device{}, errors.Wrap(err, "couldn't query for MTU")
+	}
 
 	if dev, err = dumpDeviceStateInfo(devo, dev); err != nil {
 		return Device{}, err
@@ -797,7 +814,12 @@ func dumpDevice(ctx context.Context, devo dbus.BusObject, bus *dbus.Conn) (dev D
 		return Device{}, err
 	}
 
-	if dev.Type.Info().Short == "wifi" {
+	switch dev.Type.Info().Short {
+	case "ethernet":
+		if dev.Ethernet, err = dumpEthernetDevice(devo); err != nil {
+			return Device{}, errors.Wrap(err, "couldn't dump ethernet device")
+		}
+	case "wifi":
 		if dev.Wifi, err = dumpWifiDevice(devo, bus); err != nil {
 			return Device{}, errors.Wrap(err, "couldn't dump Wi-Fi device")
 		}
@@ -0,0 +1,112 @@
+package networkmanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GlobalDNS is a DNS configuration which NetworkManager uses instead of the DNS servers and search
+// domains of every connection profile.
+type GlobalDNS struct {
+	Servers  []netip.Addr
+	Searches []string
+}
+
+func (d GlobalDNS) HasData() bool {
+	return len(d.Servers) > 0
+}
+
+const (
+	globalDNSSection       = "global-dns"
+	globalDNSDomainSection = "global-dns-domain-*"
+)
+
+// Conf returns the global DNS configuration as the contents of a NetworkManager configuration file
+// (e.g. a drop-in file in /etc/NetworkManager/conf.d).
+func (d GlobalDNS) Conf() []byte {
+	var b bytes.Buffer
+	b.WriteString("# This file is managed by machine-admin; changes made here will be overwritten.\n")
+	fmt.Fprintf(&b, "[%s]\n", globalDNSSection)
+	fmt.Fprintf(&b, "searches=%s\n", strings.Join(d.Searches, ","))
+	fmt.Fprintf(&b, "\n[%s]\n", globalDNSDomainSection)
+	servers := make([]string, 0, len(d.Servers))
+	for _, server := range d.Servers {
+		servers = append(servers, server.String())
+	}
+	fmt.Fprintf(&b, "servers=%s\n", strings.Join(servers, ","))
+	return b.Bytes()
+}
+
+// ParseGlobalDNSConf parses the global DNS configuration from the contents of a NetworkManager
+// configuration file, ignoring all other settings in the file.
+func ParseGlobalDNSConf(data []byte) (d GlobalDNS, err error) {
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return d, errors.Errorf("line %d: expected a key=value pair or a [section]", lineNum)
+		}
+		key = strings.TrimSpace(key)
+		switch {
+		case section == globalDNSSection && key == "searches":
+			d.Searches = splitConfList(value)
+		case section == globalDNSDomainSection && key == "servers":
+			d.Servers = make([]netip.Addr, 0)
+			for _, rawServer := range splitConfList(value) {
+				server, err := netip.ParseAddr(rawServer)
+				if err != nil {
+					return d, errors.Wrapf(
+						err, "line %d: couldn't parse DNS server %s", lineNum, rawServer,
+					)
+				}
+				d.Servers = append(d.Servers, server)
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return d, errors.Wrap(err, "couldn't read configuration file")
+	}
+	return d, nil
+}
+
+// splitConfList splits a list value of NetworkManager's configuration files, whose items may be
+// separated by commas or semicolons.
+func splitConfList(value string) []string {
+	items := make([]string, 0)
+	for item := range strings.FieldsFuncSeq(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	}) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ReloadConf makes NetworkManager reload its configuration files, which also applies any change to
+// the global DNS configuration.
+func (c *Client) ReloadConf(ctx context.Context) error {
+	nm := c.getNetworkManager()
+	const reloadFlagConf = 0x1 // NM_MANAGER_RELOAD_FLAG_CONF
+	if err := nm.CallWithContext(
+		ctx, nmName+".Reload", 0, uint32(reloadFlagConf),
+	).Store(); err != nil {
+		return errors.Wrap(err, "couldn't reload NetworkManager configuration")
+	}
+	return nil
+}
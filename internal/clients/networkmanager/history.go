@@ -0,0 +1,402 @@
+package networkmanager
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// StateTransition is a change in the state of a device or of an active connection, as reported by
+// NetworkManager.
+type StateTransition struct {
+	Time time.Time `json:"time"`
+	// Interfaces are the network interfaces of the device, or of the devices of the active
+	// connection.
+	Interfaces []string `json:"interfaces,omitempty"`
+	// Device is nil if the transition is of an active connection.
+	Device *DeviceStateTransition `json:"device,omitempty"`
+	// ActiveConn is nil if the transition is of a device.
+	ActiveConn *ActiveConnStateTransition `json:"active-conn,omitempty"`
+}
+
+type DeviceStateTransition struct {
+	OldState DeviceState       `json:"old-state"`
+	NewState DeviceState       `json:"new-state"`
+	Reason   DeviceStateReason `json:"reason"`
+}
+
+type ActiveConnStateTransition struct {
+	// ID, UUID, and Type are empty if the active connection was already removed by the time its
+	// state transition was recorded.
+	ID     string                `json:"id,omitempty"`
+	UUID   uuid.UUID             `json:"uuid"`
+	Type   string                `json:"type,omitempty"`
+	State  ActiveConnState       `json:"state"`
+	Reason ActiveConnStateReason `json:"reason"`
+}
+
+// history is a bounded ring buffer of state transitions, which is persisted to a file of JSON
+// lines so that transitions from before a restart (e.g. of flaky uplinks) can still be examined.
+type history struct {
+	mu sync.Mutex
+	// path is empty if the history is only kept in memory.
+	path string
+	// transitions is the ring buffer; next is the index at which the next transition is stored.
+	transitions []StateTransition
+	next        int
+	full        bool
+	// lines is the number of lines in the file; once it holds twice as many lines as the ring
+	// buffer, the file is rewritten with just the transitions in the ring buffer.
+	lines int
+
+	// deviceInterfaces and activeConns remember the identities of D-Bus objects, because objects
+	// may already be removed by the time their last state transition is recorded. Devices are
+	// forgotten once they're removed, and active connections once they're deactivated.
+	deviceInterfaces map[dbus.ObjectPath]string
+	activeConns      map[dbus.ObjectPath]activeConnIdentity
+
+	// pending queues signals which may report state transitions, so that they can be recorded
+	// without delaying the handling of other signals.
+	pending chan pendingSignal
+}
+
+type pendingSignal struct {
+	signal   *dbus.Signal
+	received time.Time
+}
+
+// historyQueueSize is the number of signals which may be waiting to be recorded; any further
+// signals are dropped until the queue has room again.
+const historyQueueSize = 256
+
+type activeConnIdentity struct {
+	activeConn ActiveConnStateTransition
+	interfaces []string
+}
+
+func newHistory(path string, size int) *history {
+	return &history{
+		path:             path,
+		transitions:      make([]StateTransition, max(size, 0)),
+		deviceInterfaces: make(map[dbus.ObjectPath]string),
+		activeConns:      make(map[dbus.ObjectPath]activeConnIdentity),
+		pending:          make(chan pendingSignal, historyQueueSize),
+	}
+}
+
+// load restores the transitions persisted in the history's file. If the file can't be used, the
+// history is only kept in memory.
+func (h *history) load() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.path == "" || len(h.transitions) == 0 {
+		return nil
+	}
+	const dirPerm = 0o755
+	if err := os.MkdirAll(filepath.Dir(h.path), dirPerm); err != nil {
+		path := h.path
+		h.path = ""
+		return errors.Wrapf(err, "couldn't make directory for state history %s", path)
+	}
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		path := h.path
+		h.path = ""
+		return errors.Wrapf(err, "couldn't open state history %s", path)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		h.lines++
+		var t StateTransition
+		if err := json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			continue // e.g. a line which was only partially written before a power loss
+		}
+		h.push(t)
+	}
+	if err = scanner.Err(); err != nil {
+		return errors.Wrapf(err, "couldn't read state history %s", h.path)
+	}
+	return nil
+}
+
+// push adds the transition to the ring buffer, replacing the oldest transition if the buffer is
+// full.
+func (h *history) push(t StateTransition) {
+	h.transitions[h.next] = t
+	h.next = (h.next + 1) % len(h.transitions)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// list returns the transitions in the ring buffer, from oldest to newest.
+func (h *history) list() []StateTransition {
+	if !h.full {
+		return slices.Clone(h.transitions[:h.next])
+	}
+	return slices.Concat(h.transitions[h.next:], h.transitions[:h.next])
+}
+
+// record adds the transition to the history and persists it.
+func (h *history) record(t StateTransition) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.transitions) == 0 {
+		return nil
+	}
+	h.push(t)
+	if h.path == "" {
+		return nil
+	}
+	if h.lines+1 >= 2*len(h.transitions) {
+		return h.compact()
+	}
+
+	line, err := json.Marshal(t)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't serialize state transition %+v", t)
+	}
+	const filePerm = 0o644
+	f, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, filePerm)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't open state history %s", h.path)
+	}
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "couldn't write to state history %s", h.path)
+	}
+	if err = f.Close(); err != nil {
+		return errors.Wrapf(err, "couldn't close state history %s", h.path)
+	}
+	h.lines++
+	return nil
+}
+
+// compact rewrites the history's file with just the transitions in the ring buffer, so that the
+// file doesn't grow without bound.
+func (h *history) compact() error {
+	transitions := h.list()
+	var b []byte
+	for _, t := range transitions {
+		line, err := json.Marshal(t)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't serialize state transition %+v", t)
+		}
+		b = append(append(b, line...), '\n')
+	}
+
+	swapPath := filepath.Join(filepath.Dir(h.path), "."+filepath.Base(h.path)+".swp")
+	const filePerm = 0o644
+	if err := os.WriteFile(swapPath, b, filePerm); err != nil {
+		return errors.Wrapf(err, "couldn't write state history to swap file %s", swapPath)
+	}
+	if err := os.Rename(swapPath, h.path); err != nil {
+		return errors.Wrapf(err, "couldn't move swap file %s to %s", swapPath, h.path)
+	}
+	h.lines = len(transitions)
+	return nil
+}
+
+// GetStateHistory returns the recorded state transitions of the device with the interface and of
+// the connections which were active on it, from newest to oldest.
+func (c *Client) GetStateHistory(iface string) []StateTransition {
+	c.history.mu.Lock()
+	transitions := c.history.list()
+	c.history.mu.Unlock()
+
+	filtered := make([]StateTransition, 0, len(transitions))
+	for _, t := range slices.Backward(transitions) {
+		if slices.Contains(t.Interfaces, iface) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// Recording
+
+// queueStateTransition queues the signal to be recorded by recordStateTransitions, if the signal
+// reports a state transition of a device or of an active connection, or the removal of a device.
+// It never blocks, since recording may require D-Bus queries and file writes.
+func (c *Client) queueStateTransition(signal *dbus.Signal) {
+	if len(c.history.transitions) == 0 {
+		return // the history is disabled
+	}
+	switch signal.Name {
+	default:
+		return
+	case nmName + ".Device.StateChanged", nmName + ".Connection.Active.StateChanged",
+		nmName + ".DeviceRemoved":
+	}
+
+	select {
+	case c.history.pending <- pendingSignal{signal: signal, received: time.Now()}:
+	default:
+		c.l.Warnf(
+			"couldn't record signal %s from %s, since too many signals are waiting to be recorded",
+			signal.Name, signal.Path,
+		)
+	}
+}
+
+// recordStateTransitions records the state transitions from the signals queued by
+// queueStateTransition, in order, until the context is canceled.
+func (c *Client) recordStateTransitions(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pending := <-c.history.pending:
+			c.recordStateTransition(pending.signal, pending.received)
+		}
+	}
+}
+
+// recordStateTransition records the state transition reported by the signal, or forgets the
+// device reported as removed by the signal.
+func (c *Client) recordStateTransition(signal *dbus.Signal, received time.Time) {
+	var t StateTransition
+	switch signal.Name {
+	default:
+		return
+	case nmName + ".DeviceRemoved":
+		var devPath dbus.ObjectPath
+		if err := dbus.Store(signal.Body, &devPath); err != nil {
+			c.l.Warn(errors.Wrap(err, "couldn't parse removed device"))
+			return
+		}
+		c.history.mu.Lock()
+		delete(c.history.deviceInterfaces, devPath)
+		c.history.mu.Unlock()
+		return
+	case nmName + ".Device.StateChanged":
+		var newState, oldState, reason uint32
+		if err := dbus.Store(signal.Body, &newState, &oldState, &reason); err != nil {
+			c.l.Warn(errors.Wrapf(err, "couldn't parse state transition of device %s", signal.Path))
+			return
+		}
+		t.Device = &DeviceStateTransition{
+			OldState: DeviceState(oldState),
+			NewState: DeviceState(newState),
+			Reason:   DeviceStateReason(reason),
+		}
+		if iface := c.lookUpDeviceInterface(signal.Path); iface != "" {
+			t.Interfaces = []string{iface}
+		}
+	case nmName + ".Connection.Active.StateChanged":
+		var state, reason uint32
+		if err := dbus.Store(signal.Body, &state, &reason); err != nil {
+			c.l.Warn(errors.Wrapf(
+				err, "couldn't parse state transition of active connection %s", signal.Path,
+			))
+			return
+		}
+		var activeConn ActiveConnStateTransition
+		activeConn, t.Interfaces = c.lookUpActiveConn(signal.Path)
+		activeConn.State = ActiveConnState(state)
+		activeConn.Reason = ActiveConnStateReason(reason)
+		t.ActiveConn = &activeConn
+		if activeConn.State == activeConnStateDeactivated {
+			c.history.mu.Lock()
+			delete(c.history.activeConns, signal.Path)
+			c.history.mu.Unlock()
+		}
+	}
+	t.Time = received
+
+	if err := c.history.record(t); err != nil {
+		c.l.Error(errors.Wrap(err, "couldn't record state transition"))
+	}
+}
+
+// lookUpDeviceInterface returns the network interface of the device, or an empty string if it
+// can't be determined.
+func (c *Client) lookUpDeviceInterface(devPath dbus.ObjectPath) string {
+	c.history.mu.Lock()
+	iface, ok := c.history.deviceInterfaces[devPath]
+	c.history.mu.Unlock()
+	if ok {
+		return iface
+	}
+
+	dev := Device{}
+	devo := c.bus.Object(nmName, devPath)
+	if err := devo.StoreProperty(nmName+".Device.Interface", &dev.ControlInterface); err != nil {
+		return ""
+	}
+	if err := devo.StoreProperty(nmName+".Device.IpInterface", &dev.IpInterface); err != nil {
+		return ""
+	}
+	iface = cmp.Or(dev.IpInterface, dev.ControlInterface)
+
+	c.history.mu.Lock()
+	defer c.history.mu.Unlock()
+	c.history.deviceInterfaces[devPath] = iface
+	return iface
+}
+
+// lookUpActiveConn returns the identity of the active connection and the network interfaces of its
+// devices, which are empty if they can't be determined.
+func (c *Client) lookUpActiveConn(
+	connPath dbus.ObjectPath,
+) (activeConn ActiveConnStateTransition, interfaces []string) {
+	c.history.mu.Lock()
+	identity, ok := c.history.activeConns[connPath]
+	c.history.mu.Unlock()
+
+	const connName = nmName + ".Connection.Active"
+	conno := c.bus.Object(nmName, connPath)
+	if !ok {
+		var rawUUID string
+		if err := conno.StoreProperty(connName+".Id", &identity.activeConn.ID); err != nil {
+			return ActiveConnStateTransition{}, nil
+		}
+		if err := conno.StoreProperty(connName+".Uuid", &rawUUID); err != nil {
+			return ActiveConnStateTransition{}, nil
+		}
+		identity.activeConn.UUID, _ = uuid.Parse(rawUUID)
+		if err := conno.StoreProperty(connName+".Type", &identity.activeConn.Type); err != nil {
+			return ActiveConnStateTransition{}, nil
+		}
+	}
+
+	// Note: the devices of an active connection may change while it's activated (e.g. once a VPN
+	// tunnel's device is created), so we only fall back to the remembered devices if the active
+	// connection was already removed
+	var devPaths []dbus.ObjectPath
+	if err := conno.StoreProperty(connName+".Devices", &devPaths); err == nil {
+		for _, devPath := range devPaths {
+			if iface := c.lookUpDeviceInterface(devPath); iface != "" {
+				interfaces = append(interfaces, iface)
+			}
+		}
+	}
+	slices.Sort(interfaces)
+	if len(interfaces) > 0 {
+		identity.interfaces = interfaces
+	}
+
+	c.history.mu.Lock()
+	defer c.history.mu.Unlock()
+	c.history.activeConns[connPath] = identity
+	return identity.activeConn, identity.interfaces
+}
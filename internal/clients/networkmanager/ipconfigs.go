@@ -28,7 +28,10 @@ type IPRoute struct {
 	Destination netip.Prefix
 	NextHop     netip.Addr
 	Metric      uint32
-	Attributes  map[string]string
+	// HasMetric is set when the route has its own metric, even if the metric is 0. Otherwise, the
+	// route uses the route metric of the connection profile or the device.
+	HasMetric  bool
+	Attributes map[string]string
 }
 
 type DNSConfig struct {
@@ -138,6 +141,14 @@ func parseIPAddress(rawAddress map[string]dbus.Variant) (address IPAddress, err
 	return address, nil
 }
 
+// dbusValue returns the representation of the address in NetworkManager's address-data settings.
+func (a IPAddress) dbusValue() map[string]dbus.Variant {
+	return map[string]dbus.Variant{
+		"address": dbus.MakeVariant(a.Prefix.Addr().String()),
+		"prefix":  dbus.MakeVariant(uint32(a.Prefix.Bits())), //nolint:gosec // bits are in [0, 128]
+	}
+}
+
 func parseIPAddressString(raw dbus.Variant) (parsed netip.Addr, err error) {
 	addr, ok := raw.Value().(string)
 	if !ok {
@@ -182,6 +193,7 @@ func parseIPRoute(rawRoute map[string]dbus.Variant) (route IPRoute, err error) {
 		if route.Metric, ok = raw.Value().(uint32); !ok {
 			return IPRoute{}, errors.Errorf("metric has unexpected type %T", raw.Value())
 		}
+		route.HasMetric = true
 	}
 
 	route.Attributes = make(map[string]string)
@@ -194,6 +206,21 @@ func parseIPRoute(rawRoute map[string]dbus.Variant) (route IPRoute, err error) {
 	return route, nil
 }
 
+// dbusValue returns the representation of the route in NetworkManager's route-data settings.
+func (r IPRoute) dbusValue() map[string]dbus.Variant {
+	value := map[string]dbus.Variant{
+		"dest":   dbus.MakeVariant(r.Destination.Masked().Addr().String()),
+		"prefix": dbus.MakeVariant(uint32(r.Destination.Bits())), //nolint:gosec // bits are in [0, 128]
+	}
+	if r.NextHop.IsValid() {
+		value["next-hop"] = dbus.MakeVariant(r.NextHop.String())
+	}
+	if r.HasMetric {
+		value["metric"] = dbus.MakeVariant(r.Metric)
+	}
+	return value
+}
+
 func parseNameservers(confo dbus.BusObject, ipVersion uint8) (nameservers []netip.Addr, err error) {
 	ipConfigName := fmt.Sprintf(".IP%dConfig", ipVersion)
 
@@ -0,0 +1,320 @@
+package networkmanager
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Keyfile is a connection profile in NetworkManager's keyfile format (i.e. the contents of a
+// .nmconnection file).
+type Keyfile struct {
+	Sections []KeyfileSection
+}
+
+type KeyfileSection struct {
+	Name    string
+	Entries []KeyfileEntry
+}
+
+type KeyfileEntry struct {
+	Key   string
+	Value string
+}
+
+// keyfileSections are the names of the settings sections which may appear in keyfiles, including
+// the aliases used by the keyfile format; sections mapped to true are also connection types.
+var keyfileSections = map[string]bool{
+	"connection": false, "ipv4": false, "ipv6": false, "proxy": false, "ethtool": false,
+	"match": false, "user": false, "hostname": false, "link": false, "tc": false, "sriov": false,
+	"dcb": false, "ppp": false, "serial": false, "802-1x": false, "bond-port": false,
+	"bridge-port": false, "team-port": false, "vpn-secrets": false, "ovs-external-ids": false,
+	"ovs-other-config": false, "ovs-dpdk": false, "ovs-patch": false,
+	"wifi-security": false, "802-11-wireless-security": false,
+	"ethernet": true, "802-3-ethernet": true, "wifi": true, "802-11-wireless": true,
+	"gsm": true, "cdma": true, "bluetooth": true, "pppoe": true, "adsl": true, "bond": true,
+	"bridge": true, "team": true, "vlan": true, "vxlan": true, "macvlan": true, "macsec": true,
+	"ip-tunnel": true, "tun": true, "veth": true, "vrf": true, "loopback": true, "dummy": true,
+	"generic": true, "infiniband": true, "wireguard": true, "vpn": true, "wifi-p2p": true,
+	"wpan": true, "6lowpan": true, "olpc-mesh": true, "802-11-olpc-mesh": true, "hsr": true,
+	"ipvlan": true, "ovs-bridge": true, "ovs-interface": true, "ovs-port": true,
+}
+
+// wireGuardPeerSectionPrefix is the prefix of the sections for the peers of WireGuard connection
+// profiles, which are followed by the public key of each peer.
+const wireGuardPeerSectionPrefix = "wireguard-peer."
+
+// keyfileSectionAliases maps the names of settings sections to the names used in keyfiles.
+var keyfileSectionAliases = map[string]string{
+	"802-3-ethernet":           "ethernet",
+	"802-11-wireless":          "wifi",
+	"802-11-wireless-security": "wifi-security",
+	"802-11-olpc-mesh":         "olpc-mesh",
+}
+
+// keyfileSecrets lists the secret keys of each section, by the section's keyfile name.
+var keyfileSecrets = map[string][]string{
+	"wifi-security": {"psk", "wep-key0", "wep-key1", "wep-key2", "wep-key3", "leap-password"},
+	"802-1x": {
+		"password", "password-raw", "pin", "private-key-password", "phase2-private-key-password",
+		"ca-cert-password", "client-cert-password", "phase2-ca-cert-password",
+		"phase2-client-cert-password",
+	},
+	"gsm":                      {"password", "pin"},
+	"cdma":                     {"password"},
+	"pppoe":                    {"password"},
+	"adsl":                     {"password"},
+	"macsec":                   {"mka-cak"},
+	"wireguard":                {"private-key"},
+	wireGuardPeerSectionPrefix: {"preshared-key"},
+}
+
+// keyfilePrivateKeys lists the keys of each section, by the section's keyfile name, which refer to
+// private keys. They usually hold the path of a file, but they may instead hold the private key
+// itself as a blob.
+var keyfilePrivateKeys = map[string][]string{
+	"802-1x": {"private-key", "phase2-private-key"},
+}
+
+// keyfileBlobPrefix is the prefix of values which hold a file's contents as a blob, rather than
+// the path of a file.
+const keyfileBlobPrefix = "data:"
+
+// keyfile8021xFileKeys lists the keys of the 802-1x section which refer to files or directories.
+// Certificates and private keys may be stored as blobs instead.
+var keyfile8021xFileKeys = []string{
+	"ca-cert", "client-cert", "private-key", "phase2-ca-cert", "phase2-client-cert",
+	"phase2-private-key", "ca-path", "phase2-ca-path", "pac-file",
+}
+
+// keyfileSectionKind returns the name under which the section's keys are listed in keyfileSecrets
+// and keyfilePrivateKeys, for a section named either by its settings name or by its keyfile name.
+func keyfileSectionKind(section string) string {
+	if alias, ok := keyfileSectionAliases[section]; ok {
+		return alias
+	}
+	if strings.HasPrefix(section, wireGuardPeerSectionPrefix) {
+		return wireGuardPeerSectionPrefix
+	}
+	return section
+}
+
+// IsSecretSetting checks whether the setting (keyed by its "section.key" name, e.g.
+// "802-11-wireless-security.psk") may hold a secret, which must not be shown. This includes
+// private keys, which may be stored in the connection profile.
+func IsSecretSetting(key string) bool {
+	section, key, ok := strings.Cut(key, ".")
+	if !ok {
+		return false
+	}
+	if section == "vpn" && key == "secrets" {
+		return true
+	}
+	kind := keyfileSectionKind(section)
+	return slices.Contains(keyfileSecrets[kind], key) ||
+		slices.Contains(keyfilePrivateKeys[kind], key)
+}
+
+var keyfileKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:+-]*$`)
+
+// ParseKeyfile parses and checks a connection profile in NetworkManager's keyfile format. It only
+// checks the structure of the keyfile and the connection section, since NetworkManager checks the
+// values of all settings when it loads the keyfile.
+func ParseKeyfile(data []byte) (k Keyfile, err error) {
+	var section *KeyfileSection
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			if err = checkKeyfileSection(name); err != nil {
+				return k, errors.Wrapf(err, "line %d", lineNum)
+			}
+			if k.Section(name) != nil {
+				return k, errors.Errorf("line %d: section [%s] appears more than once", lineNum, name)
+			}
+			k.Sections = append(k.Sections, KeyfileSection{Name: name})
+			section = &k.Sections[len(k.Sections)-1]
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return k, errors.Errorf("line %d: expected a key=value pair or a [section]", lineNum)
+		}
+		key = strings.TrimSpace(key)
+		if section == nil {
+			return k, errors.Errorf("line %d: key %s is outside of any section", lineNum, key)
+		}
+		if !keyfileKeyPattern.MatchString(key) {
+			return k, errors.Errorf("line %d: invalid key %s", lineNum, key)
+		}
+		if _, ok := section.Get(key); ok {
+			return k, errors.Errorf(
+				"line %d: key %s appears more than once in section [%s]", lineNum, key, section.Name,
+			)
+		}
+		section.Entries = append(section.Entries, KeyfileEntry{
+			Key: key, Value: strings.TrimSpace(value),
+		})
+	}
+	if err = scanner.Err(); err != nil {
+		return k, errors.Wrap(err, "couldn't read keyfile")
+	}
+	return k, k.check()
+}
+
+func checkKeyfileSection(name string) error {
+	if _, ok := keyfileSections[name]; ok {
+		return nil
+	}
+	if peer, ok := strings.CutPrefix(name, wireGuardPeerSectionPrefix); ok && peer != "" {
+		return nil
+	}
+	return errors.Errorf("unknown section [%s]", name)
+}
+
+func (k Keyfile) check() error {
+	conn := k.Section("connection")
+	if conn == nil {
+		return errors.New("the keyfile has no [connection] section")
+	}
+	if id, _ := conn.Get("id"); id == "" {
+		return errors.New("the [connection] section has no id")
+	}
+	connType, _ := conn.Get("type")
+	if !keyfileSections[connType] {
+		return errors.Errorf("unknown connection type %s", connType)
+	}
+	if rawUUID, ok := conn.Get("uuid"); ok {
+		if _, err := uuid.Parse(rawUUID); err != nil {
+			return errors.Wrapf(err, "invalid uuid %s", rawUUID)
+		}
+	}
+	return nil
+}
+
+// Section returns the section with the name, or nil if the keyfile has no such section.
+func (k Keyfile) Section(name string) *KeyfileSection {
+	for i, section := range k.Sections {
+		if section.Name == name {
+			return &k.Sections[i]
+		}
+	}
+	return nil
+}
+
+// ID returns the name of the connection profile.
+func (k Keyfile) ID() string {
+	id, _ := k.Section("connection").Get("id")
+	return id
+}
+
+// UUID returns the UUID of the connection profile, which is the zero value if the keyfile has no
+// UUID.
+func (k Keyfile) UUID() uuid.UUID {
+	rawUUID, _ := k.Section("connection").Get("uuid")
+	uid, _ := uuid.Parse(rawUUID)
+	return uid
+}
+
+// WithUUID returns a copy of the keyfile with the UUID of the connection profile replaced.
+func (k Keyfile) WithUUID(uid uuid.UUID) Keyfile {
+	sections := make([]KeyfileSection, 0, len(k.Sections))
+	for _, section := range k.Sections {
+		if section.Name == "connection" {
+			section = section.with("uuid", uid.String())
+		}
+		sections = append(sections, section)
+	}
+	return Keyfile{Sections: sections}
+}
+
+// WithoutSecrets returns a copy of the keyfile without any passwords or private keys.
+func (k Keyfile) WithoutSecrets() Keyfile {
+	sections := make([]KeyfileSection, 0, len(k.Sections))
+	for _, section := range k.Sections {
+		if section.Name == "vpn-secrets" {
+			continue
+		}
+		kind := keyfileSectionKind(section.Name)
+		entries := make([]KeyfileEntry, 0, len(section.Entries))
+		for _, entry := range section.Entries {
+			if slices.Contains(keyfileSecrets[kind], entry.Key) {
+				continue
+			}
+			if slices.Contains(keyfilePrivateKeys[kind], entry.Key) &&
+				strings.HasPrefix(entry.Value, keyfileBlobPrefix) {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		sections = append(sections, KeyfileSection{Name: section.Name, Entries: entries})
+	}
+	return Keyfile{Sections: sections}
+}
+
+// Referenced8021xFiles returns the paths of the files and directories which the keyfile's 802.1X
+// settings refer to, keyed by the settings' keys (e.g. "ca-cert"). Certificates and private keys
+// which are stored as blobs aren't included.
+func (k Keyfile) Referenced8021xFiles() map[string]string {
+	files := make(map[string]string)
+	section := k.Section("802-1x")
+	for _, key := range keyfile8021xFileKeys {
+		value, _ := section.Get(key)
+		if value == "" || strings.HasPrefix(value, keyfileBlobPrefix) {
+			continue
+		}
+		files[key] = strings.TrimPrefix(value, certPathScheme)
+	}
+	return files
+}
+
+// Bytes returns the keyfile in NetworkManager's keyfile format.
+func (k Keyfile) Bytes() []byte {
+	var b bytes.Buffer
+	for i, section := range k.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", section.Name)
+		for _, entry := range section.Entries {
+			fmt.Fprintf(&b, "%s=%s\n", entry.Key, entry.Value)
+		}
+	}
+	return b.Bytes()
+}
+
+// Get returns the value of the key in the section, if the section isn't nil and has the key.
+func (s *KeyfileSection) Get(key string) (value string, ok bool) {
+	if s == nil {
+		return "", false
+	}
+	for _, entry := range s.Entries {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+func (s KeyfileSection) with(key, value string) KeyfileSection {
+	entries := slices.Clone(s.Entries)
+	for i, entry := range entries {
+		if entry.Key == key {
+			entries[i].Value = value
+			return KeyfileSection{Name: s.Name, Entries: entries}
+		}
+	}
+	entries = append(entries, KeyfileEntry{Key: key, Value: value})
+	return KeyfileSection{Name: s.Name, Entries: entries}
+}
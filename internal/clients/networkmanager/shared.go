@@ -0,0 +1,44 @@
+package networkmanager
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// SharedDHCPRange returns the range of addresses which NetworkManager's DHCP server hands out to
+// other devices on a network interface with the address, when the interface's IPv4 method is
+// "shared". Like NetworkManager, it treats subnets larger than /24 as the /24 subnet containing the
+// address, and it leaves up to 8 addresses next to the interface's own address for devices with
+// static addresses. ok is false if the subnet is too small for DHCP.
+func SharedDHCPRange(address netip.Prefix) (first, last netip.Addr, ok bool) {
+	const (
+		ipv4Bits      = 32
+		maxPrefixBits = 30
+		maxSubnetBits = 24
+		// NetworkManager leaves a tenth of the addresses, but at most 8, for static addresses:
+		reservedDivisor = 10
+		maxReserved     = 8
+	)
+	if !address.Addr().Is4() || address.Bits() < 0 || address.Bits() > maxPrefixBits {
+		return netip.Addr{}, netip.Addr{}, false
+	}
+	host := binary.BigEndian.Uint32(address.Addr().AsSlice())
+	mask := ^uint32(0) << (ipv4Bits - max(address.Bits(), maxSubnetBits))
+	firstN := host&mask + 1
+	lastN := host | ^mask - 1
+	if host < firstN || host > lastN {
+		return netip.Addr{}, netip.Addr{}, false // the address is the network or broadcast address
+	}
+
+	// NetworkManager uses whichever side of the interface's own address has more addresses:
+	if host-firstN > lastN-host {
+		lastN = host - min((host-firstN)/reservedDivisor, maxReserved) - 1
+	} else {
+		firstN = host + min((lastN-host)/reservedDivisor, maxReserved) + 1
+	}
+	return uint32ToAddr(firstN), uint32ToAddr(lastN), true
+}
+
+func uint32ToAddr(n uint32) netip.Addr {
+	return netip.AddrFrom4([4]byte(binary.BigEndian.AppendUint32(nil, n)))
+}
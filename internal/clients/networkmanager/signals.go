@@ -0,0 +1,313 @@
+package networkmanager
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/pkg/errors"
+)
+
+// watchedSignals lists the D-Bus signals which indicate that some part of NetworkManager's state
+// has changed.
+var watchedSignals = []struct {
+	iface  string
+	member string
+}{
+	{"org.freedesktop.DBus.Properties", "PropertiesChanged"},
+	{nmName, "DeviceAdded"},
+	{nmName, "DeviceRemoved"},
+	{nmName, "StateChanged"},
+	{nmName + ".Device", "StateChanged"},
+	{nmName + ".Connection.Active", "StateChanged"},
+	{nmName + ".Device.Wireless", "AccessPointAdded"},
+	{nmName + ".Device.Wireless", "AccessPointRemoved"},
+	{nmName + ".Settings", "NewConnection"},
+	{nmName + ".Settings", "ConnectionRemoved"},
+	{nmName + ".Settings.Connection", "Updated"},
+	{nmName + ".Settings.Connection", "Removed"},
+}
+
+// ignoredProperties lists the D-Bus properties, keyed by their D-Bus interfaces, whose changes
+// don't invalidate the model, because they change frequently but aren't shown or don't need to be
+// shown immediately. An interface which maps to nil has all of its properties ignored. The signal
+// strengths of access points are still refreshed whenever a Wi-Fi scan finishes, since that
+// changes the device's LastScan property.
+var ignoredProperties = map[string][]string{
+	nmName + ".AccessPoint":       {"Strength", "LastSeen"},
+	nmName + ".Device.Statistics": nil,
+}
+
+// model is an in-memory cache of query results about NetworkManager's state, which is invalidated
+// whenever NetworkManager reports a change to its state.
+type model struct {
+	mu sync.Mutex
+	// watching is true when changes are reported by D-Bus signals, so that query results can be
+	// cached; otherwise, nothing is cached.
+	watching bool
+	// generation is incremented whenever NetworkManager's state changes, so that query results
+	// which were started before a change won't be cached after that change.
+	generation uint64
+
+	nm           *NetworkManager
+	devices      []Device
+	connProfiles []ConnProfile
+	networks     map[string]map[string][]AccessPoint // keyed by interface
+
+	subscribers map[chan struct{}]struct{}
+}
+
+func newModel() *model {
+	return &model{
+		networks:    make(map[string]map[string][]AccessPoint),
+		subscribers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// watchSignals subscribes to D-Bus signals from NetworkManager, and invalidates the model whenever
+// NetworkManager's state changes, until the context is canceled.
+func (c *Client) watchSignals(ctx context.Context) error {
+	for _, s := range watchedSignals {
+		opts := []dbus.MatchOption{
+			dbus.WithMatchSender(nmName),
+			dbus.WithMatchInterface(s.iface),
+			dbus.WithMatchMember(s.member),
+		}
+		if s.iface == "org.freedesktop.DBus.Properties" {
+			opts = append(opts, dbus.WithMatchPathNamespace("/org/freedesktop/NetworkManager"))
+		}
+		if err := c.bus.AddMatchSignalContext(ctx, opts...); err != nil {
+			return errors.Wrapf(err, "couldn't subscribe to %s.%s signals", s.iface, s.member)
+		}
+	}
+
+	const signalBufferSize = 64
+	signals := make(chan *dbus.Signal, signalBufferSize)
+	c.bus.Signal(signals)
+
+	c.model.mu.Lock()
+	c.model.watching = true
+	c.model.mu.Unlock()
+
+	go c.recordStateTransitions(ctx)
+	go func() {
+		defer func() {
+			c.bus.RemoveSignal(signals)
+			c.model.mu.Lock()
+			c.model.watching = false
+			c.model.mu.Unlock()
+			c.invalidate()
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case signal, ok := <-signals:
+				if !ok {
+					return
+				}
+				c.queueStateTransition(signal)
+				if !changesModel(signal) {
+					continue
+				}
+				c.invalidate()
+			}
+		}
+	}()
+	return nil
+}
+
+// changesModel checks whether the signal reports a change to NetworkManager's state which may
+// invalidate the model, i.e. anything other than a change to ignored properties.
+func changesModel(signal *dbus.Signal) bool {
+	if signal.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+		return true
+	}
+	var (
+		iface       string
+		changed     map[string]dbus.Variant
+		invalidated []string
+	)
+	if err := dbus.Store(signal.Body, &iface, &changed, &invalidated); err != nil {
+		return true
+	}
+	ignored, ok := ignoredProperties[iface]
+	if !ok {
+		return true
+	}
+	if ignored == nil {
+		return false
+	}
+	for property := range changed {
+		if !slices.Contains(ignored, property) {
+			return true
+		}
+	}
+	for _, property := range invalidated {
+		if !slices.Contains(ignored, property) {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidate clears all cached query results and notifies all subscribers of a change.
+func (c *Client) invalidate() {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	c.model.generation++
+	c.model.nm = nil
+	c.model.devices = nil
+	c.model.connProfiles = nil
+	clear(c.model.networks)
+	for subscriber := range c.model.subscribers {
+		select {
+		case subscriber <- struct{}{}:
+		default: // the subscriber already has a pending notification
+		}
+	}
+}
+
+// Subscribe returns a channel which receives a value whenever NetworkManager's state changes, until
+// the context is canceled. Multiple changes may be coalesced into a single notification. If
+// changes can't be watched through D-Bus signals, the channel instead receives a value at regular
+// intervals.
+func (c *Client) Subscribe(ctx context.Context) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+	c.model.mu.Lock()
+	c.model.subscribers[changes] = struct{}{}
+	watching := c.model.watching
+	c.model.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.model.mu.Lock()
+			delete(c.model.subscribers, changes)
+			c.model.mu.Unlock()
+		}()
+		if watching {
+			<-ctx.Done()
+			return
+		}
+
+		const pollInterval = 4 * time.Second
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case changes <- struct{}{}:
+				default: // the subscriber already has a pending notification
+				}
+			}
+		}
+	}()
+	return changes
+}
+
+// Caching
+
+// cacheGeneration returns the model's current generation, which must be checked when caching
+// query results.
+func (c *Client) cacheGeneration() uint64 {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	return c.model.generation
+}
+
+func (c *Client) getCachedNetworkManager() (nm NetworkManager, ok bool) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if c.model.nm == nil {
+		return NetworkManager{}, false
+	}
+	return *c.model.nm, true
+}
+
+func (c *Client) cacheNetworkManager(generation uint64, nm NetworkManager) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if !c.model.watching || c.model.generation != generation {
+		return
+	}
+	c.model.nm = &nm
+}
+
+func (c *Client) getCachedDevices() (devs []Device, ok bool) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if c.model.devices == nil {
+		return nil, false
+	}
+	return slices.Clone(c.model.devices), true
+}
+
+func (c *Client) cacheDevices(generation uint64, devs []Device) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if !c.model.watching || c.model.generation != generation {
+		return
+	}
+	c.model.devices = slices.Clone(devs)
+}
+
+func (c *Client) getCachedConnProfiles() (conns []ConnProfile, ok bool) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if c.model.connProfiles == nil {
+		return nil, false
+	}
+	return slices.Clone(c.model.connProfiles), true
+}
+
+func (c *Client) cacheConnProfiles(generation uint64, conns []ConnProfile) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if !c.model.watching || c.model.generation != generation {
+		return
+	}
+	c.model.connProfiles = slices.Clone(conns)
+}
+
+func cloneNetworks(networks map[string][]AccessPoint) map[string][]AccessPoint {
+	cloned := maps.Clone(networks)
+	for ssid, aps := range cloned {
+		cloned[ssid] = slices.Clone(aps)
+	}
+	return cloned
+}
+
+func (c *Client) getCachedNetworks(iface string) (networks map[string][]AccessPoint, ok bool) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	cached, ok := c.model.networks[iface]
+	if !ok {
+		return nil, false
+	}
+	return cloneNetworks(cached), true
+}
+
+func (c *Client) cacheNetworks(generation uint64, iface string, networks map[string][]AccessPoint) {
+	c.model.mu.Lock()
+	defer c.model.mu.Unlock()
+
+	if !c.model.watching || c.model.generation != generation {
+		return
+	}
+	c.model.networks[iface] = cloneNetworks(networks)
+}
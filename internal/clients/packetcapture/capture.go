@@ -0,0 +1,285 @@
+// Package packetcapture captures raw network packets from a network interface into pcap files
+package packetcapture
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// Filter
+
+type Filter string
+
+const (
+	FilterAll  Filter = ""
+	FilterDHCP Filter = "dhcp"
+	FilterDNS  Filter = "dns"
+)
+
+func ParseFilter(raw string) (Filter, error) {
+	switch f := Filter(raw); f {
+	default:
+		return f, errors.Errorf("unknown capture filter %s", raw)
+	case FilterAll, FilterDHCP, FilterDNS:
+		return f, nil
+	}
+}
+
+func (f Filter) ports() []uint16 {
+	switch f {
+	default:
+		return nil
+	case FilterDHCP:
+		return []uint16{67, 68, 546, 547}
+	case FilterDNS:
+		return []uint16{53}
+	}
+}
+
+// Options
+
+type Options struct {
+	MaxDuration time.Duration
+	MaxBytes    int64
+	Filter      Filter
+}
+
+// Capture
+
+const (
+	// pcap link-layer header types, from https://www.tcpdump.org/linktypes.html
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+
+	// ARP hardware types, from linux/if_arp.h
+	arpHrdEther = 1
+	arpHrdNone  = 65534
+
+	snapLen = 65535
+	// readTimeout bounds how long a single read can block, so that we can regularly check for
+	// cancellation and for the capture deadline:
+	readTimeout = 500 * time.Millisecond
+)
+
+// Capture writes a pcap file of packets received or sent on the specified network interface to w,
+// until either the context is canceled, the maximum duration has elapsed, or the maximum number of
+// bytes has been written.
+func Capture(ctx context.Context, iface string, o Options, w io.Writer) error {
+	netIface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't find network interface %s", iface)
+	}
+	linkType, err := getLinkType(iface)
+	if err != nil {
+		return err
+	}
+
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW|unix.SOCK_CLOEXEC, int(htons(unix.ETH_P_ALL)))
+	if err != nil {
+		return errors.Wrap(err, "couldn't open packet socket")
+	}
+	defer func() {
+		_ = unix.Close(fd)
+	}()
+	if err = unix.Bind(fd, &unix.SockaddrLinklayer{
+		Protocol: htons(unix.ETH_P_ALL),
+		Ifindex:  netIface.Index,
+	}); err != nil {
+		return errors.Wrapf(err, "couldn't bind packet socket to %s", iface)
+	}
+	tv := unix.NsecToTimeval(readTimeout.Nanoseconds())
+	if err = unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return errors.Wrap(err, "couldn't set read timeout on packet socket")
+	}
+
+	written, err := writeFileHeader(w, linkType)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(o.MaxDuration)
+	buf := make([]byte, snapLen)
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		n, _, err := unix.Recvfrom(fd, buf, 0)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			return errors.Wrapf(err, "couldn't read packet from %s", iface)
+		}
+		packet := buf[:n]
+		if !matches(packet, linkType, o.Filter) {
+			continue
+		}
+		const recordHeaderLen = 16
+		if o.MaxBytes > 0 && written+recordHeaderLen+int64(n) > o.MaxBytes {
+			return nil
+		}
+		recordLen, err := writeRecord(w, time.Now(), packet)
+		if err != nil {
+			return err
+		}
+		written += recordLen
+	}
+	return nil
+}
+
+func htons(i uint16) uint16 {
+	return (i<<8)&0xff00 | i>>8
+}
+
+func getLinkType(iface string) (uint32, error) {
+	rawType, err := os.ReadFile(fmt.Sprintf("/sys/class/net/%s/type", iface))
+	if err != nil {
+		return 0, errors.Wrapf(err, "couldn't determine hardware type of %s", iface)
+	}
+	hwType, err := strconv.Atoi(strings.TrimSpace(string(rawType)))
+	if err != nil {
+		return 0, errors.Wrapf(err, "couldn't parse hardware type of %s", iface)
+	}
+	switch hwType {
+	default:
+		return 0, errors.Errorf("unsupported hardware type %d of %s", hwType, iface)
+	case arpHrdEther:
+		return linkTypeEthernet, nil
+	case arpHrdNone:
+		return linkTypeRaw, nil
+	}
+}
+
+// pcap file format
+
+func writeFileHeader(w io.Writer, linkType uint32) (written int64, err error) {
+	const (
+		magic        = 0xa1b2c3d4
+		versionMajor = 2
+		versionMinor = 4
+	)
+	header := make([]byte, 0, 24)
+	header = binary.LittleEndian.AppendUint32(header, magic)
+	header = binary.LittleEndian.AppendUint16(header, versionMajor)
+	header = binary.LittleEndian.AppendUint16(header, versionMinor)
+	header = binary.LittleEndian.AppendUint32(header, 0) // thiszone
+	header = binary.LittleEndian.AppendUint32(header, 0) // sigfigs
+	header = binary.LittleEndian.AppendUint32(header, snapLen)
+	header = binary.LittleEndian.AppendUint32(header, linkType)
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), errors.Wrap(err, "couldn't write pcap file header")
+	}
+	return int64(n), nil
+}
+
+func writeRecord(w io.Writer, timestamp time.Time, packet []byte) (written int64, err error) {
+	record := make([]byte, 0, 16+len(packet))
+	// Note: pcap only has 32-bit timestamps, and packet lengths are bounded by snapLen:
+	//nolint:gosec // see above
+	seconds, micros, length := uint32(timestamp.Unix()), uint32(timestamp.Nanosecond()/1000),
+		uint32(len(packet))
+	record = binary.LittleEndian.AppendUint32(record, seconds)
+	record = binary.LittleEndian.AppendUint32(record, micros)
+	record = binary.LittleEndian.AppendUint32(record, length) // captured length
+	record = binary.LittleEndian.AppendUint32(record, length) // original length
+	record = append(record, packet...)
+	n, err := w.Write(record)
+	if err != nil {
+		return int64(n), errors.Wrap(err, "couldn't write pcap packet record")
+	}
+	return int64(n), nil
+}
+
+// Filtering
+
+func matches(packet []byte, linkType uint32, f Filter) bool {
+	ports := f.ports()
+	if len(ports) == 0 {
+		return true
+	}
+
+	if linkType == linkTypeEthernet {
+		const ethHeaderLen = 14
+		if len(packet) < ethHeaderLen {
+			return false
+		}
+		etherType := binary.BigEndian.Uint16(packet[12:14])
+		packet = packet[ethHeaderLen:]
+		const etherTypeVLAN = 0x8100
+		if etherType == etherTypeVLAN {
+			const vlanTagLen = 4
+			if len(packet) < vlanTagLen {
+				return false
+			}
+			packet = packet[vlanTagLen:]
+		}
+	}
+
+	srcPort, dstPort, ok := parseTransportPorts(packet)
+	if !ok {
+		return false
+	}
+	for _, port := range ports {
+		if srcPort == port || dstPort == port {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTransportPorts returns the source and destination TCP/UDP ports of the provided IP packet.
+func parseTransportPorts(packet []byte) (srcPort, dstPort uint16, ok bool) {
+	if len(packet) < 1 {
+		return 0, 0, false
+	}
+	var (
+		protocol byte
+		payload  []byte
+	)
+	switch packet[0] >> 4 {
+	default:
+		return 0, 0, false
+	case 4:
+		const minHeaderLen = 20
+		if len(packet) < minHeaderLen {
+			return 0, 0, false
+		}
+		headerLen := int(packet[0]&0x0f) * 4
+		if headerLen < minHeaderLen || len(packet) < headerLen {
+			return 0, 0, false
+		}
+		protocol = packet[9]
+		payload = packet[headerLen:]
+	case 6:
+		// Note: we don't follow IPv6 extension headers, which is fine for the kinds of simple
+		// filtering we need for DHCPv6 and DNS.
+		const headerLen = 40
+		if len(packet) < headerLen {
+			return 0, 0, false
+		}
+		protocol = packet[6]
+		payload = packet[headerLen:]
+	}
+
+	const (
+		protocolTCP = 6
+		protocolUDP = 17
+	)
+	if protocol != protocolTCP && protocol != protocolUDP {
+		return 0, 0, false
+	}
+	if len(payload) < 4 {
+		return 0, 0, false
+	}
+	return binary.BigEndian.Uint16(payload[0:2]), binary.BigEndian.Uint16(payload[2:4]), true
+}
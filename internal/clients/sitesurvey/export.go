@@ -0,0 +1,62 @@
+package sitesurvey
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var csvHeader = []string{
+	"time", "interface", "ssid", "bssid", "band", "channel", "frequency_mhz", "bandwidth_mhz",
+	"strength_percent", "max_bitrate_kbps", "security",
+}
+
+// WriteCSV writes the survey's access points as CSV, with one row per access point.
+func (s Survey) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return errors.Wrap(err, "couldn't write CSV header")
+	}
+	scanned := s.Time.UTC().Format(time.RFC3339)
+	for _, ap := range s.AccessPoints {
+		channel := ""
+		if ap.Channel != 0 {
+			channel = strconv.FormatUint(uint64(ap.Channel), 10)
+		}
+		bandwidth := ""
+		if ap.Bandwidth != 0 {
+			bandwidth = strconv.FormatUint(uint64(ap.Bandwidth), 10)
+		}
+		if err := cw.Write([]string{
+			scanned,
+			csvCell(s.Interface),
+			csvCell(ap.SSID),
+			csvCell(ap.BSSID),
+			csvCell(ap.Band),
+			channel,
+			strconv.FormatUint(uint64(ap.Frequency), 10),
+			bandwidth,
+			strconv.FormatUint(uint64(ap.Strength), 10),
+			strconv.FormatUint(uint64(ap.MaxBitrate), 10),
+			csvCell(strings.Join(ap.Security, "; ")),
+		}); err != nil {
+			return errors.Wrapf(err, "couldn't write CSV row for access point %s", ap.BSSID)
+		}
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "couldn't write CSV")
+}
+
+// csvCell neutralizes text which spreadsheet programs would otherwise interpret as a formula, by
+// prefixing it with a single quote. This matters because SSIDs are chosen by whoever broadcasts
+// them, so a nearby access point could otherwise inject formulas into the exported spreadsheet.
+func csvCell(text string) string {
+	if text != "" && strings.ContainsAny(text[:1], "=+-@\t\r") {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,230 @@
+// Package sitesurvey analyzes the Wi-Fi networks found by scans, to estimate how congested each
+// Wi-Fi channel is
+package sitesurvey
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	nm "github.com/openUC2/machine-admin/internal/clients/networkmanager"
+	"github.com/openUC2/machine-admin/internal/clients/wifireg"
+)
+
+// AccessPoint is an access point found by a scan.
+type AccessPoint struct {
+	SSID  string `json:"ssid"`
+	BSSID string `json:"bssid"`
+	// Band and Channel are empty if the access point's frequency isn't one of wifireg.Channels
+	Band       string   `json:"band"`
+	Channel    uint32   `json:"channel"`
+	Frequency  uint32   `json:"frequencyMHz"`
+	Bandwidth  uint32   `json:"bandwidthMHz,omitempty"` // zero if unknown
+	Strength   uint8    `json:"strengthPercent"`
+	MaxBitrate uint32   `json:"maxBitrateKbps"`
+	Security   []string `json:"security"`
+}
+
+func newAccessPoint(ap nm.AccessPoint) AccessPoint {
+	a := AccessPoint{
+		SSID:       ap.SSID,
+		BSSID:      ap.BSSID,
+		Frequency:  ap.Frequency,
+		Bandwidth:  ap.Bandwidth,
+		Strength:   ap.Strength,
+		MaxBitrate: ap.MaxBitrate,
+		Security:   []string{},
+	}
+	if channel, ok := wifireg.FindChannel(ap.Frequency); ok {
+		a.Band = channel.Band
+		a.Channel = channel.Number
+	}
+	if ap.RSN.IsNone() {
+		a.Security = append(a.Security, "none")
+	}
+	if ap.RSN.SupportsPSK() {
+		a.Security = append(a.Security, "WPA2 PSK")
+	}
+	if ap.RSN.SupportsSAE() {
+		a.Security = append(a.Security, "WPA3 SAE")
+	}
+	if ap.RSN.SupportsOWE() {
+		a.Security = append(a.Security, "WPA3 OWE")
+	}
+	if ap.RSN.SupportsEAPSuiteB192() {
+		a.Security = append(a.Security, "WPA3 Enterprise 192-bit")
+	}
+	return a
+}
+
+// overlap estimates how much of the access point's signal interferes with the channel, from 0 (no
+// interference) to 1 (the access point uses the channel).
+func (a AccessPoint) overlap(channel wifireg.Channel) float64 {
+	if a.Band != channel.Band || a.Channel == 0 {
+		return 0
+	}
+	const defaultBandwidth = 20 // MHz
+	bandwidth := cmp.Or(a.Bandwidth, defaultBandwidth)
+	switch channel.Band {
+	default:
+		return 0
+	case "bg":
+		// 2.4 GHz channels are 5 MHz apart, so each 20 MHz-wide signal also interferes with the four
+		// adjacent channels on either side, though less on channels which are further away
+		const channelSpacing = 5 // MHz
+		spread := float64(bandwidth/channelSpacing + 1)
+		distance := float64(max(a.Channel, channel.Number) - min(a.Channel, channel.Number))
+		return max(0, 1-distance/spread)
+	case "a":
+		// 5 GHz channels don't overlap, but wider signals occupy blocks of adjacent channels
+		const channelWidth = 20 // MHz
+		width := max(bandwidth/channelWidth, 1)
+		if block5GHz(a.Channel, width) == block5GHz(channel.Number, width) {
+			return 1
+		}
+		return 0
+	}
+}
+
+// block5GHz identifies the block of adjacent 5 GHz channels (with the specified number of 20 MHz
+// channels per block) which contains the channel.
+func block5GHz(number, width uint32) uint32 {
+	const channelStep = 4 // 5 GHz channel numbers are 4 apart
+	// Channels 149 and above aren't aligned with the lower channels
+	const upperStart, upperOffset = 149, 1000
+	if number >= upperStart {
+		return upperOffset + (number-upperStart)/channelStep/width
+	}
+	const lowerStart = 36
+	return (number - lowerStart) / channelStep / width
+}
+
+// ChannelUsage describes how much other Wi-Fi networks use a channel.
+type ChannelUsage struct {
+	wifireg.Channel
+	// AccessPoints is the number of access points whose primary channel is the channel
+	AccessPoints int `json:"accessPoints"`
+	// OverlappingAccessPoints is the number of access points on other channels which interfere with
+	// the channel
+	OverlappingAccessPoints int `json:"overlappingAccessPoints"`
+	// MaxStrength is the strongest signal among the access points on the channel
+	MaxStrength uint8 `json:"maxStrengthPercent"`
+	// Congestion estimates how much the channel is used, by adding up the signal strengths (from 0
+	// to 1) of access points, weighted by how much they interfere with the channel
+	Congestion float64 `json:"congestion"`
+}
+
+// Survey is an analysis of the Wi-Fi networks found by a scan.
+type Survey struct {
+	Interface string `json:"interface"`
+	// Time is when the survey was made from the latest scan results
+	Time         time.Time      `json:"time"`
+	AccessPoints []AccessPoint  `json:"accessPoints"`
+	Channels     []ChannelUsage `json:"channels"`
+	// Recommendations are the least congested channels in each band, among the candidate channels
+	Recommendations []ChannelUsage `json:"recommendations"`
+}
+
+// nonOverlapping24GHz are the 2.4 GHz channels which don't overlap with each other. Channels
+// between them should be avoided, since they would suffer interference from networks on two
+// channels.
+var nonOverlapping24GHz = []uint32{1, 6, 11}
+
+// New analyzes the access points found by a scan on the network interface, and recommends the
+// least congested channels among the candidates.
+func New(
+	iface string, scanned time.Time, networks map[string][]nm.AccessPoint,
+	candidates []wifireg.Channel,
+) (s Survey) {
+	s.Interface = iface
+	s.Time = scanned
+	s.AccessPoints = []AccessPoint{}
+	for _, aps := range networks {
+		for _, ap := range aps {
+			s.AccessPoints = append(s.AccessPoints, newAccessPoint(ap))
+		}
+	}
+	slices.SortFunc(s.AccessPoints, func(a, b AccessPoint) int {
+		return cmp.Or(
+			cmp.Compare(a.Frequency, b.Frequency),
+			cmp.Compare(b.Strength, a.Strength),
+			strings.Compare(a.SSID, b.SSID),
+			strings.Compare(a.BSSID, b.BSSID),
+		)
+	})
+
+	s.Channels = make([]ChannelUsage, 0, len(wifireg.Channels))
+	for _, channel := range wifireg.Channels {
+		usage := ChannelUsage{Channel: channel}
+		for _, ap := range s.AccessPoints {
+			overlap := ap.overlap(channel)
+			if overlap == 0 {
+				continue
+			}
+			if ap.Channel == channel.Number {
+				usage.AccessPoints++
+				usage.MaxStrength = max(usage.MaxStrength, ap.Strength)
+			} else {
+				usage.OverlappingAccessPoints++
+			}
+			const maxStrength = 100 // %
+			usage.Congestion += overlap * float64(ap.Strength) / maxStrength
+		}
+		s.Channels = append(s.Channels, usage)
+	}
+
+	s.Recommendations = []ChannelUsage{}
+	for _, band := range []string{"bg", "a"} {
+		if recommendation, ok := s.recommend(band, candidates); ok {
+			s.Recommendations = append(s.Recommendations, recommendation)
+		}
+	}
+	return s
+}
+
+func (s Survey) recommend(band string, candidates []wifireg.Channel) (ChannelUsage, bool) {
+	var best ChannelUsage
+	found := false
+	for _, usage := range s.Channels {
+		if usage.Band != band || !slices.Contains(candidates, usage.Channel) {
+			continue
+		}
+		if band == "bg" && !slices.Contains(nonOverlapping24GHz, usage.Number) {
+			continue
+		}
+		if !found || usage.Congestion < best.Congestion {
+			best = usage
+			found = true
+		}
+	}
+	return best, found
+}
+
+// BandChannels returns the usage of the channels in the band ("bg" for 2.4 GHz or "a" for 5 GHz).
+func (s Survey) BandChannels(band string) []ChannelUsage {
+	var channels []ChannelUsage
+	for _, usage := range s.Channels {
+		if usage.Band == band {
+			channels = append(channels, usage)
+		}
+	}
+	return channels
+}
+
+// MaxCongestion returns the congestion of the most congested channel, or 1 if no channel is more
+// congested than that, for scaling charts of congestion.
+func (s Survey) MaxCongestion() float64 {
+	congestion := 1.0
+	for _, usage := range s.Channels {
+		congestion = max(congestion, usage.Congestion)
+	}
+	return congestion
+}
+
+// IsRecommended checks whether the channel is one of the recommended channels.
+func (s Survey) IsRecommended(channel wifireg.Channel) bool {
+	return slices.ContainsFunc(s.Recommendations, func(r ChannelUsage) bool {
+		return r.Channel == channel
+	})
+}
@@ -0,0 +1,312 @@
+// Package terminal provides pseudo-terminal shell sessions running as an unprivileged user, for
+// remote support
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest"
+
+	"github.com/openUC2/machine-admin/internal/clients/audit"
+)
+
+var (
+	ErrDisabled       = errors.New("terminal sessions are disabled")
+	ErrUnknownSession = errors.New("unknown terminal session")
+)
+
+type Client struct {
+	Config Config
+
+	ac *audit.Client
+
+	mu           sync.Mutex
+	enabledUntil time.Time
+	disableTimer *time.Timer
+	sessions     map[uuid.UUID]*session
+
+	l godest.Logger
+}
+
+func NewClient(c Config, ac *audit.Client, l godest.Logger) *Client {
+	return &Client{
+		Config:   c,
+		ac:       ac,
+		sessions: make(map[uuid.UUID]*session),
+		l:        l,
+	}
+}
+
+// Enablement
+
+// EnabledUntil returns the time when terminal sessions will be automatically disabled, or the zero
+// time if terminal sessions are disabled.
+func (c *Client) EnabledUntil() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().After(c.enabledUntil) {
+		return time.Time{}
+	}
+	return c.enabledUntil
+}
+
+// Enable allows terminal sessions to be opened for the specified duration (capped at the configured
+// maximum duration), after which all terminal sessions will be closed.
+func (c *Client) Enable(duration time.Duration) (enabledUntil time.Time) {
+	duration = min(duration, c.Config.MaxEnabledDuration)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.enabledUntil = time.Now().Add(duration)
+	if c.disableTimer != nil {
+		c.disableTimer.Stop()
+	}
+	c.disableTimer = time.AfterFunc(duration, c.Disable)
+	c.ac.RecordOrLog(audit.Event{
+		Kind:    "terminal-enabled",
+		Message: fmt.Sprintf("terminal sessions enabled until %s", c.enabledUntil.Format(time.RFC3339)),
+	})
+	return c.enabledUntil
+}
+
+// Disable prevents new terminal sessions from being opened, and closes all open terminal sessions.
+func (c *Client) Disable() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.enabledUntil = time.Time{}
+	if c.disableTimer != nil {
+		c.disableTimer.Stop()
+		c.disableTimer = nil
+	}
+	for id, s := range c.sessions {
+		s.close()
+		delete(c.sessions, id)
+		c.ac.RecordOrLog(audit.Event{
+			Kind:    "terminal-session-closed",
+			Subject: id.String(),
+		})
+	}
+	c.ac.RecordOrLog(audit.Event{
+		Kind:    "terminal-disabled",
+		Message: "terminal sessions disabled",
+	})
+}
+
+// Sessions
+
+type session struct {
+	id   uuid.UUID
+	cmd  *exec.Cmd
+	pty  *os.File
+	once sync.Once
+}
+
+func (s *session) close() {
+	s.once.Do(func() {
+		_ = s.pty.Close()
+		if s.cmd.Process != nil {
+			// The shell is the leader of a new session, so we kill its whole process group in order to
+			// also kill any processes it started:
+			_ = syscall.Kill(-s.cmd.Process.Pid, syscall.SIGKILL)
+		}
+		_ = s.cmd.Wait()
+	})
+}
+
+func (c *Client) lookupCredential() (*syscall.Credential, string, error) {
+	u, err := user.Lookup(c.Config.User)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "couldn't look up user %s", c.Config.User)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "couldn't parse uid %s of user %s", u.Uid, u.Username)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "couldn't parse gid %s of user %s", u.Gid, u.Username)
+	}
+	if uid == 0 || gid == 0 {
+		return nil, "", errors.Errorf("refusing to run terminal sessions as privileged user %s", u.Username)
+	}
+	if err = checkUnprivilegedGroups(u); err != nil {
+		return nil, "", err
+	}
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, u.HomeDir, nil
+}
+
+// privilegedGroups are the groups whose members can gain root privileges (e.g. through sudo) or
+// read the machine's logs, so terminal sessions must not run as their members.
+var privilegedGroups = []string{"root", "sudo", "wheel", "adm", "admin"}
+
+// checkUnprivilegedGroups returns an error if the user is a member of any privileged group.
+func checkUnprivilegedGroups(u *user.User) error {
+	gids, err := u.GroupIds()
+	if err != nil {
+		return errors.Wrapf(err, "couldn't look up groups of user %s", u.Username)
+	}
+	for _, gid := range gids {
+		group, err := user.LookupGroupId(gid)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't look up group %s of user %s", gid, u.Username)
+		}
+		if slices.Contains(privilegedGroups, group.Name) {
+			return errors.Errorf(
+				"refusing to run terminal sessions as user %s, who is in the privileged group %s",
+				u.Username, group.Name,
+			)
+		}
+	}
+	return nil
+}
+
+// OpenSession starts a new shell in a pseudo-terminal with the specified size.
+func (c *Client) OpenSession(cols, rows uint16) (id uuid.UUID, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().After(c.enabledUntil) {
+		return uuid.UUID{}, ErrDisabled
+	}
+
+	cred, home, err := c.lookupCredential()
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	cmd := exec.Command(c.Config.Shell, "-l")
+	cmd.Dir = home
+	if _, err = os.Stat(home); err != nil {
+		// Some unprivileged users (e.g. nobody) don't have a home directory
+		cmd.Dir = "/"
+	}
+	cmd.Env = []string{
+		"HOME=" + home,
+		"USER=" + c.Config.User,
+		"LOGNAME=" + c.Config.User,
+		"SHELL=" + c.Config.Shell,
+		"TERM=xterm-256color",
+		"PATH=/usr/local/bin:/usr/bin:/bin",
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: cred}
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: cols, Rows: rows})
+	if err != nil {
+		return uuid.UUID{}, errors.Wrapf(err, "couldn't start %s as %s", c.Config.Shell, c.Config.User)
+	}
+
+	s := &session{
+		id:  uuid.New(),
+		cmd: cmd,
+		pty: f,
+	}
+	c.sessions[s.id] = s
+	c.ac.RecordOrLog(audit.Event{
+		Kind:    "terminal-session-opened",
+		Subject: s.id.String(),
+		Message: fmt.Sprintf("started %s as %s", c.Config.Shell, c.Config.User),
+	})
+	return s.id, nil
+}
+
+func (c *Client) getSession(id uuid.UUID) (*session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.sessions[id]
+	if !ok {
+		return nil, errors.Wrapf(ErrUnknownSession, "no session %s", id)
+	}
+	return s, nil
+}
+
+// ReadSession passes output from the terminal session to the handler until the session is closed
+// or the context is canceled. All output is recorded in the audit trail.
+func (c *Client) ReadSession(ctx context.Context, id uuid.UUID, handle func([]byte) error) error {
+	s, err := c.getSession(id)
+	if err != nil {
+		return err
+	}
+
+	const bufSize = 4096
+	buf := make([]byte, bufSize)
+	for ctx.Err() == nil {
+		n, err := s.pty.Read(buf)
+		if n > 0 {
+			c.ac.RecordOrLog(audit.Event{
+				Kind:    "terminal-output",
+				Subject: id.String(),
+				Data:    string(buf[:n]),
+			})
+			if herr := handle(buf[:n]); herr != nil {
+				return herr
+			}
+		}
+		if err != nil {
+			// The pseudo-terminal returns an error once the shell exits or the session is closed, which
+			// is the normal way for a session to end
+			return c.CloseSession(id)
+		}
+	}
+	return nil
+}
+
+// WriteSession sends input to the terminal session. All input is recorded in the audit trail.
+func (c *Client) WriteSession(id uuid.UUID, data []byte) error {
+	s, err := c.getSession(id)
+	if err != nil {
+		return err
+	}
+	c.ac.RecordOrLog(audit.Event{
+		Kind:    "terminal-input",
+		Subject: id.String(),
+		Data:    string(data),
+	})
+	if _, err = s.pty.Write(data); err != nil {
+		return errors.Wrapf(err, "couldn't write to session %s", id)
+	}
+	return nil
+}
+
+// ResizeSession changes the size of the terminal session's pseudo-terminal.
+func (c *Client) ResizeSession(id uuid.UUID, cols, rows uint16) error {
+	s, err := c.getSession(id)
+	if err != nil {
+		return err
+	}
+	if err = pty.Setsize(s.pty, &pty.Winsize{Cols: cols, Rows: rows}); err != nil {
+		return errors.Wrapf(err, "couldn't resize session %s", id)
+	}
+	return nil
+}
+
+// CloseSession terminates the terminal session. Closing an already-closed session is a no-op.
+func (c *Client) CloseSession(id uuid.UUID) error {
+	c.mu.Lock()
+	s, ok := c.sessions[id]
+	delete(c.sessions, id)
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	s.close()
+	c.ac.RecordOrLog(audit.Event{
+		Kind:    "terminal-session-closed",
+		Subject: id.String(),
+	})
+	return nil
+}
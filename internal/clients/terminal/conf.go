@@ -0,0 +1,42 @@
+package terminal
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sargassum-world/godest/env"
+)
+
+const envPrefix = "TERMINAL_"
+
+type Config struct {
+	// User is the name of the unprivileged user account which terminal sessions run as. It must
+	// not be root or a member of an administrative group (see privilegedGroups).
+	User string
+	// Shell is the path of the program to run in terminal sessions.
+	Shell string
+	// MaxEnabledDuration is the longest amount of time for which terminal sessions can be enabled
+	// before they are automatically disabled.
+	MaxEnabledDuration time.Duration
+}
+
+func GetConfig() (c Config, err error) {
+	// Note: we don't default to the pi user, since Raspberry Pi OS lets it use sudo without a
+	// password; instead, the OS should provide a dedicated account for support sessions
+	const defaultUser = "machine-admin-support"
+	c.User = env.GetString(envPrefix+"USER", defaultUser)
+
+	const defaultShell = "/bin/bash"
+	c.Shell = env.GetString(envPrefix+"SHELL", defaultShell)
+
+	const defaultMaxEnabledDuration = 60 * 60 // sec
+	rawMaxEnabledDuration, err := env.GetInt64(
+		envPrefix+"MAX_ENABLED_DURATION", defaultMaxEnabledDuration,
+	)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "couldn't make max enabled duration config")
+	}
+	c.MaxEnabledDuration = time.Duration(rawMaxEnabledDuration) * time.Second
+
+	return c, nil
+}
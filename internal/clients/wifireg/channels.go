@@ -0,0 +1,92 @@
+package wifireg
+
+import (
+	"fmt"
+)
+
+// Channel is a 20 MHz-wide Wi-Fi channel.
+type Channel struct {
+	Number uint32 `json:"number"`
+	// Band is the band of the channel, as named in NetworkManager's settings ("bg" for 2.4 GHz or
+	// "a" for 5 GHz).
+	Band      string `json:"band"`
+	Frequency uint32 `json:"frequencyMHz"` // at the center of the channel
+}
+
+const channelWidth = 20 // MHz
+
+// Channels lists the Wi-Fi channels which the hotspot could use, in order of frequency. Channel 14
+// isn't included, since it only allows the obsolete 802.11b standard.
+var Channels = func() (channels []Channel) {
+	const (
+		firstChannel24GHz = 1
+		lastChannel24GHz  = 13
+		baseFreq24GHz     = 2407 // MHz
+		baseFreq5GHz      = 5000 // MHz
+		channelSpacing    = 5    // MHz
+	)
+	for number := uint32(firstChannel24GHz); number <= lastChannel24GHz; number++ {
+		channels = append(channels, Channel{
+			Number: number, Band: "bg", Frequency: baseFreq24GHz + channelSpacing*number,
+		})
+	}
+	for _, number := range []uint32{
+		36, 40, 44, 48, 52, 56, 60, 64, 100, 104, 108, 112, 116, 120, 124, 128, 132, 136, 140, 144,
+		149, 153, 157, 161, 165,
+	} {
+		channels = append(channels, Channel{
+			Number: number, Band: "a", Frequency: baseFreq5GHz + channelSpacing*number,
+		})
+	}
+	return channels
+}()
+
+// FindChannel returns the channel whose center is at the frequency (in MHz), if it's one of the
+// Channels.
+func FindChannel(frequency uint32) (Channel, bool) {
+	for _, channel := range Channels {
+		if channel.Frequency == frequency {
+			return channel, true
+		}
+	}
+	return Channel{}, false
+}
+
+// APRestriction explains why the regulatory domain doesn't allow the machine to make a Wi-Fi
+// access point on the channel, or returns an empty string if it's allowed.
+func (d Domain) APRestriction(channel Channel) string {
+	start := channel.Frequency - channelWidth/2
+	end := channel.Frequency + channelWidth/2
+	for _, rule := range d.Rules {
+		if start < rule.StartFreq || end > rule.EndFreq {
+			continue
+		}
+		switch {
+		case rule.MaxBandwidth < channelWidth:
+			return fmt.Sprintf("channels may only be %d MHz wide", rule.MaxBandwidth)
+		case rule.Flags.NoIR():
+			return "access points may not be started on this channel"
+		case rule.Flags.DFS():
+			return "this channel requires radar detection, which the hotspot doesn't support"
+		default:
+			return ""
+		}
+	}
+	return "this channel may not be used"
+}
+
+// APChannels returns the channels on which the regulatory domain allows the machine to make a
+// Wi-Fi access point, for a Wi-Fi device which supports the specified bands.
+func (d Domain) APChannels(supports24GHz, supports5GHz bool) []Channel {
+	var channels []Channel
+	for _, channel := range Channels {
+		if (channel.Band == "bg" && !supports24GHz) || (channel.Band == "a" && !supports5GHz) {
+			continue
+		}
+		if d.APRestriction(channel) != "" {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+	return channels
+}
@@ -0,0 +1,89 @@
+package wifireg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// cmdlinePaths are the possible locations of the Raspberry Pi's kernel command line file, in order
+// of preference (newer OS releases mount the boot partition at /boot/firmware).
+var cmdlinePaths = []string{"/boot/firmware/cmdline.txt", "/boot/cmdline.txt"}
+
+// cmdlineParam is the kernel command line parameter which sets the regulatory domain on boot, as
+// also set by raspi-config.
+const cmdlineParam = "cfg80211.ieee80211_regdom"
+
+func findCmdline() (string, error) {
+	for _, path := range cmdlinePaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.Errorf("couldn't find kernel command line file in %v", cmdlinePaths)
+}
+
+// GetPersistedCountry returns the country which will be set on boot, or an empty string if no
+// country will be set.
+func GetPersistedCountry() (string, error) {
+	path, err := findCmdline()
+	if err != nil {
+		return "", err
+	}
+	cmdline, err := os.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't read kernel command line file %s", path)
+	}
+	for field := range strings.FieldsSeq(string(cmdline)) {
+		if country, ok := strings.CutPrefix(field, cmdlineParam+"="); ok {
+			return country, nil
+		}
+	}
+	return "", nil
+}
+
+// PersistCountry sets the country which will be set on boot. This requires root privileges.
+func PersistCountry(country string) error {
+	if !ValidCountry(country) {
+		return errors.Errorf("invalid country code %s", country)
+	}
+	path, err := findCmdline()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't check kernel command line file %s", path)
+	}
+	cmdline, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "couldn't read kernel command line file %s", path)
+	}
+
+	// Note: the kernel command line must stay on a single line
+	param := cmdlineParam + "=" + country
+	fields := strings.Fields(string(cmdline))
+	replaced := false
+	for i, field := range fields {
+		if strings.HasPrefix(field, cmdlineParam+"=") {
+			fields[i] = param
+			replaced = true
+		}
+	}
+	if !replaced {
+		fields = append(fields, param)
+	}
+
+	swapPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".swp")
+	if err = os.WriteFile(
+		swapPath, []byte(strings.Join(fields, " ")+"\n"), info.Mode().Perm(),
+	); err != nil {
+		return errors.Wrapf(err, "couldn't write kernel command line to swap file %s", swapPath)
+	}
+	if err = os.Rename(swapPath, path); err != nil {
+		return errors.Wrapf(err, "couldn't move swap file %s to %s", swapPath, path)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Package wifireg queries and configures the Wi-Fi regulatory domain (i.e. the country whose rules
+// determine which Wi-Fi channels may be used, and how)
+package wifireg
+
+import (
+	"regexp"
+
+	"github.com/mdlayher/genetlink"
+	"github.com/mdlayher/netlink"
+	"github.com/pkg/errors"
+)
+
+// Constants from linux/nl80211.h
+const (
+	nl80211Family = "nl80211"
+
+	nl80211CmdGetReg    = 31
+	nl80211CmdReqSetReg = 27
+
+	nl80211AttrRegAlpha2 = 33
+	nl80211AttrRegRules  = 34
+
+	nl80211AttrRegRuleFlags     = 1
+	nl80211AttrFreqRangeStart   = 2 // u32, kHz
+	nl80211AttrFreqRangeEnd     = 3 // u32, kHz
+	nl80211AttrFreqRangeMaxBW   = 4 // u32, kHz
+	nl80211AttrPowerRuleMaxEIRP = 6 // u32, mBm
+)
+
+// WorldCountry is the country code of the world regulatory domain, which the kernel uses when no
+// country has been set. It only allows channels which may be used everywhere.
+const WorldCountry = "00"
+
+var countryPattern = regexp.MustCompile(`^([A-Z]{2}|00)$`)
+
+// ValidCountry reports whether the code is an ISO 3166-1 alpha-2 country code (or the world
+// regulatory domain's code), as accepted by the kernel.
+func ValidCountry(code string) bool {
+	return countryPattern.MatchString(code)
+}
+
+type Domain struct {
+	Country string
+	Rules   []Rule
+}
+
+// Rule is a frequency range in which Wi-Fi may be used, subject to the rule's flags.
+type Rule struct {
+	StartFreq    uint32 // MHz
+	EndFreq      uint32 // MHz
+	MaxBandwidth uint32 // MHz
+	MaxEIRP      uint32 // mBm
+	Flags        RuleFlags
+}
+
+type RuleFlags uint32
+
+func (f RuleFlags) NoOFDM() bool {
+	return f&0x1 > 0
+}
+
+func (f RuleFlags) NoIndoor() bool {
+	return f&0x4 > 0
+}
+
+func (f RuleFlags) NoOutdoor() bool {
+	return f&0x8 > 0
+}
+
+// DFS reports whether radar detection is required, which the hotspot doesn't support.
+func (f RuleFlags) DFS() bool {
+	return f&0x10 > 0
+}
+
+// NoIR reports whether the machine is forbidden from initiating radiation (e.g. as an access
+// point), so that it may only join networks which were started by other devices.
+func (f RuleFlags) NoIR() bool {
+	return f&0x80 > 0
+}
+
+// GetDomain asks the kernel for the current global regulatory domain, like `iw reg get`.
+func GetDomain() (d Domain, err error) {
+	conn, family, err := dial()
+	if err != nil {
+		return d, err
+	}
+	defer func() {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "couldn't close generic netlink connection")
+		}
+	}()
+
+	messages, err := conn.Execute(genetlink.Message{
+		Header: genetlink.Header{Command: nl80211CmdGetReg, Version: family.Version},
+	}, family.ID, netlink.Request)
+	if err != nil {
+		return d, errors.Wrap(err, "couldn't request regulatory domain")
+	}
+	if len(messages) == 0 {
+		return d, errors.New("kernel didn't report a regulatory domain")
+	}
+	if d, err = parseDomain(messages[0].Data); err != nil {
+		return d, errors.Wrap(err, "couldn't parse regulatory domain")
+	}
+	return d, nil
+}
+
+func parseDomain(data []byte) (d Domain, err error) {
+	ad, err := netlink.NewAttributeDecoder(data)
+	if err != nil {
+		return d, err
+	}
+	for ad.Next() {
+		switch ad.Type() {
+		case nl80211AttrRegAlpha2:
+			d.Country = ad.String()
+		case nl80211AttrRegRules:
+			ad.Nested(func(nad *netlink.AttributeDecoder) error {
+				for nad.Next() {
+					nad.Nested(func(rad *netlink.AttributeDecoder) error {
+						d.Rules = append(d.Rules, parseRule(rad))
+						return nil
+					})
+				}
+				return nil
+			})
+		}
+	}
+	return d, ad.Err()
+}
+
+func parseRule(ad *netlink.AttributeDecoder) (r Rule) {
+	const kHzPerMHz = 1000
+	for ad.Next() {
+		switch ad.Type() {
+		case nl80211AttrRegRuleFlags:
+			r.Flags = RuleFlags(ad.Uint32())
+		case nl80211AttrFreqRangeStart:
+			r.StartFreq = ad.Uint32() / kHzPerMHz
+		case nl80211AttrFreqRangeEnd:
+			r.EndFreq = ad.Uint32() / kHzPerMHz
+		case nl80211AttrFreqRangeMaxBW:
+			r.MaxBandwidth = ad.Uint32() / kHzPerMHz
+		case nl80211AttrPowerRuleMaxEIRP:
+			r.MaxEIRP = ad.Uint32()
+		}
+	}
+	return r
+}
+
+// SetCountry asks the kernel to switch to the regulatory domain of the country, like
+// `iw reg set <country>`. This requires root privileges. The kernel applies the change
+// asynchronously, and it's lost on reboot unless it's also persisted with PersistCountry.
+func SetCountry(country string) (err error) {
+	if !ValidCountry(country) {
+		return errors.Errorf("invalid country code %s", country)
+	}
+
+	conn, family, err := dial()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "couldn't close generic netlink connection")
+		}
+	}()
+
+	ae := netlink.NewAttributeEncoder()
+	ae.String(nl80211AttrRegAlpha2, country)
+	data, err := ae.Encode()
+	if err != nil {
+		return errors.Wrap(err, "couldn't encode request attributes")
+	}
+	if _, err = conn.Execute(genetlink.Message{
+		Header: genetlink.Header{Command: nl80211CmdReqSetReg, Version: family.Version},
+		Data:   data,
+	}, family.ID, netlink.Request|netlink.Acknowledge); err != nil {
+		return errors.Wrapf(err, "couldn't request regulatory domain %s", country)
+	}
+	return nil
+}
+
+func dial() (*genetlink.Conn, genetlink.Family, error) {
+	conn, err := genetlink.Dial(nil)
+	if err != nil {
+		return nil, genetlink.Family{}, errors.Wrap(err, "couldn't open generic netlink connection")
+	}
+	family, err := conn.GetFamily(nl80211Family)
+	if err != nil {
+		_ = conn.Close()
+		return nil, genetlink.Family{}, errors.Wrapf(
+			err, "couldn't find generic netlink family %s", nl80211Family,
+		)
+	}
+	return conn, family, nil
+}
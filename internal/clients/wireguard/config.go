@@ -0,0 +1,253 @@
+// Package wireguard parses WireGuard configuration files and reports the status of WireGuard
+// network interfaces
+package wireguard
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// Config is the configuration of a WireGuard network interface, in the format of the .conf files
+// used by wg-quick.
+type Config struct {
+	PrivateKey string
+	ListenPort uint32 // zero if a random port should be used
+	FwMark     uint32
+	MTU        uint32 // zero if the MTU should be determined automatically
+	// NoPeerRoutes is true if routes to the peers' allowed IPs shouldn't be added automatically
+	NoPeerRoutes bool
+	Addresses    []netip.Prefix
+	DNS          []netip.Addr
+	DNSSearch    []string
+	Peers        []Peer
+	// Ignored lists the settings which only wg-quick understands (e.g. PostUp), and which therefore
+	// were ignored
+	Ignored []string
+}
+
+// Peer is a peer of a WireGuard network interface.
+type Peer struct {
+	PublicKey    string
+	PresharedKey string
+	// Endpoint is the host and port of the peer, or empty if the peer will connect to us
+	Endpoint            string
+	AllowedIPs          []netip.Prefix
+	PersistentKeepalive uint32 // seconds; zero if disabled
+}
+
+// wgQuickKeys are the keys in [Interface] sections which are only understood by wg-quick.
+var wgQuickKeys = []string{"preup", "postup", "predown", "postdown", "saveconfig"}
+
+// ParseConfig parses a WireGuard configuration file in the format used by wg-quick.
+func ParseConfig(r io.Reader) (c Config, err error) {
+	var section string
+	var peer *Peer
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			switch section = strings.ToLower(strings.Trim(line, "[]")); section {
+			default:
+				return c, errors.Errorf("line %d: unknown section %s", lineNum, line)
+			case "interface":
+			case "peer":
+				c.Peers = append(c.Peers, Peer{})
+				peer = &c.Peers[len(c.Peers)-1]
+			}
+			continue
+		}
+
+		rawKey, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return c, errors.Errorf("line %d: expected a key = value pair", lineNum)
+		}
+		key := strings.ToLower(strings.TrimSpace(rawKey))
+		value = strings.TrimSpace(value)
+		switch section {
+		default:
+			return c, errors.Errorf("line %d: setting %s is outside of any section", lineNum, rawKey)
+		case "interface":
+			err = c.parseInterfaceSetting(key, value)
+		case "peer":
+			err = peer.parseSetting(key, value)
+		}
+		if err != nil {
+			return c, errors.Wrapf(err, "line %d: invalid %s", lineNum, strings.TrimSpace(rawKey))
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return c, errors.Wrap(err, "couldn't read configuration")
+	}
+	return c, c.validate()
+}
+
+func (c *Config) parseInterfaceSetting(key, value string) (err error) {
+	switch key {
+	default:
+		if slices.Contains(wgQuickKeys, key) {
+			c.Ignored = append(c.Ignored, key)
+			return nil
+		}
+		return errors.New("unknown setting")
+	case "privatekey":
+		c.PrivateKey, err = parseKey(value)
+		return err
+	case "listenport":
+		c.ListenPort, err = parseUint(value, 16)
+		return err
+	case "table":
+		// Note: only "off" has an equivalent outside of wg-quick; custom routing tables aren't supported
+		switch strings.ToLower(value) {
+		default:
+			c.Ignored = append(c.Ignored, key)
+		case "auto":
+		case "off":
+			c.NoPeerRoutes = true
+		}
+		return nil
+	case "fwmark":
+		if value == "off" {
+			c.FwMark = 0
+			return nil
+		}
+		c.FwMark, err = parseUint(value, 32)
+		return err
+	case "mtu":
+		c.MTU, err = parseUint(value, 32)
+		return err
+	case "address":
+		for _, rawAddress := range splitList(value) {
+			address, err := parsePrefix(rawAddress, false)
+			if err != nil {
+				return err
+			}
+			c.Addresses = append(c.Addresses, address)
+		}
+		return nil
+	case "dns":
+		// Note: wg-quick treats values which aren't IP addresses as DNS search domains
+		for _, rawServer := range splitList(value) {
+			if server, err := netip.ParseAddr(rawServer); err == nil {
+				c.DNS = append(c.DNS, server)
+				continue
+			}
+			c.DNSSearch = append(c.DNSSearch, rawServer)
+		}
+		return nil
+	}
+}
+
+func (p *Peer) parseSetting(key, value string) (err error) {
+	switch key {
+	default:
+		return errors.New("unknown setting")
+	case "publickey":
+		p.PublicKey, err = parseKey(value)
+		return err
+	case "presharedkey":
+		p.PresharedKey, err = parseKey(value)
+		return err
+	case "endpoint":
+		host, rawPort, err := net.SplitHostPort(value)
+		if err != nil {
+			return err
+		}
+		if host == "" {
+			return errors.New("the endpoint has no host")
+		}
+		if _, err = parseUint(rawPort, 16); err != nil {
+			return errors.Wrap(err, "invalid port")
+		}
+		p.Endpoint = value
+		return nil
+	case "allowedips":
+		for _, rawPrefix := range splitList(value) {
+			prefix, err := parsePrefix(rawPrefix, true)
+			if err != nil {
+				return err
+			}
+			p.AllowedIPs = append(p.AllowedIPs, prefix)
+		}
+		return nil
+	case "persistentkeepalive":
+		if value == "off" {
+			p.PersistentKeepalive = 0
+			return nil
+		}
+		p.PersistentKeepalive, err = parseUint(value, 16)
+		return err
+	}
+}
+
+func (c Config) validate() error {
+	if c.PrivateKey == "" {
+		return errors.New("the [Interface] section has no PrivateKey")
+	}
+	if len(c.Peers) == 0 {
+		return errors.New("the configuration has no [Peer] sections")
+	}
+	for i, peer := range c.Peers {
+		if peer.PublicKey == "" {
+			return errors.Errorf("[Peer] section %d has no PublicKey", i+1)
+		}
+	}
+	return nil
+}
+
+func parseKey(raw string) (string, error) {
+	key, err := wgtypes.ParseKey(raw)
+	if err != nil {
+		return "", err
+	}
+	return key.String(), nil
+}
+
+func parseUint(raw string, bitSize int) (uint32, error) {
+	parsed, err := strconv.ParseUint(raw, 10, bitSize)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(parsed), nil //nolint:gosec // callers limit bitSize to at most 32
+}
+
+func splitList(raw string) []string {
+	var values []string
+	for value := range strings.SplitSeq(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parsePrefix parses an IP address with a prefix length, e.g. 10.0.0.2/24; an address without a
+// prefix length is treated as a single host. If masked is true, the prefix is also masked, as
+// WireGuard requires for allowed IPs.
+func parsePrefix(raw string, masked bool) (netip.Prefix, error) {
+	if !strings.Contains(raw, "/") {
+		addr, err := netip.ParseAddr(raw)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(raw)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if masked {
+		return prefix.Masked(), nil
+	}
+	return prefix, nil
+}
@@ -0,0 +1,72 @@
+package wireguard
+
+import (
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.zx2c4.com/wireguard/wgctrl"
+)
+
+// PeerStatus describes the tunnel to a peer of a WireGuard network interface.
+type PeerStatus struct {
+	PublicKey string
+	// Endpoint is empty if the peer's address isn't known yet
+	Endpoint   string
+	AllowedIPs []netip.Prefix
+	// LastHandshake is the zero value if no handshake has happened yet
+	LastHandshake    time.Time
+	ReceivedBytes    int64
+	TransmittedBytes int64
+}
+
+// GetPeerStatuses reports the status of the tunnels to the peers of the WireGuard network
+// interface. This requires the CAP_NET_ADMIN capability.
+func GetPeerStatuses(iface string) (statuses []PeerStatus, err error) {
+	client, err := wgctrl.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open WireGuard control client")
+	}
+	defer func() {
+		if cerr := client.Close(); cerr != nil && err == nil {
+			err = errors.Wrap(cerr, "couldn't close WireGuard control client")
+		}
+	}()
+
+	device, err := client.Device(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't get WireGuard device %s", iface)
+	}
+	statuses = make([]PeerStatus, 0, len(device.Peers))
+	for _, peer := range device.Peers {
+		status := PeerStatus{
+			PublicKey:        peer.PublicKey.String(),
+			ReceivedBytes:    peer.ReceiveBytes,
+			TransmittedBytes: peer.TransmitBytes,
+		}
+		if peer.Endpoint != nil {
+			status.Endpoint = peer.Endpoint.String()
+		}
+		for _, allowedIP := range peer.AllowedIPs {
+			if prefix, ok := prefixFromIPNet(allowedIP); ok {
+				status.AllowedIPs = append(status.AllowedIPs, prefix)
+			}
+		}
+		// Note: the kernel reports the Unix epoch if no handshake has happened yet
+		if peer.LastHandshakeTime.Unix() > 0 {
+			status.LastHandshake = peer.LastHandshakeTime
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func prefixFromIPNet(ipNet net.IPNet) (netip.Prefix, bool) {
+	addr, ok := netip.AddrFromSlice(ipNet.IP)
+	if !ok {
+		return netip.Prefix{}, false
+	}
+	bits, _ := ipNet.Mask.Size()
+	return netip.PrefixFrom(addr.Unmap(), bits), true
+}
@@ -49,6 +49,30 @@ var serverCmd = &cli.Command{
 			Sources: cli.EnvVars("SHUTDOWNTIMEOUT"),
 		},
 
+		// Roles
+		&cli.StringFlag{
+			Name:    "roles-hotspot-iface",
+			Usage:   "network interface for Wi-Fi hotspot (detected if empty)",
+			Sources: cli.EnvVars("ROLES_HOTSPOT_IFACE"),
+		},
+		&cli.StringFlag{
+			Name:    "roles-uplink-iface",
+			Usage:   "network interface for external Wi-Fi networks (detected if empty)",
+			Sources: cli.EnvVars("ROLES_UPLINK_IFACE"),
+		},
+		&cli.StringFlag{
+			Name:    "roles-hotspot-conn-profile",
+			Value:   "wlan0-hotspot",
+			Usage:   "ID of connection profile for Wi-Fi hotspot",
+			Sources: cli.EnvVars("ROLES_HOTSPOT_CONN_PROFILE"),
+		},
+		&cli.StringFlag{
+			Name:    "roles-internet-conn-profile",
+			Value:   "wlan1-internet",
+			Usage:   "ID of built-in connection profile for external Wi-Fi networks",
+			Sources: cli.EnvVars("ROLES_INTERNET_CONN_PROFILE"),
+		},
+
 		// Sidecar
 		&cli.StringFlag{
 			Name:    "sidecar-address",
@@ -70,6 +94,10 @@ func serverMain(ctx context.Context, cmd *cli.Command) error {
 	config.HTTP.Port = cmd.Int("http-port")
 	config.HTTP.BasePath = cmd.String("http-base-path")
 	config.HTTP.GzipLevel = cmd.Int("http-gzip-level")
+	config.Roles.HotspotIface = cmd.String("roles-hotspot-iface")
+	config.Roles.UplinkIface = cmd.String("roles-uplink-iface")
+	config.Roles.HotspotConnProfileID = cmd.String("roles-hotspot-conn-profile")
+	config.Roles.InternetConnProfileID = cmd.String("roles-internet-conn-profile")
 	config.Sidecar.Address = cmd.String("sidecar-address")
 
 	// Prepare server
@@ -23,6 +23,12 @@ var sidecarCmd = &cli.Command{
 			Usage:   "address of varlink service",
 			Sources: cli.EnvVars("SIDECAR_ADDRESS"),
 		},
+		&cli.StringFlag{
+			Name:    "roles-hotspot-conn-profile",
+			Value:   "wlan0-hotspot",
+			Usage:   "ID of connection profile for Wi-Fi hotspot, whose password may be shared",
+			Sources: cli.EnvVars("ROLES_HOTSPOT_CONN_PROFILE"),
+		},
 	},
 }
 
@@ -39,6 +45,7 @@ func sidecarMain(ctx context.Context, cmd *cli.Command) error {
 	// Prepare sidecar
 	config.Version = toolVersion
 	config.Address = cmd.String("address")
+	config.Routes.HotspotConnProfileID = cmd.String("roles-hotspot-conn-profile")
 	s, err := sidecar.New(config, e.Logger)
 	if err != nil {
 		return err
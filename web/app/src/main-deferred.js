@@ -9,6 +9,7 @@ import {
   NavigationLinkController,
   NavigationMenuController,
   PasswordInputController,
+  TerminalController,
   ThemeController,
   Turbo,
   TurboCableStreamSourceElement,
@@ -35,6 +36,7 @@ Stimulus.register('hideable', HideableController);
 Stimulus.register('navigation-link', NavigationLinkController);
 Stimulus.register('navigation-menu', NavigationMenuController);
 Stimulus.register('password-input', PasswordInputController);
+Stimulus.register('terminal', TerminalController);
 Stimulus.register('theme', ThemeController);
 Stimulus.register('turbo-cache', TurboCacheController);
 Stimulus.register('showable', ShowableController);
@@ -7,6 +7,7 @@ export { default as HideableController } from './hideable.controller';
 export { default as NavigationLinkController } from './navigation-link.controller';
 export { default as NavigationMenuController } from './navigation-menu.controller';
 export { default as PasswordInputController } from './password-input.controller';
+export { default as TerminalController } from './terminal.controller';
 export { default as ThemeController } from './theme.controller';
 export { Turbo, streamActionReload } from './turbo';
 export { default as TurboCableStreamSourceElement } from './turbo-cable-stream-source.element';
@@ -0,0 +1,140 @@
+import { Controller } from '@hotwired/stimulus';
+import { getActionCableConsumer, makeWebSocketURL } from './action-cable';
+
+// Terminal input must be split into messages smaller than the server's websocket message size
+// limit (wsMaxMessageSize in cable.go), which is sized so that a chunk of this many UTF-16 code
+// units always fits after it's escaped and wrapped in an Action Cable message.
+const maxInputLength = 1024;
+// We only keep a limited amount of scrollback, to limit memory usage.
+const maxOutputLength = 200000;
+
+const keySequences = {
+  Enter: '\r',
+  Backspace: '\x7f',
+  Tab: '\t',
+  Escape: '\x1b',
+  ArrowUp: '\x1b[A',
+  ArrowDown: '\x1b[B',
+  ArrowRight: '\x1b[C',
+  ArrowLeft: '\x1b[D',
+  Home: '\x1b[H',
+  End: '\x1b[F',
+  Delete: '\x1b[3~',
+  PageUp: '\x1b[5~',
+  PageDown: '\x1b[6~',
+};
+
+// This matches CSI and OSC escape sequences, as well as other two-character escape sequences.
+// eslint-disable-next-line no-control-regex
+const escapeSequences = /\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])/g;
+
+export default class extends Controller {
+  static targets = ['output'];
+  static values = {
+    cableRoute: String,
+    name: String,
+    integrity: String,
+  };
+
+  connect() {
+    this.decoder = new TextDecoder();
+    this.text = '';
+    const { cols, rows } = this.size();
+    const consumer = getActionCableConsumer(
+      makeWebSocketURL(this.cableRouteValue),
+    );
+    this.subscription = consumer.subscriptions.create(
+      {
+        channel: 'TerminalChannel',
+        name: this.nameValue,
+        integrity: this.integrityValue,
+        cols,
+        rows,
+      },
+      {
+        received: this.receive.bind(this),
+      },
+    );
+    this.resizeListener = this.resize.bind(this);
+    window.addEventListener('resize', this.resizeListener);
+    this.element.focus();
+  }
+
+  disconnect() {
+    window.removeEventListener('resize', this.resizeListener);
+    if (this.subscription) this.subscription.unsubscribe();
+  }
+
+  size() {
+    const style = window.getComputedStyle(this.outputTarget);
+    const charWidth = parseFloat(style.fontSize) * 0.6;
+    const lineHeight = parseFloat(style.lineHeight) || parseFloat(style.fontSize) * 1.2;
+    return {
+      cols: Math.max(20, Math.floor(this.outputTarget.clientWidth / charWidth)),
+      rows: Math.max(5, Math.floor(this.outputTarget.clientHeight / lineHeight)),
+    };
+  }
+
+  resize() {
+    const { cols, rows } = this.size();
+    this.subscription.perform('resize', { cols, rows });
+  }
+
+  receive(data) {
+    const bytes = Uint8Array.from(atob(data), (c) => c.charCodeAt(0));
+    this.write(this.decoder.decode(bytes, { stream: true }));
+  }
+
+  write(output) {
+    let text = this.text;
+    for (const c of output.replace(escapeSequences, '').replace(/\r\n/g, '\n')) {
+      switch (c) {
+        default:
+          text += c;
+          break;
+        case '\r':
+          text = text.slice(0, text.lastIndexOf('\n') + 1);
+          break;
+        case '\b':
+          if (!text.endsWith('\n')) text = text.slice(0, -1);
+          break;
+        case '\x07':
+          break;
+      }
+    }
+    this.text = text.slice(-maxOutputLength);
+    this.outputTarget.textContent = this.text;
+    this.outputTarget.scrollTop = this.outputTarget.scrollHeight;
+  }
+
+  send(input) {
+    for (let i = 0; i < input.length; i += maxInputLength) {
+      this.subscription.perform('input', {
+        data: input.slice(i, i + maxInputLength),
+      });
+    }
+  }
+
+  type(event) {
+    let input = keySequences[event.key];
+    if (input === undefined && event.key.length === 1) {
+      input = event.key;
+      if (event.ctrlKey) {
+        const code = event.key.toUpperCase().charCodeAt(0);
+        if (code >= 64 && code < 96) {
+          input = String.fromCharCode(code - 64);
+        }
+      }
+    }
+    if (input === undefined || event.metaKey) {
+      return;
+    }
+    event.preventDefault();
+    this.send(input);
+  }
+
+  paste(event) {
+    event.preventDefault();
+    this.send(event.clipboardData.getData('text'));
+  }
+}